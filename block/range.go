@@ -9,8 +9,8 @@ import (
 )
 
 type Range struct {
-	StartBlock        uint64
-	ExclusiveEndBlock uint64
+	StartBlock        uint64 `json:"start_block"`
+	ExclusiveEndBlock uint64 `json:"exclusive_end_block"`
 }
 
 func NewRange(startBlock, exclusiveEndBlock uint64) *Range {
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"crypto/ed25519"
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -19,13 +21,15 @@ var packCmd = &cobra.Command{
 }
 
 func init() {
+	packCmd.Flags().String("sign-key", "", "Path to an ed25519 private key (PEM, see 'substreams tools keygen'), to write a detached signature of the package next to it, as '<package>.sig'")
+
 	rootCmd.AddCommand(packCmd)
 }
 
 func runPack(cmd *cobra.Command, args []string) error {
 	manifestPath := args[0]
 
-	manifestReader := manifest.NewReader(manifestPath)
+	manifestReader := manifest.NewReader(manifestPath, manifest.WithLockfileUpdate())
 	pkg, err := manifestReader.Read()
 	if err != nil {
 		return fmt.Errorf("reading manifest %q: %w", manifestPath, err)
@@ -47,12 +51,29 @@ func runPack(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("writing %q: %w", defaultFilename, err)
 	}
 
+	if signKeyPath := mustGetString(cmd, "sign-key"); signKeyPath != "" {
+		key, err := manifest.ReadPrivateKeyFile(signKeyPath)
+		if err != nil {
+			return err
+		}
+		sigCnt, err := manifest.EncodePackageSignature(manifest.SignPackage(cnt, key))
+		if err != nil {
+			return fmt.Errorf("encoding signature: %w", err)
+		}
+		sigFilename := defaultFilename + manifest.SignatureFileSuffix
+		if err := ioutil.WriteFile(sigFilename, sigCnt, 0644); err != nil {
+			return fmt.Errorf("writing %q: %w", sigFilename, err)
+		}
+		fmt.Printf("Signed with key %s, wrote %q.\n", manifest.KeyID(key.Public().(ed25519.PublicKey)), sigFilename)
+	}
+
 	fmt.Printf(`To generate bindings for your code:
 substream protogen %s
 
 `, defaultFilename)
 	fmt.Printf("----------------------------------------\n")
 	fmt.Printf("Successfully wrote %q.\n", defaultFilename)
+	fmt.Printf("sha256: %x (to pin it in the 'imports' of other manifests)\n", sha256.Sum256(cnt))
 
 	return nil
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/manifest"
+	"github.com/streamingfast/substreams/orchestrator"
+	"github.com/streamingfast/substreams/state"
+)
+
+func init() {
+	planCmd.Flags().Int64P("start-block", "s", -1, "Start block of the request to plan for. Defaults to -1, which means the initialBlock of the module")
+	planCmd.Flags().String("state-store-url", "./localdata", "URL of the base state store, as configured on the server")
+	planCmd.Flags().Uint64("stores-save-interval", 1000, "Stores save interval, as configured on the server")
+	planCmd.Flags().Uint64("subrequests-split-size", 10000, "Block range size of sub-requests, as configured on the server")
+	planCmd.Flags().StringP("output", "o", "table", "Output mode, one of: table, json")
+
+	rootCmd.AddCommand(planCmd)
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan <package> <module_name>",
+	Short: "Display the back-processing work a request would trigger, without executing anything",
+	Long: `Lists the existing store snapshots, the missing partial ranges, the
+number of jobs and the squash operations needed to bring every store
+the module depends on up to the start block.
+`,
+	RunE:         runPlan,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	outputMode := mustGetString(cmd, "output")
+	if outputMode != "table" && outputMode != "json" {
+		return fmt.Errorf("output mode %q invalid, choose from: table, json", outputMode)
+	}
+
+	manifestPath := args[0]
+	moduleName := args[1]
+	manifestReader := manifest.NewReader(manifestPath)
+	pkg, err := manifestReader.Read()
+	if err != nil {
+		return fmt.Errorf("read manifest %q: %w", manifestPath, err)
+	}
+
+	graph, err := manifest.NewModuleGraph(pkg.Modules.Modules)
+	if err != nil {
+		return fmt.Errorf("creating module graph: %w", err)
+	}
+
+	startBlock := mustGetInt64(cmd, "start-block")
+	if startBlock == -1 {
+		sb, err := graph.ModuleInitialBlock(moduleName)
+		if err != nil {
+			return fmt.Errorf("getting module start block: %w", err)
+		}
+		startBlock = int64(sb)
+	}
+
+	storesSaveInterval := mustGetUint64(cmd, "stores-save-interval")
+	if storesSaveInterval == 0 {
+		return fmt.Errorf("stores save interval cannot be 0")
+	}
+
+	baseStateStore, err := dstore.NewStore(mustGetString(cmd, "state-store-url"), "", "", false)
+	if err != nil {
+		return fmt.Errorf("creating state store: %w", err)
+	}
+
+	storeModules, err := graph.StoresDownTo([]string{moduleName})
+	if err != nil {
+		return fmt.Errorf("getting stores down to %q: %w", moduleName, err)
+	}
+
+	stores := map[string]*state.Store{}
+	for _, storeModule := range storeModules {
+		store, err := state.NewBuilder(
+			storeModule.Name,
+			storesSaveInterval,
+			storeModule.InitialBlock,
+			manifest.HashModuleAsString(pkg.Modules, graph, storeModule),
+			storeModule.GetKindStore().UpdatePolicy,
+			storeModule.GetKindStore().ValueType,
+			baseStateStore,
+		)
+		if err != nil {
+			return fmt.Errorf("creating store %q: %w", storeModule.Name, err)
+		}
+		stores[store.Name] = store
+	}
+
+	plan, err := orchestrator.DryRun(ctx, stores, mustGetUint64(cmd, "subrequests-split-size"), uint64(startBlock))
+	if err != nil {
+		return fmt.Errorf("planning work: %w", err)
+	}
+
+	if outputMode == "json" {
+		cnt, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshalling plan: %w", err)
+		}
+		fmt.Println(string(cnt))
+		return nil
+	}
+
+	printPlan(plan)
+	return nil
+}
+
+func printPlan(plan *orchestrator.Plan) {
+	fmt.Printf("Work plan up to block %d (sub-requests split size: %d)\n\n", plan.UpToBlock, plan.SubrequestSplitSize)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STORE\tINITIAL BLOCK\tCOMPLETE SNAPSHOTS\tPARTIAL SNAPSHOTS\tMISSING RANGES\tJOBS\tSQUASHES")
+	for _, store := range plan.Stores {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\t%d\n",
+			store.ModuleName,
+			store.ModuleInitialBlock,
+			rangesOrNone(store.CompleteSnapshots),
+			rangesOrNone(store.PartialSnapshots),
+			rangesOrNone(store.PartialsMissing.Merged()),
+			len(store.Jobs),
+			store.SquashOperations,
+		)
+	}
+	w.Flush()
+
+	fmt.Printf("\nTotal: %d jobs, %d squash operations\n", plan.JobCount(), plan.SquashOperations())
+}
+
+func rangesOrNone(ranges block.Ranges) string {
+	if len(ranges) == 0 {
+		return "-"
+	}
+	return ranges.String()
+}
@@ -25,6 +25,9 @@ func init() {
 
 	runCmd.Flags().StringP("output", "o", "", "Output mode. Defaults to 'ui' when in a TTY is present, and 'json' otherwise")
 	runCmd.Flags().BoolP("initial-snapshots", "i", false, "Fetch an initial snapshot at start block, before continuing processing.")
+	runCmd.Flags().StringArray("params", nil, "Set the value of the 'params' input of a module, as 'module_name=value', overriding the one of the manifest. Can be repeated")
+	runCmd.Flags().StringArray("trusted-keys", nil, "Path to a file of trusted ed25519 public keys (PEM). When set, refuse packages, including imported ones, not signed by one of them. Can be repeated")
+	runCmd.Flags().String("log-level", "", "Minimum level of the module logs to receive, one of 'debug', 'info', 'warn' or 'error'. Defaults to all of them")
 
 	rootCmd.AddCommand(runCmd)
 }
@@ -44,12 +47,29 @@ func runRun(cmd *cobra.Command, args []string) error {
 	outputMode := mustGetString(cmd, "output")
 
 	manifestPath := args[0]
-	manifestReader := manifest.NewReader(manifestPath)
+	var readerOptions []manifest.Options
+	if cmd.Flags().Changed("trusted-keys") {
+		trustedKeys, err := readTrustedKeysFlag(cmd, "trusted-keys")
+		if err != nil {
+			return err
+		}
+		readerOptions = append(readerOptions, manifest.WithTrustedKeys(trustedKeys))
+	}
+
+	manifestReader := manifest.NewReader(manifestPath, readerOptions...)
 	pkg, err := manifestReader.Read()
 	if err != nil {
 		return fmt.Errorf("read manifest %q: %w", manifestPath, err)
 	}
 
+	params, err := manifest.ParseParams(mustGetStringArray(cmd, "params"))
+	if err != nil {
+		return fmt.Errorf("params: %w", err)
+	}
+	if err := manifest.ApplyParams(params, pkg.Modules); err != nil {
+		return fmt.Errorf("params: %w", err)
+	}
+
 	outputStreamNames := strings.Split(args[1], ",")
 
 	graph, err := manifest.NewModuleGraph(pkg.Modules.Modules)
@@ -81,12 +101,18 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("stop block: %w", err)
 	}
 
+	minLogLevel, err := readLogLevelFlag(cmd, "log-level")
+	if err != nil {
+		return fmt.Errorf("log level: %w", err)
+	}
+
 	req := &pbsubstreams.Request{
 		StartBlockNum: startBlock,
 		StopBlockNum:  stopBlock,
 		ForkSteps:     []pbsubstreams.ForkStep{pbsubstreams.ForkStep_STEP_IRREVERSIBLE},
 		Modules:       pkg.Modules,
 		OutputModules: outputStreamNames,
+		MinLogLevel:   minLogLevel,
 	}
 	if mustGetBool(cmd, "initial-snapshots") {
 		req.InitialStoreSnapshotForModules = req.OutputModules
@@ -175,3 +201,16 @@ func readStopBlockFlag(cmd *cobra.Command, startBlock int64, flagName string) (u
 
 	return endBlock, nil
 }
+
+func readLogLevelFlag(cmd *cobra.Command, flagName string) (pbsubstreams.LogLevel, error) {
+	val := mustGetString(cmd, flagName)
+	if val == "" {
+		return pbsubstreams.LogLevel_LOG_LEVEL_UNSPECIFIED, nil
+	}
+
+	level, found := pbsubstreams.LogLevel_value["LOG_LEVEL_"+strings.ToUpper(val)]
+	if !found || level == int32(pbsubstreams.LogLevel_LOG_LEVEL_UNSPECIFIED) {
+		return 0, fmt.Errorf("invalid log level %q, must be one of 'debug', 'info', 'warn' or 'error'", val)
+	}
+	return pbsubstreams.LogLevel(level), nil
+}
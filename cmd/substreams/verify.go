@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/substreams/manifest"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:          "verify <package.spkg>",
+	Short:        "Verify the detached signature of an .spkg, '<package.spkg>.sig', against trusted public keys",
+	RunE:         runVerify,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+}
+
+func init() {
+	verifyCmd.Flags().StringArray("trusted-keys", nil, "Path to a file of trusted ed25519 public keys (PEM), can be repeated")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	pkgPath := args[0]
+
+	trustedKeys, err := readTrustedKeysFlag(cmd, "trusted-keys")
+	if err != nil {
+		return err
+	}
+	if len(trustedKeys) == 0 {
+		return fmt.Errorf("no trusted keys, specify --trusted-keys")
+	}
+
+	cnt, err := ioutil.ReadFile(pkgPath)
+	if err != nil {
+		return fmt.Errorf("reading %q: %w", pkgPath, err)
+	}
+	sigCnt, err := ioutil.ReadFile(pkgPath + manifest.SignatureFileSuffix)
+	if err != nil {
+		return fmt.Errorf("reading signature: %w", err)
+	}
+	sig, err := manifest.DecodePackageSignature(sigCnt)
+	if err != nil {
+		return err
+	}
+
+	if err := manifest.VerifyPackage(cnt, sig, trustedKeys); err != nil {
+		return fmt.Errorf("package %q: %w", pkgPath, err)
+	}
+
+	fmt.Printf("Package %q is signed by trusted key %s.\n", pkgPath, manifest.KeyID(sig.PublicKey))
+	return nil
+}
+
+func readTrustedKeysFlag(cmd *cobra.Command, flagName string) (keys []ed25519.PublicKey, err error) {
+	for _, filename := range mustGetStringArray(cmd, flagName) {
+		fileKeys, err := manifest.ReadPublicKeysFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	return keys, nil
+}
@@ -34,9 +34,11 @@ require (
 	github.com/iancoleman/strcase v0.2.0
 	github.com/jmoiron/sqlx v1.3.4
 	github.com/jszwec/csvutil v1.6.0
+	github.com/klauspost/compress v1.10.2
 	github.com/lib/pq v1.10.5
 	github.com/mattn/go-isatty v0.0.14
 	github.com/test-go/testify v1.1.4
+	github.com/tetratelabs/wazero v1.3.1
 	github.com/tidwall/pretty v1.2.0
 	github.com/wasmerio/wasmer-go v1.0.4
 	golang.org/x/mod v0.6.0-dev.0.20220106191415-9b9b3d81d5e3
@@ -75,7 +77,6 @@ require (
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/juju/ansiterm v0.0.0-20180109212912-720a0952cc2a // indirect
-	github.com/klauspost/compress v1.10.2 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/lithammer/dedent v1.1.0 // indirect
 	github.com/logrusorgru/aurora v2.0.3+incompatible // indirect
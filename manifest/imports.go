@@ -0,0 +1,337 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// Import is an entry of the `imports` section of a manifest, either a
+// path only:
+//
+//	imports:
+//	  eth: https://example.com/substreams-ethereum-v0.1.0.spkg
+//
+// or a path and the expected sha256 of the package:
+//
+//	imports:
+//	  eth:
+//	    path: https://example.com/substreams-ethereum-v0.1.0.spkg
+//	    sha256: 3b5d...
+type Import struct {
+	Name   string `yaml:"-"`
+	Path   string `yaml:"path"`
+	Sha256 string `yaml:"sha256"`
+}
+
+type Imports []*Import
+
+func (s *Imports) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("expected map")
+	}
+
+	if len(n.Content)%2 != 0 {
+		return fmt.Errorf("invalid map, unequal number of nodes below")
+	}
+
+	for i := 0; i < len(n.Content); i += 2 {
+		imp := &Import{Name: n.Content[i].Value}
+		switch value := n.Content[i+1]; value.Kind {
+		case yaml.ScalarNode:
+			imp.Path = value.Value
+		case yaml.MappingNode:
+			if err := value.Decode(imp); err != nil {
+				return fmt.Errorf("import %q: %w", imp.Name, err)
+			}
+		default:
+			return fmt.Errorf("import %q: expected a path, or a map with 'path' and 'sha256'", imp.Name)
+		}
+		*s = append(*s, imp)
+	}
+
+	return nil
+}
+
+func (i *Import) validate() error {
+	if i.Path == "" {
+		return fmt.Errorf("import %q: missing 'path'", i.Name)
+	}
+	if i.Sha256 == "" {
+		return nil
+	}
+	if !isPackageImport(i.Path) {
+		return fmt.Errorf("import %q: 'sha256' only supported for .spkg packages", i.Name)
+	}
+	if decoded, err := hex.DecodeString(i.Sha256); err != nil || len(decoded) != sha256.Size {
+		return fmt.Errorf("import %q: invalid 'sha256' %q, expected 64 hexadecimal characters", i.Name, i.Sha256)
+	}
+	return nil
+}
+
+// isPackageImport is true for imports of .spkg packages, as opposed to
+// manifests, which are built from their sources on each read.
+func isPackageImport(importPath string) bool {
+	return !strings.HasSuffix(importPath, ".yaml")
+}
+
+func isRemotePath(importPath string) bool {
+	u, err := url.Parse(importPath)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// Lockfile pins the content of the remote packages imported by a
+// manifest. It is written next to the manifest, ex: `substreams.lock` for
+// `substreams.yaml`, and is meant to be committed with it. Local packages
+// are not locked, they are usually rebuilt in place.
+type Lockfile struct {
+	Imports map[string]*LockedImport `yaml:"imports"`
+}
+
+type LockedImport struct {
+	Path   string `yaml:"path"`
+	Sha256 string `yaml:"sha256"`
+}
+
+const lockfileHeader = "# Generated by substreams, pins the packages imported by the manifest.\n"
+
+func lockfilePath(manifestPath string) string {
+	return strings.TrimSuffix(manifestPath, filepath.Ext(manifestPath)) + ".lock"
+}
+
+func readLockfile(filename string) (*Lockfile, error) {
+	lockfile := &Lockfile{}
+	cnt, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lockfile, nil
+		}
+		return nil, fmt.Errorf("reading lockfile %q: %w", filename, err)
+	}
+	if err := yaml.Unmarshal(cnt, lockfile); err != nil {
+		return nil, fmt.Errorf("decoding lockfile %q: %w", filename, err)
+	}
+	return lockfile, nil
+}
+
+func writeLockfile(filename string, lockfile *Lockfile) error {
+	cnt, err := yaml.Marshal(lockfile)
+	if err != nil {
+		return fmt.Errorf("encoding lockfile: %w", err)
+	}
+	if err := ioutil.WriteFile(filename, append([]byte(lockfileHeader), cnt...), 0644); err != nil {
+		return fmt.Errorf("writing lockfile %q: %w", filename, err)
+	}
+	return nil
+}
+
+// DefaultPackageCacheDir is where remote packages are cached, by
+// sha256, unless changed with `WithPackageCacheDir`.
+func DefaultPackageCacheDir() string {
+	if dir := os.Getenv("SUBSTREAMS_PACKAGE_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "substreams", "packages")
+}
+
+// loadImports reads the imported packages and merges them into `pkg`.
+// Packages are checked against the sha256 of the manifest, or of the
+// lockfile for the same remote path. With `WithLockfileUpdate`, the
+// lockfile is updated with the sha256 of all the remote packages.
+func (r *Reader) loadImports(pkg *pbsubstreams.Package, manif *Manifest) error {
+	lockfileName := lockfilePath(r.input)
+	lockfile, err := readLockfile(lockfileName)
+	if err != nil {
+		return err
+	}
+
+	newLockfile := &Lockfile{Imports: map[string]*LockedImport{}}
+	for _, imp := range manif.Imports {
+		var subpkg *pbsubstreams.Package
+		if isPackageImport(imp.Path) {
+			var locked *LockedImport
+			if isRemotePath(imp.Path) {
+				locked = lockfile.Imports[imp.Name]
+			}
+
+			cnt, sum, err := r.readPackageImport(imp, locked, lockfileName)
+			if err != nil {
+				return err
+			}
+			if isRemotePath(imp.Path) {
+				newLockfile.Imports[imp.Name] = &LockedImport{Path: imp.Path, Sha256: sum}
+			}
+
+			subpkg, err = NewReader(imp.Path, r.options...).fromContents(cnt)
+			if err != nil {
+				return fmt.Errorf("importing %q: %w", imp.Path, err)
+			}
+		} else {
+			subpkg, err = NewReader(imp.Path, r.options...).Read()
+			if err != nil {
+				return fmt.Errorf("importing %q: %w", imp.Path, err)
+			}
+		}
+
+		prefixModules(subpkg.Modules.Modules, imp.Name)
+		reindexAndMergePackage(subpkg, pkg)
+		if err := mergeProtoFiles(subpkg, pkg, imp.Name, manif.Protobuf.ConflictPolicy); err != nil {
+			return err
+		}
+	}
+
+	if len(newLockfile.Imports) == 0 && len(lockfile.Imports) == 0 {
+		return nil
+	}
+	if reflect.DeepEqual(lockfile.Imports, newLockfile.Imports) {
+		return nil
+	}
+	if !r.updateLockfile {
+		zlog.Warn("lockfile is not up to date with the imports, run 'substreams pack' to update it", zap.String("lockfile", lockfileName))
+		return nil
+	}
+	zlog.Info("updating lockfile", zap.String("lockfile", lockfileName))
+	return writeLockfile(lockfileName, newLockfile)
+}
+
+// readPackageImport returns the content of an imported package and its
+// sha256, from the package cache when it holds the expected sha256.
+func (r *Reader) readPackageImport(imp *Import, locked *LockedImport, lockfileName string) (cnt []byte, sum string, err error) {
+	expected, from := imp.Sha256, "manifest"
+	if expected == "" && locked != nil && locked.Path == imp.Path {
+		expected, from = locked.Sha256, "lockfile "+lockfileName
+	}
+
+	remote := isRemotePath(imp.Path)
+	if remote && expected != "" {
+		if cnt, found := r.cachedPackage(expected); found {
+			if err := r.verifySignature(imp.Path, cnt, expected); err != nil {
+				return nil, "", err
+			}
+			return cnt, expected, nil
+		}
+	}
+
+	if remote {
+		cnt, err = downloadPackage(imp.Path)
+	} else {
+		cnt, err = ioutil.ReadFile(imp.Path)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("importing %q: %w", imp.Path, err)
+	}
+
+	sum = sha256Hex(cnt)
+	if expected != "" && sum != expected {
+		return nil, "", fmt.Errorf("importing %q: sha256 mismatch, got %s but the %s expects %s", imp.Path, sum, from, expected)
+	}
+
+	if err := r.verifySignature(imp.Path, cnt, sum); err != nil {
+		return nil, "", err
+	}
+	if remote {
+		r.cacheFile(sum, ".spkg", cnt)
+	}
+	return cnt, sum, nil
+}
+
+func downloadPackage(fileURL string) ([]byte, error) {
+	resp, err := http.DefaultClient.Get(fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading: unexpected status %s", resp.Status)
+	}
+	cnt, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading: %w", err)
+	}
+	return cnt, nil
+}
+
+// cachedFilename is the path of a file of the package cache, named by
+// the sha256 of the package it belongs to.
+func (r *Reader) cachedFilename(sum, suffix string) string {
+	return filepath.Join(r.packageCacheDir, sum[:2], sum+suffix)
+}
+
+func (r *Reader) cachedFile(sum, suffix string) ([]byte, bool) {
+	if r.packageCacheDir == "" || len(sum) != 2*sha256.Size {
+		return nil, false
+	}
+
+	cnt, err := ioutil.ReadFile(r.cachedFilename(sum, suffix))
+	if err != nil {
+		return nil, false
+	}
+	return cnt, true
+}
+
+func (r *Reader) cachedPackage(sum string) ([]byte, bool) {
+	cnt, found := r.cachedFile(sum, ".spkg")
+	if !found {
+		return nil, false
+	}
+	if sha256Hex(cnt) != sum {
+		zlog.Warn("ignoring corrupted cached package", zap.String("sha256", sum))
+		return nil, false
+	}
+	return cnt, true
+}
+
+// cacheFile is best effort, files missing from the cache are downloaded
+// again.
+func (r *Reader) cacheFile(sum, suffix string, cnt []byte) {
+	if r.packageCacheDir == "" {
+		return
+	}
+
+	filename := r.cachedFilename(sum, suffix)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		zlog.Warn("creating package cache directory", zap.Error(err))
+		return
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), sum+".*.tmp")
+	if err != nil {
+		zlog.Warn("caching package file", zap.Error(err))
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(cnt); err != nil {
+		tmp.Close()
+		zlog.Warn("caching package file", zap.Error(err))
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		zlog.Warn("caching package file", zap.Error(err))
+		return
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		zlog.Warn("caching package file", zap.Error(err))
+	}
+}
+
+func sha256Hex(cnt []byte) string {
+	sum := sha256.Sum256(cnt)
+	return hex.EncodeToString(sum[:])
+}
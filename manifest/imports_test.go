@@ -0,0 +1,158 @@
+package manifest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+func TestImports_UnmarshalYAML(t *testing.T) {
+	var m *Manifest
+	err := yaml.Unmarshal([]byte(`
+imports:
+  eth: ./substreams-ethereum-v0.1.0.spkg
+  tokens:
+    path: https://example.com/tokens-v0.1.0.spkg
+    sha256: 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824
+`), &m)
+	require.NoError(t, err)
+
+	assert.Equal(t, Imports{
+		{Name: "eth", Path: "./substreams-ethereum-v0.1.0.spkg"},
+		{Name: "tokens", Path: "https://example.com/tokens-v0.1.0.spkg", Sha256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+	}, m.Imports)
+}
+
+func TestImport_Validate(t *testing.T) {
+	assert.NoError(t, (&Import{Name: "eth", Path: "eth.spkg"}).validate())
+	assert.EqualError(t, (&Import{Name: "eth"}).validate(), `import "eth": missing 'path'`)
+	assert.EqualError(t, (&Import{Name: "eth", Path: "eth/substreams.yaml", Sha256: sha256Hex(nil)}).validate(), `import "eth": 'sha256' only supported for .spkg packages`)
+	assert.EqualError(t, (&Import{Name: "eth", Path: "eth.spkg", Sha256: "abc"}).validate(), `import "eth": invalid 'sha256' "abc", expected 64 hexadecimal characters`)
+}
+
+// newImportTestServer serves the package of the test manifest, and
+// counts the downloads.
+func newImportTestServer(t *testing.T) (server *httptest.Server, cnt []byte, downloads *int) {
+	t.Helper()
+
+	pkg, err := NewReader("./test/test_manifest.yaml").Read()
+	require.NoError(t, err)
+	cnt, err = proto.Marshal(pkg)
+	require.NoError(t, err)
+
+	downloads = new(int)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*downloads++
+		w.Write(cnt)
+	}))
+	t.Cleanup(server.Close)
+	return server, cnt, downloads
+}
+
+func writeImportTestManifest(t *testing.T, dir string, imports string) string {
+	t.Helper()
+
+	manifestPath := filepath.Join(dir, "substreams.yaml")
+	err := ioutil.WriteFile(manifestPath, []byte(fmt.Sprintf(`
+specVersion: v0.1.0
+package:
+  name: importer
+  version: v0.1.0
+imports:
+%s
+`, imports)), 0644)
+	require.NoError(t, err)
+	return manifestPath
+}
+
+func TestReader_ImportLockfile(t *testing.T) {
+	server, cnt, downloads := newImportTestServer(t)
+	dir := t.TempDir()
+	cacheDir := t.TempDir()
+
+	manifestPath := writeImportTestManifest(t, dir, fmt.Sprintf("  pcs: %s/pcs-v0.1.0.spkg", server.URL))
+
+	pkg, err := NewReader(manifestPath, WithPackageCacheDir(cacheDir), WithLockfileUpdate()).Read()
+	require.NoError(t, err)
+	assert.Equal(t, "pcs:map_pairs", pkg.Modules.Modules[0].Name)
+	assert.Equal(t, 1, *downloads)
+
+	lockfile, err := readLockfile(filepath.Join(dir, "substreams.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*LockedImport{
+		"pcs": {Path: server.URL + "/pcs-v0.1.0.spkg", Sha256: sha256Hex(cnt)},
+	}, lockfile.Imports)
+
+	// Locked packages are read from the cache
+	_, err = NewReader(manifestPath, WithPackageCacheDir(cacheDir)).Read()
+	require.NoError(t, err)
+	assert.Equal(t, 1, *downloads)
+
+	// Downloaded again, and checked, without the cache
+	_, err = NewReader(manifestPath, WithPackageCacheDir("")).Read()
+	require.NoError(t, err)
+	assert.Equal(t, 2, *downloads)
+
+	err = writeLockfile(filepath.Join(dir, "substreams.lock"), &Lockfile{Imports: map[string]*LockedImport{
+		"pcs": {Path: server.URL + "/pcs-v0.1.0.spkg", Sha256: sha256Hex(nil)},
+	}})
+	require.NoError(t, err)
+	_, err = NewReader(manifestPath, WithPackageCacheDir("")).Read()
+	assert.EqualError(t, err, fmt.Sprintf("error loading imports: importing %q: sha256 mismatch, got %s but the lockfile %s expects %s", server.URL+"/pcs-v0.1.0.spkg", sha256Hex(cnt), filepath.Join(dir, "substreams.lock"), sha256Hex(nil)))
+}
+
+func TestReader_ImportLocalPackageNotLocked(t *testing.T) {
+	_, cnt, _ := newImportTestServer(t)
+	dir := t.TempDir()
+
+	packagePath := filepath.Join(dir, "pcs-v0.1.0.spkg")
+	require.NoError(t, ioutil.WriteFile(packagePath, cnt, 0644))
+	manifestPath := writeImportTestManifest(t, dir, fmt.Sprintf("  pcs: %s", packagePath))
+
+	// A stale entry of a previous version is ignored, and removed
+	err := writeLockfile(filepath.Join(dir, "substreams.lock"), &Lockfile{Imports: map[string]*LockedImport{
+		"pcs": {Path: packagePath, Sha256: sha256Hex(nil)},
+	}})
+	require.NoError(t, err)
+
+	_, err = NewReader(manifestPath, WithPackageCacheDir(""), WithLockfileUpdate()).Read()
+	require.NoError(t, err)
+
+	lockfile, err := readLockfile(filepath.Join(dir, "substreams.lock"))
+	require.NoError(t, err)
+	assert.Empty(t, lockfile.Imports)
+}
+
+func TestReader_ImportLockfileNotWrittenOnRead(t *testing.T) {
+	server, _, _ := newImportTestServer(t)
+	dir := t.TempDir()
+
+	manifestPath := writeImportTestManifest(t, dir, fmt.Sprintf("  pcs: %s/pcs-v0.1.0.spkg", server.URL))
+	_, err := NewReader(manifestPath, WithPackageCacheDir("")).Read()
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "substreams.lock"))
+	assert.True(t, os.IsNotExist(err), "only WithLockfileUpdate writes the lockfile")
+}
+
+func TestReader_ImportSha256(t *testing.T) {
+	server, cnt, _ := newImportTestServer(t)
+	dir := t.TempDir()
+
+	manifestPath := writeImportTestManifest(t, dir, fmt.Sprintf("  pcs:\n    path: %s/pcs.spkg\n    sha256: %s", server.URL, sha256Hex(cnt)))
+	_, err := NewReader(manifestPath, WithPackageCacheDir("")).Read()
+	require.NoError(t, err)
+
+	manifestPath = writeImportTestManifest(t, dir, fmt.Sprintf("  pcs:\n    path: %s/pcs.spkg\n    sha256: %s", server.URL, sha256Hex(nil)))
+	_, err = NewReader(manifestPath, WithPackageCacheDir("")).Read()
+	assert.EqualError(t, err, fmt.Sprintf("error loading imports: importing %q: sha256 mismatch, got %s but the manifest expects %s", server.URL+"/pcs.spkg", sha256Hex(cnt), sha256Hex(nil)))
+}
@@ -29,12 +29,14 @@ const (
 // Manifest is a YAML structure used to create a Package and its list
 // of Modules. The notion of a manifest does not live in protobuf definitions.
 type Manifest struct {
-	SpecVersion string            `yaml:"specVersion"` // check that it equals v0.1.0
-	Package     PackageMeta       `yaml:"package"`
-	Protobuf    Protobuf          `yaml:"protobuf"`
-	Imports     mapSlice          `yaml:"imports"`
-	Binaries    map[string]Binary `yaml:"binaries"`
-	Modules     []*Module         `yaml:"modules"`
+	SpecVersion string               `yaml:"specVersion"` // check that it equals v0.1.0
+	Package     PackageMeta          `yaml:"package"`
+	Protobuf    Protobuf             `yaml:"protobuf"`
+	Imports     Imports              `yaml:"imports"`
+	Binaries    map[string]Binary    `yaml:"binaries"`
+	Modules     []*Module            `yaml:"modules"`
+	Params      map[string]string    `yaml:"params"`    // module name to the value of its `params` input
+	Overrides   map[string]*Override `yaml:"overrides"` // prefixed name of imported modules to their changes
 
 	Graph   *ModuleGraph `yaml:"-"`
 	Workdir string       `yaml:"-"`
@@ -48,8 +50,9 @@ type PackageMeta struct {
 }
 
 type Protobuf struct {
-	Files       []string `yaml:"files"`
-	ImportPaths []string `yaml:"importPaths"`
+	Files          []string            `yaml:"files"`
+	ImportPaths    []string            `yaml:"importPaths"`
+	ConflictPolicy ProtoConflictPolicy `yaml:"conflictPolicy"`
 }
 
 type Module struct {
@@ -58,6 +61,8 @@ type Module struct {
 	Kind         string  `yaml:"kind"`
 	InitialBlock *uint64 `yaml:"initialBlock"`
 
+	ReuseInstance bool `yaml:"reuseInstance"`
+
 	UpdatePolicy string `yaml:"updatePolicy"`
 	ValueType    string `yaml:"valueType"`
 	Binary       string `yaml:"binary"`
@@ -70,6 +75,7 @@ type Input struct {
 	Source string `yaml:"source"`
 	Store  string `yaml:"store"`
 	Map    string `yaml:"map"`
+	Params string `yaml:"params"` // type of the value, only "string" for now
 	Mode   string `yaml:"mode"`
 
 	Name string `yaml:"-"`
@@ -117,11 +123,11 @@ func decodeYamlManifestFromFile(yamlFilePath string) (out *Manifest, err error)
 //}
 
 func (i *Input) parse() error {
-	if i.Map != "" && i.Store == "" && i.Source == "" {
+	if i.Map != "" && i.Store == "" && i.Source == "" && i.Params == "" {
 		i.Name = fmt.Sprintf("map:%s", i.Map)
 		return nil
 	}
-	if i.Store != "" && i.Map == "" && i.Source == "" {
+	if i.Store != "" && i.Map == "" && i.Source == "" && i.Params == "" {
 		i.Name = fmt.Sprintf("store:%s", i.Store)
 		if i.Mode == "" {
 			i.Mode = "get"
@@ -131,11 +137,18 @@ func (i *Input) parse() error {
 		}
 		return nil
 	}
-	if i.Source != "" && i.Map == "" && i.Store == "" {
+	if i.Source != "" && i.Map == "" && i.Store == "" && i.Params == "" {
 		i.Name = fmt.Sprintf("source:%s", i.Source)
 		return nil
 	}
-	return fmt.Errorf("one, and only one of 'map', 'store' or 'source' must be specified")
+	if i.Params != "" && i.Map == "" && i.Store == "" && i.Source == "" {
+		i.Name = "params"
+		if i.Params != "string" {
+			return fmt.Errorf("input %q: 'params' must be of type 'string'", i.Name)
+		}
+		return nil
+	}
+	return fmt.Errorf("one, and only one of 'map', 'store', 'source' or 'params' must be specified")
 }
 
 func validateStoreBuilder(module *Module) error {
@@ -195,6 +208,7 @@ func (m *Module) ToProtoWASM(codeIndex uint32) (*pbsubstreams.Module, error) {
 		Name:             m.Name,
 		BinaryIndex:      codeIndex,
 		BinaryEntrypoint: m.Name,
+		ReuseInstance:    m.ReuseInstance,
 	}
 
 	out.InitialBlock = UNSET
@@ -262,6 +276,15 @@ func (m *Module) setInputsToProto(pbModule *pbsubstreams.Module) error {
 			pbModule.Inputs = append(pbModule.Inputs, pbInput)
 			continue
 		}
+		if input.Params != "" {
+			pbInput := &pbsubstreams.Module_Input{
+				Input: &pbsubstreams.Module_Input_Params_{
+					Params: &pbsubstreams.Module_Input_Params{},
+				},
+			}
+			pbModule.Inputs = append(pbModule.Inputs, pbInput)
+			continue
+		}
 
 		return fmt.Errorf("invalid input")
 	}
@@ -129,3 +129,126 @@ func TestManifest_ToProto(t *testing.T) {
 	require.Equal(t, uint32(0), module.BinaryIndex)
 	require.Equal(t, "proto:sf.substreams.tokens.v1.Tokens", module.Output.Type)
 }
+
+func TestValidateModules_Entrypoints(t *testing.T) {
+	pkg, err := NewReader("./test/test_manifest.yaml").Read()
+	require.NoError(t, err)
+
+	// Stores in get mode are passed as a single store index
+	mod := pkg.Modules.Modules[2]
+	require.Equal(t, "map_reserves", mod.Name)
+	mod.Inputs[1].GetStore().Mode = pbsubstreams.Module_Input_Store_DELTAS
+
+	err = ValidateModules(pkg.Modules)
+	assert.EqualError(t, err, `binary 0: entrypoint "map_reserves" takes 3 parameters, expected 4 for the module inputs`)
+}
+
+func TestInput_ParseParams(t *testing.T) {
+	in := &Input{Params: "string"}
+	require.NoError(t, in.parse())
+	assert.Equal(t, "params", in.Name)
+
+	assert.EqualError(t, (&Input{Params: "bytes"}).parse(), `input "params": 'params' must be of type 'string'`)
+	assert.EqualError(t, (&Input{Params: "string", Map: "map_pairs"}).parse(), `one, and only one of 'map', 'store', 'source' or 'params' must be specified`)
+}
+
+func newParamsTestModules(value string) *pbsubstreams.Modules {
+	return &pbsubstreams.Modules{
+		Binaries: []*pbsubstreams.Binary{{Type: "wasm/rust-v1", Content: []byte("code")}},
+		Modules: []*pbsubstreams.Module{
+			{
+				Name: "map_pools",
+				Kind: &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{OutputType: "proto:pools"}},
+				Inputs: []*pbsubstreams.Module_Input{
+					{Input: &pbsubstreams.Module_Input_Params_{Params: &pbsubstreams.Module_Input_Params{Value: value}}},
+					{Input: &pbsubstreams.Module_Input_Source_{Source: &pbsubstreams.Module_Input_Source{Type: "sf.ethereum.type.v1.Block"}}},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyParams(t *testing.T) {
+	mods := newParamsTestModules("0xaa")
+
+	require.NoError(t, ApplyParams(map[string]string{"map_pools": "0xbb"}, mods))
+	assert.Equal(t, "0xbb", mods.Modules[0].Inputs[0].GetParams().Value)
+
+	assert.EqualError(t, ApplyParams(map[string]string{"unknown": "0xbb"}, mods), `params for module "unknown": module not found`)
+
+	mods.Modules[0].Inputs = mods.Modules[0].Inputs[1:]
+	assert.EqualError(t, ApplyParams(map[string]string{"map_pools": "0xbb"}, mods), `params for module "map_pools": module has no 'params' input`)
+}
+
+func TestParseParams(t *testing.T) {
+	params, err := ParseParams([]string{"map_pools=0xaa=1", "eth:map_pairs="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"map_pools": "0xaa=1", "eth:map_pairs": ""}, params)
+
+	_, err = ParseParams([]string{"map_pools"})
+	assert.EqualError(t, err, `invalid params "map_pools", expected 'module=value'`)
+
+	_, err = ParseParams([]string{"map_pools=1", "map_pools=2"})
+	assert.EqualError(t, err, `params for module "map_pools" specified more than once`)
+}
+
+func TestHashModule_Params(t *testing.T) {
+	hash := func(value string) string {
+		mods := newParamsTestModules(value)
+		graph, err := NewModuleGraph(mods.Modules)
+		require.NoError(t, err)
+		return HashModuleAsString(mods, graph, mods.Modules[0])
+	}
+
+	assert.Equal(t, hash("0xaa"), hash("0xaa"))
+	assert.NotEqual(t, hash("0xaa"), hash("0xbb"))
+}
+
+func TestValidateModules_Params(t *testing.T) {
+	mods := newParamsTestModules("0xaa")
+	mods.Modules[0].Inputs = append(mods.Modules[0].Inputs, mods.Modules[0].Inputs[0])
+
+	err := ValidateModules(mods)
+	assert.EqualError(t, err, `module "map_pools": input index 2: only one 'params' input allowed`)
+}
+
+func TestHashModule_CoversInputs(t *testing.T) {
+	pkg, err := NewReader("./test/test_manifest.yaml").Read()
+	require.NoError(t, err)
+
+	hash := func() map[string]string {
+		graph, err := NewModuleGraph(pkg.Modules.Modules)
+		require.NoError(t, err)
+
+		hashes := map[string]string{}
+		for _, module := range pkg.Modules.Modules {
+			hashes[module.Name] = HashModuleAsString(pkg.Modules, graph, module)
+		}
+		return hashes
+	}
+
+	original := hash()
+	assert.NotEqual(t, original["map_pairs"], original["map_block_to_tokens"], "same inputs, different entrypoints and output types")
+
+	mod := pkg.Modules.Modules[2]
+	require.Equal(t, "map_reserves", mod.Name)
+	mod.Inputs[1].GetStore().Mode = pbsubstreams.Module_Input_Store_DELTAS
+	deltas := hash()
+	assert.NotEqual(t, original["map_reserves"], deltas["map_reserves"])
+	assert.Equal(t, original["build_pairs_state"], deltas["build_pairs_state"])
+
+	mod.Output.Type = "proto:pcs.types.v2.Reserves"
+	assert.NotEqual(t, deltas["map_reserves"], hash()["map_reserves"])
+}
+
+func TestHashModuleV1(t *testing.T) {
+	pkg, err := NewReader("./test/test_manifest.yaml").Read()
+	require.NoError(t, err)
+	graph, err := NewModuleGraph(pkg.Modules.Modules)
+	require.NoError(t, err)
+
+	// Hashes of the caches written before ModuleHashVersion 2, must never change
+	assert.Equal(t, "f8961332850590ebb85784cfdaaa3ff89686f335", HashModuleV1AsString(pkg.Modules, graph, pkg.Modules.Modules[0]))
+	assert.Equal(t, "c1620fa8a6e1b4e23e41aaa37199e08d7595d2d5", HashModuleV1AsString(pkg.Modules, graph, pkg.Modules.Modules[1]))
+	assert.Equal(t, "f8961332850590ebb85784cfdaaa3ff89686f335", HashModuleV1AsString(pkg.Modules, graph, pkg.Modules.Modules[3]))
+}
@@ -35,6 +35,9 @@ func PrintMermaid(mods *pbsubstreams.Modules) {
 				} else {
 					fmt.Printf("  %s --> %s\n", name, s.Name)
 				}
+			case *pbsubstreams.Module_Input_Params_:
+				name := fmt.Sprintf("%s:params", s.Name)
+				fmt.Printf("  %s[params] --> %s\n", name, s.Name)
 			}
 		}
 	}
@@ -0,0 +1,189 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+// Override changes an imported module. Overrides are keyed by the
+// prefixed name of the module in the `overrides` section of the
+// manifest:
+//
+//	overrides:
+//	  eth:map_pools:
+//	    initialBlock: 12369621
+//	    binary: patched
+//	    params: "0xabcd"
+type Override struct {
+	InitialBlock *uint64 `yaml:"initialBlock"`
+	Binary       string  `yaml:"binary"` // name in the `binaries` section of the manifest
+	Params       *string `yaml:"params"`
+}
+
+func (o *Override) validate(target string, m *Manifest) error {
+	importName, _, found := strings.Cut(target, PrefixSeparator)
+	if !found {
+		return fmt.Errorf("override %q: must target an imported module, as 'import_name%smodule'", target, PrefixSeparator)
+	}
+
+	imported := false
+	for _, imp := range m.Imports {
+		if imp.Name == importName {
+			imported = true
+			break
+		}
+	}
+	if !imported {
+		return fmt.Errorf("override %q: unknown import %q", target, importName)
+	}
+
+	if o == nil || (o.InitialBlock == nil && o.Binary == "" && o.Params == nil) {
+		return fmt.Errorf("override %q: nothing to override, expected 'initialBlock', 'binary' or 'params'", target)
+	}
+	if o.Binary != "" {
+		if _, found := m.Binaries[o.Binary]; !found {
+			return fmt.Errorf("override %q: binary %q is not defined in the 'binaries' section of the manifest", target, o.Binary)
+		}
+	}
+	if o.Params != nil {
+		if _, found := m.Params[target]; found {
+			return fmt.Errorf("override %q: params also set in the 'params' section of the manifest", target)
+		}
+	}
+	return nil
+}
+
+func sortedOverrideTargets(overrides map[string]*Override) []string {
+	targets := make([]string, 0, len(overrides))
+	for target := range overrides {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// applyOverrides changes the imported modules of `pkg` according to the
+// `overrides` of the manifest, once the imports are merged. Module hashes
+// cover the initial block, binary and params, so overridden modules, and
+// the ones reading from them, do not reuse the caches of the originals.
+//
+// Modules reading from an overridden one without an initial block of
+// their own inherit the new one. Those of an imported `.spkg` had theirs
+// resolved when packed: an override starting a module after one of them
+// is rejected, they must be overridden too.
+func (r *Reader) applyOverrides(pkg *pbsubstreams.Package, m *Manifest) error {
+	for _, target := range sortedOverrideTargets(m.Overrides) {
+		override := m.Overrides[target]
+
+		var mod *pbsubstreams.Module
+		for _, candidate := range pkg.Modules.Modules {
+			if candidate.Name == target {
+				mod = candidate
+				break
+			}
+		}
+		if mod == nil {
+			return fmt.Errorf("override %q: module not found", target)
+		}
+
+		if override.InitialBlock != nil {
+			mod.InitialBlock = *override.InitialBlock
+		}
+
+		if override.Binary != "" {
+			code, err := r.readBinary(m.Binaries[override.Binary])
+			if err != nil {
+				return fmt.Errorf("override %q: %w", target, err)
+			}
+			mod.BinaryIndex = addBinary(pkg.Modules, code)
+		}
+
+		if override.Params != nil {
+			idx := paramsInputIndex(mod)
+			if idx == -1 {
+				return fmt.Errorf("override %q: module has no 'params' input", target)
+			}
+			mod.Inputs[idx].GetParams().Value = *override.Params
+		}
+	}
+
+	removeUnusedBinaries(pkg.Modules)
+	return checkOverriddenInitialBlocks(pkg.Modules.Modules, m.Overrides)
+}
+
+// checkOverriddenInitialBlocks returns an error when a module reads from
+// a module whose initial block was overridden past its own.
+func checkOverriddenInitialBlocks(modules []*pbsubstreams.Module, overrides map[string]*Override) error {
+	initialBlocks := map[string]uint64{}
+	for _, mod := range modules {
+		initialBlocks[mod.Name] = mod.InitialBlock
+	}
+
+	for _, mod := range modules {
+		for _, input := range mod.Inputs {
+			var moduleName string
+			if v := input.GetMap(); v != nil {
+				moduleName = v.ModuleName
+			} else if v := input.GetStore(); v != nil {
+				moduleName = v.ModuleName
+			}
+
+			override, found := overrides[moduleName]
+			if !found || override.InitialBlock == nil {
+				continue
+			}
+			if initialBlocks[moduleName] > mod.InitialBlock {
+				return fmt.Errorf("override %q: initial block %d is after the initial block %d of module %q reading from it, override it too", moduleName, initialBlocks[moduleName], mod.InitialBlock, mod.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// removeUnusedBinaries drops the binaries no module uses anymore, like
+// the ones replaced by `binary` overrides, and reindexes the modules.
+func removeUnusedBinaries(mods *pbsubstreams.Modules) {
+	used := make([]bool, len(mods.Binaries))
+	for _, mod := range mods.Modules {
+		if int(mod.BinaryIndex) < len(used) {
+			used[mod.BinaryIndex] = true
+		}
+	}
+
+	var binaries []*pbsubstreams.Binary
+	indexes := make([]uint32, len(mods.Binaries))
+	for idx, binary := range mods.Binaries {
+		if used[idx] {
+			indexes[idx] = uint32(len(binaries))
+			binaries = append(binaries, binary)
+		}
+	}
+	if len(binaries) == len(mods.Binaries) {
+		return
+	}
+
+	for _, mod := range mods.Modules {
+		if int(mod.BinaryIndex) < len(indexes) {
+			mod.BinaryIndex = indexes[mod.BinaryIndex]
+		}
+	}
+	mods.Binaries = binaries
+}
+
+// addBinary returns the index of `code` in the binaries of `mods`,
+// appending it unless already there.
+func addBinary(mods *pbsubstreams.Modules, code *pbsubstreams.Binary) uint32 {
+	if len(code.Content) != 0 {
+		for idx, binary := range mods.Binaries {
+			if binary.Type == code.Type && bytes.Equal(binary.Content, code.Content) {
+				return uint32(idx)
+			}
+		}
+	}
+	mods.Binaries = append(mods.Binaries, code)
+	return uint32(len(mods.Binaries) - 1)
+}
@@ -0,0 +1,162 @@
+package manifest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func writeOverridesTestManifest(t *testing.T, dir string, overrides string) string {
+	t.Helper()
+
+	importPath, err := filepath.Abs("./test/test_manifest.yaml")
+	require.NoError(t, err)
+
+	// The same code, with a custom section appended
+	code, err := ioutil.ReadFile("./test/code/pcs_substreams_bg.wasm")
+	require.NoError(t, err)
+	patchedPath := filepath.Join(dir, "patched.wasm")
+	require.NoError(t, ioutil.WriteFile(patchedPath, append(code, 0x00, 0x05, 0x04, 't', 'e', 's', 't'), 0644))
+
+	manifestPath := filepath.Join(dir, "substreams.yaml")
+	err = ioutil.WriteFile(manifestPath, []byte(fmt.Sprintf(`
+specVersion: v0.1.0
+package:
+  name: overrider
+  version: v0.1.0
+imports:
+  pcs: %s
+binaries:
+  patched:
+    type: wasm/rust-v1
+    file: %s
+overrides:
+%s
+`, importPath, patchedPath, overrides)), 0644)
+	require.NoError(t, err)
+	return manifestPath
+}
+
+func moduleHashes(t *testing.T, pkg *pbsubstreams.Package) map[string]string {
+	t.Helper()
+
+	graph, err := NewModuleGraph(pkg.Modules.Modules)
+	require.NoError(t, err)
+
+	hashes := map[string]string{}
+	for _, mod := range pkg.Modules.Modules {
+		hashes[mod.Name] = HashModuleAsString(pkg.Modules, graph, mod)
+	}
+	return hashes
+}
+
+func TestReader_Overrides(t *testing.T) {
+	dir := t.TempDir()
+
+	original, err := NewReader(writeOverridesTestManifest(t, dir, "")).Read()
+	require.NoError(t, err)
+
+	pkg, err := NewReader(writeOverridesTestManifest(t, dir, "  pcs:map_pairs:\n    initialBlock: 12369621\n    binary: patched")).Read()
+	require.NoError(t, err)
+
+	mapPairs := pkg.Modules.Modules[0]
+	assert.Equal(t, "pcs:map_pairs", mapPairs.Name)
+	assert.Equal(t, uint64(12369621), mapPairs.InitialBlock)
+	require.Len(t, pkg.Modules.Binaries, 2)
+	assert.Equal(t, uint32(1), mapPairs.BinaryIndex)
+	assert.Equal(t, uint32(0), pkg.Modules.Modules[3].BinaryIndex)
+
+	originalHashes, hashes := moduleHashes(t, original), moduleHashes(t, pkg)
+	assert.NotEqual(t, originalHashes["pcs:map_pairs"], hashes["pcs:map_pairs"])
+	assert.NotEqual(t, originalHashes["pcs:build_pairs_state"], hashes["pcs:build_pairs_state"])
+	assert.Equal(t, originalHashes["pcs:map_block_to_tokens"], hashes["pcs:map_block_to_tokens"])
+	assert.Equal(t, uint64(12369621), pkg.Modules.Modules[1].InitialBlock, "build_pairs_state inherits the overridden initial block")
+
+	// The replaced binary is dropped once no module uses it
+	var overrides string
+	for _, mod := range original.Modules.Modules {
+		overrides += fmt.Sprintf("  %s:\n    binary: patched\n", mod.Name)
+	}
+	pkg, err = NewReader(writeOverridesTestManifest(t, dir, overrides)).Read()
+	require.NoError(t, err)
+	require.Len(t, pkg.Modules.Binaries, 1)
+	assert.NotEqual(t, original.Modules.Binaries[0].Content, pkg.Modules.Binaries[0].Content)
+	for _, mod := range pkg.Modules.Modules {
+		assert.Equal(t, uint32(0), mod.BinaryIndex, mod.Name)
+	}
+
+	_, err = NewReader(writeOverridesTestManifest(t, dir, "  pcs:unknown:\n    initialBlock: 10")).Read()
+	assert.EqualError(t, err, `error applying overrides: override "pcs:unknown": module not found`)
+}
+
+func TestOverride_Validate(t *testing.T) {
+	m := &Manifest{
+		Imports:  Imports{{Name: "pcs", Path: "pcs.spkg"}},
+		Binaries: map[string]Binary{"patched": {Type: "wasm/rust-v1", File: "patched.wasm"}},
+		Params:   map[string]string{"pcs:map_pools": "0xaa"},
+	}
+
+	assert.NoError(t, (&Override{Binary: "patched"}).validate("pcs:map_pairs", m))
+	assert.EqualError(t, (&Override{Binary: "patched"}).validate("map_pairs", m), `override "map_pairs": must target an imported module, as 'import_name:module'`)
+	assert.EqualError(t, (&Override{Binary: "patched"}).validate("eth:map_pairs", m), `override "eth:map_pairs": unknown import "eth"`)
+	assert.EqualError(t, (&Override{}).validate("pcs:map_pairs", m), `override "pcs:map_pairs": nothing to override, expected 'initialBlock', 'binary' or 'params'`)
+	assert.EqualError(t, (&Override{Binary: "unknown"}).validate("pcs:map_pairs", m), `override "pcs:map_pairs": binary "unknown" is not defined in the 'binaries' section of the manifest`)
+	assert.EqualError(t, (&Override{Params: proto.String("0xbb")}).validate("pcs:map_pools", m), `override "pcs:map_pools": params also set in the 'params' section of the manifest`)
+}
+
+func TestReader_ApplyOverridesParams(t *testing.T) {
+	pkg := &pbsubstreams.Package{Modules: newParamsTestModules("0xaa")}
+
+	m := &Manifest{Overrides: map[string]*Override{"map_pools": {Params: proto.String("0xbb")}}}
+	require.NoError(t, NewReader("").applyOverrides(pkg, m))
+	assert.Equal(t, "0xbb", pkg.Modules.Modules[0].Inputs[0].GetParams().Value)
+
+	pkg.Modules.Modules[0].Inputs = nil
+	err := NewReader("").applyOverrides(pkg, m)
+	assert.EqualError(t, err, `override "map_pools": module has no 'params' input`)
+}
+
+func TestReader_ApplyOverridesInitialBlockDependents(t *testing.T) {
+	newPkg := func() *pbsubstreams.Package {
+		return &pbsubstreams.Package{Modules: &pbsubstreams.Modules{
+			Modules: []*pbsubstreams.Module{
+				{
+					Name:         "pcs:map_pools",
+					InitialBlock: 10,
+					Kind:         &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{OutputType: "proto:pools"}},
+					Inputs: []*pbsubstreams.Module_Input{
+						{Input: &pbsubstreams.Module_Input_Source_{Source: &pbsubstreams.Module_Input_Source{Type: "sf.ethereum.type.v1.Block"}}},
+					},
+				},
+				{
+					Name:         "pcs:store_pools",
+					InitialBlock: 10,
+					Kind:         &pbsubstreams.Module_KindStore_{KindStore: &pbsubstreams.Module_KindStore{UpdatePolicy: pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, ValueType: "bytes"}},
+					Inputs: []*pbsubstreams.Module_Input{
+						{Input: &pbsubstreams.Module_Input_Map_{Map: &pbsubstreams.Module_Input_Map{ModuleName: "pcs:map_pools"}}},
+					},
+				},
+			},
+		}}
+	}
+
+	m := &Manifest{Overrides: map[string]*Override{"pcs:map_pools": {InitialBlock: proto.Uint64(20)}}}
+	err := NewReader("").applyOverrides(newPkg(), m)
+	assert.EqualError(t, err, `override "pcs:map_pools": initial block 20 is after the initial block 10 of module "pcs:store_pools" reading from it, override it too`)
+
+	m.Overrides["pcs:map_pools"].InitialBlock = proto.Uint64(5)
+	require.NoError(t, NewReader("").applyOverrides(newPkg(), m))
+
+	m.Overrides["pcs:map_pools"].InitialBlock = proto.Uint64(20)
+	m.Overrides["pcs:store_pools"] = &Override{InitialBlock: proto.Uint64(20)}
+	pkg := newPkg()
+	require.NoError(t, NewReader("").applyOverrides(pkg, m))
+	assert.Equal(t, uint64(20), pkg.Modules.Modules[0].InitialBlock)
+	assert.Equal(t, uint64(20), pkg.Modules.Modules[1].InitialBlock)
+}
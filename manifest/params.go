@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+// ApplyParams sets the value of the `params` input of the modules named
+// in `params`, overriding the value they had. Modules of imported
+// packages are referred to by their prefixed name, ex: `eth:map_pools`.
+func ApplyParams(params map[string]string, mods *pbsubstreams.Modules) error {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var mod *pbsubstreams.Module
+		for _, m := range mods.Modules {
+			if m.Name == name {
+				mod = m
+				break
+			}
+		}
+		if mod == nil {
+			return fmt.Errorf("params for module %q: module not found", name)
+		}
+
+		idx := paramsInputIndex(mod)
+		if idx == -1 {
+			return fmt.Errorf("params for module %q: module has no 'params' input", name)
+		}
+		mod.Inputs[idx].GetParams().Value = params[name]
+	}
+	return nil
+}
+
+// ParseParams parses `module=value` pairs, as given on the command line.
+// The value is everything after the first `=`, and can be empty.
+func ParseParams(pairs []string) (map[string]string, error) {
+	params := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, found := strings.Cut(pair, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid params %q, expected 'module=value'", pair)
+		}
+		if _, found := params[name]; found {
+			return nil, fmt.Errorf("params for module %q specified more than once", name)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// paramsInputIndex returns the index of the first `params` input of
+// `mod`, or -1 when it has none.
+func paramsInputIndex(mod *pbsubstreams.Module) int {
+	for idx, in := range mod.Inputs {
+		if in.GetParams() != nil {
+			return idx
+		}
+	}
+	return -1
+}
@@ -3,10 +3,12 @@ package manifest
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/jhump/protoreflect/desc/protoparse"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 	"github.com/streamingfast/substreams/pb/system"
+	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
@@ -58,3 +60,319 @@ func readSystemProtobufs() (*descriptorpb.FileDescriptorSet, error) {
 
 	return fds, nil
 }
+
+// protoMessageNames returns the fully-qualified names of all the
+// messages defined in `files`, nested ones included.
+func protoMessageNames(files []*descriptorpb.FileDescriptorProto) map[string]bool {
+	names := map[string]bool{}
+
+	var addMessages func(prefix string, messages []*descriptorpb.DescriptorProto)
+	addMessages = func(prefix string, messages []*descriptorpb.DescriptorProto) {
+		for _, msg := range messages {
+			name := prefix + msg.GetName()
+			names[name] = true
+			addMessages(name+".", msg.NestedType)
+		}
+	}
+
+	for _, file := range files {
+		prefix := ""
+		if file.GetPackage() != "" {
+			prefix = file.GetPackage() + "."
+		}
+		addMessages(prefix, file.MessageType)
+	}
+	return names
+}
+
+// validateProtoReferences resolves the message types referenced by the
+// modules against the protobuf definitions of the package. Sources, store
+// value types and outputs must be found, as they are needed to decode
+// the data. Map output types only get a warning: maps may output types
+// defined elsewhere.
+func validateProtoReferences(pkg *pbsubstreams.Package) error {
+	if errs := unresolvedProtoReferences(pkg); len(errs) != 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// warnProtoReferences logs the message types of a package read from an
+// `.spkg` that validateProtoReferences would refuse, as packages built
+// before the check do not always embed their chain's block type.
+func warnProtoReferences(pkg *pbsubstreams.Package) {
+	for _, err := range unresolvedProtoReferences(pkg) {
+		zlog.Warn("unresolved protobuf type", zap.String("package", pkg.PackageMeta[0].Name), zap.Error(err))
+	}
+}
+
+func unresolvedProtoReferences(pkg *pbsubstreams.Package) (errs []error) {
+	messages := protoMessageNames(pkg.ProtoFiles)
+
+	for _, mod := range pkg.Modules.Modules {
+		var mapOutputType string
+		switch kind := mod.Kind.(type) {
+		case *pbsubstreams.Module_KindStore_:
+			if typeName := strings.TrimPrefix(kind.KindStore.ValueType, "proto:"); typeName != kind.KindStore.ValueType && !messages[typeName] {
+				errs = append(errs, fmt.Errorf("module %q: store value type %q not found in the protobuf definitions of the package", mod.Name, typeName))
+			}
+		case *pbsubstreams.Module_KindMap_:
+			mapOutputType = kind.KindMap.OutputType
+			if typeName := strings.TrimPrefix(mapOutputType, "proto:"); !messages[typeName] {
+				zlog.Warn("map output type not found in the protobuf definitions of the package", zap.String("module", mod.Name), zap.String("type", typeName))
+			}
+		}
+
+		if output := mod.GetOutput(); output != nil && output.Type != mapOutputType {
+			if typeName := strings.TrimPrefix(output.Type, "proto:"); typeName != output.Type && !messages[typeName] {
+				errs = append(errs, fmt.Errorf("module %q: output type %q not found in the protobuf definitions of the package", mod.Name, typeName))
+			}
+		}
+
+		for _, input := range mod.Inputs {
+			if source := input.GetSource(); source != nil && !messages[source.Type] {
+				errs = append(errs, fmt.Errorf("module %q: source type %q not found in the protobuf definitions of the package", mod.Name, source.Type))
+			}
+		}
+	}
+	return errs
+}
+
+// ProtoConflictPolicy decides what happens when an imported package
+// defines a protobuf message or enum differently than the package
+// importing it, or than a previous import.
+type ProtoConflictPolicy string
+
+const (
+	// ProtoConflictError fails the import, the default.
+	ProtoConflictError ProtoConflictPolicy = "error"
+	// ProtoConflictKeepFirst keeps the definition loaded first: the
+	// manifest's own, then the ones of the imports, in order.
+	ProtoConflictKeepFirst ProtoConflictPolicy = "keep_first"
+)
+
+type protoDefinition struct {
+	name string // fully-qualified
+	def  proto.Message
+	file *descriptorpb.FileDescriptorProto
+}
+
+// protoDefinitions returns the top-level messages and enums of `file`,
+// nested types are compared with their parent.
+func protoDefinitions(file *descriptorpb.FileDescriptorProto) (defs []protoDefinition) {
+	prefix := ""
+	if file.GetPackage() != "" {
+		prefix = file.GetPackage() + "."
+	}
+	for _, msg := range file.MessageType {
+		defs = append(defs, protoDefinition{name: prefix + msg.GetName(), def: msg, file: file})
+	}
+	for _, enum := range file.EnumType {
+		defs = append(defs, protoDefinition{name: prefix + enum.GetName(), def: enum, file: file})
+	}
+	return defs
+}
+
+// mergeProtoFiles merges the protobuf files of `src`, imported as
+// `importName`, into `dest` so that each message and enum is defined
+// once. Files named like one of `dest`, and definitions already found
+// in another file, are dropped when identical, and handled according to
+// `policy` otherwise. Files using dropped definitions are made to depend
+// on the files defining them instead.
+func mergeProtoFiles(src, dest *pbsubstreams.Package, importName string, policy ProtoConflictPolicy) error {
+	systemFiles, err := readSystemProtobufs()
+	if err != nil {
+		return fmt.Errorf("reading system protobufs: %w", err)
+	}
+	isSystem := map[string]bool{}
+	for _, file := range systemFiles.File {
+		isSystem[file.GetName()] = true
+	}
+
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+	defs := map[string]protoDefinition{}
+	addFile := func(file *descriptorpb.FileDescriptorProto) {
+		files[file.GetName()] = file
+		for _, def := range protoDefinitions(file) {
+			if _, found := defs[def.name]; !found {
+				defs[def.name] = def
+			}
+		}
+	}
+	for _, file := range dest.ProtoFiles {
+		addFile(file)
+	}
+
+	dropped := map[string]bool{}
+	redirects := map[string][]string{} // file name to the files defining its dropped definitions
+	var kept []*descriptorpb.FileDescriptorProto
+	for _, file := range src.ProtoFiles {
+		name := file.GetName()
+		if existing, found := files[name]; found {
+			// System protobufs are the ones of this version of substreams,
+			// older versions of them in imports are ignored.
+			if !isSystem[name] && !equalProtoFiles(file, existing) {
+				if policy != ProtoConflictKeepFirst {
+					return fmt.Errorf("import %q: protobuf file %q differs from the one already in the package, set 'protobuf.conflictPolicy' to %q to keep the first one", importName, name, ProtoConflictKeepFirst)
+				}
+				zlog.Warn("keeping the first of conflicting protobuf files", zap.String("import", importName), zap.String("proto_file", name))
+			}
+			zlog.Debug("skipping protofile already seen", zap.String("proto_file", name))
+			continue
+		}
+
+		droppedDefs := map[proto.Message]bool{}
+		for _, def := range protoDefinitions(file) {
+			existing, found := defs[def.name]
+			if !found {
+				continue
+			}
+			if !proto.Equal(def.def, existing.def) {
+				if policy != ProtoConflictKeepFirst {
+					return fmt.Errorf("import %q: %q of protobuf file %q conflicts with its definition in %q, set 'protobuf.conflictPolicy' to %q to keep the first one", importName, def.name, name, existing.file.GetName(), ProtoConflictKeepFirst)
+				}
+				zlog.Warn("keeping the first of conflicting protobuf definitions", zap.String("import", importName), zap.String("type", def.name), zap.String("kept_file", existing.file.GetName()), zap.String("dropped_file", name))
+			}
+			droppedDefs[def.def] = true
+			redirects[name] = appendMissing(redirects[name], existing.file.GetName())
+		}
+		if len(droppedDefs) != 0 {
+			removeProtoDefinitions(file, droppedDefs)
+			if len(file.MessageType) == 0 && len(file.EnumType) == 0 && len(file.Service) == 0 && len(file.Extension) == 0 {
+				zlog.Debug("skipping protofile with definitions already seen", zap.String("proto_file", name))
+				dropped[name] = true
+				continue
+			}
+		}
+
+		addFile(file)
+		kept = append(kept, file)
+	}
+
+	for _, file := range kept {
+		redirectProtoDependencies(file, dropped, redirects)
+		dest.ProtoFiles = append(dest.ProtoFiles, file)
+	}
+	return nil
+}
+
+// equalProtoFiles compares files without their source code info, which
+// depends on how they were compiled.
+func equalProtoFiles(a, b *descriptorpb.FileDescriptorProto) bool {
+	a = proto.Clone(a).(*descriptorpb.FileDescriptorProto)
+	b = proto.Clone(b).(*descriptorpb.FileDescriptorProto)
+	a.SourceCodeInfo = nil
+	b.SourceCodeInfo = nil
+	return proto.Equal(a, b)
+}
+
+func removeProtoDefinitions(file *descriptorpb.FileDescriptorProto, removed map[proto.Message]bool) {
+	var messages []*descriptorpb.DescriptorProto
+	messageIndexes := make([]int, len(file.MessageType))
+	for i, msg := range file.MessageType {
+		messageIndexes[i] = -1
+		if !removed[msg] {
+			messageIndexes[i] = len(messages)
+			messages = append(messages, msg)
+		}
+	}
+	var enums []*descriptorpb.EnumDescriptorProto
+	enumIndexes := make([]int, len(file.EnumType))
+	for i, enum := range file.EnumType {
+		enumIndexes[i] = -1
+		if !removed[enum] {
+			enumIndexes[i] = len(enums)
+			enums = append(enums, enum)
+		}
+	}
+	file.MessageType = messages
+	file.EnumType = enums
+
+	if file.SourceCodeInfo != nil {
+		remapSourceCodeInfo(file.SourceCodeInfo, messageIndexes, enumIndexes)
+	}
+}
+
+// Field numbers of `message_type` and `enum_type` in FileDescriptorProto,
+// the first element of the source code info paths of their definitions.
+const (
+	fileMessageTypeField = 4
+	fileEnumTypeField    = 5
+)
+
+// remapSourceCodeInfo updates the locations of the top-level messages and
+// enums of a file to their new index, -1 for the definitions removed,
+// whose locations are dropped.
+func remapSourceCodeInfo(info *descriptorpb.SourceCodeInfo, messageIndexes, enumIndexes []int) {
+	var locations []*descriptorpb.SourceCodeInfo_Location
+	for _, loc := range info.Location {
+		if len(loc.Path) >= 2 {
+			var indexes []int
+			switch loc.Path[0] {
+			case fileMessageTypeField:
+				indexes = messageIndexes
+			case fileEnumTypeField:
+				indexes = enumIndexes
+			}
+			if indexes != nil {
+				old := int(loc.Path[1])
+				if old >= len(indexes) || indexes[old] == -1 {
+					continue
+				}
+				loc.Path[1] = int32(indexes[old])
+			}
+		}
+		locations = append(locations, loc)
+	}
+	info.Location = locations
+}
+
+// redirectProtoDependencies replaces the dependencies of `file` on
+// dropped files by the files defining their definitions, and adds the
+// ones defining the dropped definitions of `file` itself, or of the
+// files it depends on.
+func redirectProtoDependencies(file *descriptorpb.FileDescriptorProto, dropped map[string]bool, redirects map[string][]string) {
+	public := map[string]bool{}
+	for _, idx := range file.PublicDependency {
+		public[file.Dependency[idx]] = true
+	}
+	weak := map[string]bool{}
+	for _, idx := range file.WeakDependency {
+		weak[file.Dependency[idx]] = true
+	}
+
+	var deps []string
+	for _, dep := range file.Dependency {
+		if !dropped[dep] {
+			deps = appendMissing(deps, dep)
+		}
+		for _, redirect := range redirects[dep] {
+			deps = appendMissing(deps, redirect)
+			public[redirect] = public[redirect] || public[dep]
+		}
+	}
+	for _, redirect := range redirects[file.GetName()] {
+		deps = appendMissing(deps, redirect)
+	}
+
+	file.Dependency = deps
+	file.PublicDependency = nil
+	file.WeakDependency = nil
+	for i, dep := range deps {
+		if public[dep] {
+			file.PublicDependency = append(file.PublicDependency, int32(i))
+		}
+		if weak[dep] && !dropped[dep] {
+			file.WeakDependency = append(file.WeakDependency, int32(i))
+		}
+	}
+}
+
+func appendMissing(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
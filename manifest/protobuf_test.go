@@ -0,0 +1,237 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func newProtoRefsTestPackage(storeValueType string) *pbsubstreams.Package {
+	return &pbsubstreams.Package{
+		ProtoFiles: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("pcs.proto"),
+				Package: proto.String("pcs.types.v1"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("Pairs"), NestedType: []*descriptorpb.DescriptorProto{{Name: proto.String("Pair")}}},
+				},
+			},
+			{
+				Name:        proto.String("no_package.proto"),
+				MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Block")}},
+			},
+		},
+		Modules: &pbsubstreams.Modules{
+			Modules: []*pbsubstreams.Module{
+				{
+					Name: "map_pairs",
+					Kind: &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{OutputType: "proto:pcs.types.v1.Unknown"}},
+					Inputs: []*pbsubstreams.Module_Input{
+						{Input: &pbsubstreams.Module_Input_Source_{Source: &pbsubstreams.Module_Input_Source{Type: "Block"}}},
+					},
+				},
+				{
+					Name: "store_pairs",
+					Kind: &pbsubstreams.Module_KindStore_{KindStore: &pbsubstreams.Module_KindStore{ValueType: storeValueType}},
+				},
+			},
+		},
+	}
+}
+
+func TestProtoMessageNames(t *testing.T) {
+	pkg := newProtoRefsTestPackage("bytes")
+	assert.Equal(t, map[string]bool{
+		"pcs.types.v1.Pairs":      true,
+		"pcs.types.v1.Pairs.Pair": true,
+		"Block":                   true,
+	}, protoMessageNames(pkg.ProtoFiles))
+}
+
+func TestValidateProtoReferences(t *testing.T) {
+	// Unresolved map outputs are only warnings
+	assert.NoError(t, validateProtoReferences(newProtoRefsTestPackage("bytes")))
+	assert.NoError(t, validateProtoReferences(newProtoRefsTestPackage("proto:pcs.types.v1.Pairs.Pair")))
+
+	err := validateProtoReferences(newProtoRefsTestPackage("proto:pcs.types.v1.Pair"))
+	assert.EqualError(t, err, `module "store_pairs": store value type "pcs.types.v1.Pair" not found in the protobuf definitions of the package`)
+
+	pkg := newProtoRefsTestPackage("bytes")
+	pkg.Modules.Modules[0].Inputs[0].GetSource().Type = "sf.ethereum.type.v1.Block"
+	err = validateProtoReferences(pkg)
+	assert.EqualError(t, err, `module "map_pairs": source type "sf.ethereum.type.v1.Block" not found in the protobuf definitions of the package`)
+
+	pkg = newProtoRefsTestPackage("bytes")
+	pkg.Modules.Modules[1].Output = &pbsubstreams.Module_Output{Type: "proto:pcs.types.v1.Pair"}
+	err = validateProtoReferences(pkg)
+	assert.EqualError(t, err, `module "store_pairs": output type "pcs.types.v1.Pair" not found in the protobuf definitions of the package`)
+}
+
+func TestReader_PackageWithUnresolvedSourceType(t *testing.T) {
+	pkg, err := NewReader("./test/test_manifest.yaml").Read()
+	require.NoError(t, err)
+
+	// Packages built before the check may not embed their chain's block type
+	var protoFiles []*descriptorpb.FileDescriptorProto
+	for _, file := range pkg.ProtoFiles {
+		if file.GetPackage() != "sf.ethereum.type.v1" {
+			protoFiles = append(protoFiles, file)
+		}
+	}
+	require.Len(t, protoFiles, len(pkg.ProtoFiles)-1)
+	pkg.ProtoFiles = protoFiles
+
+	cnt, err := proto.Marshal(pkg)
+	require.NoError(t, err)
+	pkgPath := filepath.Join(t.TempDir(), "pcs-v0.1.0.spkg")
+	require.NoError(t, ioutil.WriteFile(pkgPath, cnt, 0644))
+
+	_, err = NewReader(pkgPath).Read()
+	assert.NoError(t, err)
+}
+
+func newProtoMergeTestFile(name, pkg string, deps []string, messages ...*descriptorpb.DescriptorProto) *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(name),
+		Package:     proto.String(pkg),
+		Dependency:  deps,
+		MessageType: messages,
+	}
+}
+
+func newProtoMergeTestMessage(name string, fields ...string) *descriptorpb.DescriptorProto {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	for i, field := range fields {
+		msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(field),
+			Number: proto.Int32(int32(i + 1)),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		})
+	}
+	return msg
+}
+
+func protoFileNames(files []*descriptorpb.FileDescriptorProto) (names []string) {
+	for _, file := range files {
+		names = append(names, file.GetName())
+	}
+	return names
+}
+
+func TestMergeProtoFiles_Deduplicates(t *testing.T) {
+	dest := &pbsubstreams.Package{ProtoFiles: []*descriptorpb.FileDescriptorProto{
+		newProtoMergeTestFile("tokens.proto", "tokens.v1", nil, newProtoMergeTestMessage("Token", "address")),
+	}}
+	src := &pbsubstreams.Package{ProtoFiles: []*descriptorpb.FileDescriptorProto{
+		// Same file
+		newProtoMergeTestFile("tokens.proto", "tokens.v1", nil, newProtoMergeTestMessage("Token", "address")),
+		// Same definitions under another name
+		newProtoMergeTestFile("vendor/tokens.proto", "tokens.v1", nil, newProtoMergeTestMessage("Token", "address")),
+		// Some definitions already seen
+		newProtoMergeTestFile("pcs.proto", "tokens.v1", []string{"vendor/tokens.proto"},
+			newProtoMergeTestMessage("Pair", "token0", "token1"),
+			newProtoMergeTestMessage("Token", "address"),
+		),
+		newProtoMergeTestFile("prices.proto", "prices.v1", []string{"pcs.proto"}, newProtoMergeTestMessage("Price", "pair")),
+	}}
+
+	require.NoError(t, mergeProtoFiles(src, dest, "pcs", ""))
+
+	assert.Equal(t, []string{"tokens.proto", "pcs.proto", "prices.proto"}, protoFileNames(dest.ProtoFiles))
+	assert.Equal(t, []string{"tokens.proto"}, dest.ProtoFiles[1].Dependency)
+	assert.Len(t, dest.ProtoFiles[1].MessageType, 1)
+	assert.Equal(t, "Pair", dest.ProtoFiles[1].MessageType[0].GetName())
+	assert.Equal(t, []string{"pcs.proto", "tokens.proto"}, dest.ProtoFiles[2].Dependency)
+}
+
+func TestRemoveProtoDefinitions_SourceCodeInfo(t *testing.T) {
+	file := newProtoMergeTestFile("pcs.proto", "pcs.v1", nil,
+		newProtoMergeTestMessage("Token", "address"),
+		newProtoMergeTestMessage("Pair", "token0", "token1"),
+		newProtoMergeTestMessage("Price", "pair"),
+	)
+	file.EnumType = []*descriptorpb.EnumDescriptorProto{{Name: proto.String("Kind")}, {Name: proto.String("Side")}}
+
+	location := func(comment string, path ...int32) *descriptorpb.SourceCodeInfo_Location {
+		return &descriptorpb.SourceCodeInfo_Location{Path: path, LeadingComments: proto.String(comment)}
+	}
+	file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{Location: []*descriptorpb.SourceCodeInfo_Location{
+		location("package", 2),
+		location("Token", 4, 0),
+		location("Pair", 4, 1),
+		location("Pair.token1", 4, 1, 2, 1),
+		location("Price", 4, 2),
+		location("Kind", 5, 0),
+		location("Side", 5, 1),
+	}}
+
+	removeProtoDefinitions(file, map[proto.Message]bool{file.MessageType[0]: true, file.EnumType[0]: true})
+
+	var paths [][]int32
+	var comments []string
+	for _, loc := range file.SourceCodeInfo.Location {
+		paths = append(paths, loc.Path)
+		comments = append(comments, loc.GetLeadingComments())
+	}
+	assert.Equal(t, []string{"package", "Pair", "Pair.token1", "Price", "Side"}, comments)
+	assert.Equal(t, [][]int32{{2}, {4, 0}, {4, 0, 2, 1}, {4, 1}, {5, 0}}, paths)
+}
+
+func TestMergeProtoFiles_Conflicts(t *testing.T) {
+	newPackages := func() (src, dest *pbsubstreams.Package) {
+		dest = &pbsubstreams.Package{ProtoFiles: []*descriptorpb.FileDescriptorProto{
+			newProtoMergeTestFile("tokens.proto", "tokens.v1", nil, newProtoMergeTestMessage("Token", "address")),
+		}}
+		src = &pbsubstreams.Package{ProtoFiles: []*descriptorpb.FileDescriptorProto{
+			newProtoMergeTestFile("vendor/tokens.proto", "tokens.v1", nil,
+				newProtoMergeTestMessage("Token", "address", "decimals"),
+				newProtoMergeTestMessage("Tokens", "tokens"),
+			),
+		}}
+		return src, dest
+	}
+
+	src, dest := newPackages()
+	err := mergeProtoFiles(src, dest, "tokens", "")
+	assert.EqualError(t, err, `import "tokens": "tokens.v1.Token" of protobuf file "vendor/tokens.proto" conflicts with its definition in "tokens.proto", set 'protobuf.conflictPolicy' to "keep_first" to keep the first one`)
+
+	src, dest = newPackages()
+	require.NoError(t, mergeProtoFiles(src, dest, "tokens", ProtoConflictKeepFirst))
+	assert.Equal(t, []string{"tokens.proto", "vendor/tokens.proto"}, protoFileNames(dest.ProtoFiles))
+	assert.Len(t, dest.ProtoFiles[0].MessageType[0].Field, 1)
+	assert.Equal(t, []string{"tokens.proto"}, dest.ProtoFiles[1].Dependency)
+	assert.Len(t, dest.ProtoFiles[1].MessageType, 1)
+}
+
+func TestMergeProtoFiles_FileConflicts(t *testing.T) {
+	newPackages := func(name string) (src, dest *pbsubstreams.Package) {
+		dest = &pbsubstreams.Package{ProtoFiles: []*descriptorpb.FileDescriptorProto{
+			newProtoMergeTestFile(name, "tokens.v1", nil, newProtoMergeTestMessage("Token", "address")),
+		}}
+		src = &pbsubstreams.Package{ProtoFiles: []*descriptorpb.FileDescriptorProto{
+			newProtoMergeTestFile(name, "tokens.v1", nil, newProtoMergeTestMessage("Token", "address", "decimals")),
+		}}
+		return src, dest
+	}
+
+	src, dest := newPackages("tokens.proto")
+	err := mergeProtoFiles(src, dest, "tokens", ProtoConflictError)
+	assert.EqualError(t, err, `import "tokens": protobuf file "tokens.proto" differs from the one already in the package, set 'protobuf.conflictPolicy' to "keep_first" to keep the first one`)
+
+	src, dest = newPackages("tokens.proto")
+	require.NoError(t, mergeProtoFiles(src, dest, "tokens", ProtoConflictKeepFirst))
+	require.Len(t, dest.ProtoFiles, 1)
+	assert.Len(t, dest.ProtoFiles[0].MessageType[0].Field, 1)
+
+	// Imported system protobufs are older versions of the ones of the package
+	src, dest = newPackages("sf/substreams/v1/modules.proto")
+	require.NoError(t, mergeProtoFiles(src, dest, "tokens", ProtoConflictError))
+	require.Len(t, dest.ProtoFiles, 1)
+	assert.Len(t, dest.ProtoFiles[0].MessageType[0].Field, 1)
+}
@@ -1,16 +1,16 @@
 package manifest
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"path"
 	"path/filepath"
 	"strings"
 
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
-	"go.uber.org/zap"
+	"github.com/streamingfast/substreams/wasm/wasmbin"
 	"golang.org/x/mod/semver"
 	"google.golang.org/protobuf/proto"
 )
@@ -24,15 +24,52 @@ func SkipSourceCodeReader() Options {
 	}
 }
 
+// WithPackageCacheDir sets the directory where imported remote packages
+// are cached, empty to disable the cache. Defaults to
+// `DefaultPackageCacheDir()`.
+func WithPackageCacheDir(dir string) Options {
+	return func(r *Reader) *Reader {
+		r.packageCacheDir = dir
+		return r
+	}
+}
+
+// WithTrustedKeys refuses packages, including imported ones, without a
+// valid signature by one of `keys`. Manifests are not signed, only the
+// packages they import are checked.
+func WithTrustedKeys(keys []ed25519.PublicKey) Options {
+	return func(r *Reader) *Reader {
+		r.requireSignatures = true
+		r.trustedKeys = keys
+		return r
+	}
+}
+
+// WithLockfileUpdate writes the lockfile of the manifest when the sha256
+// of its remote imports changed, as `substreams pack` does. Other reads
+// only check the imports against the lockfile.
+func WithLockfileUpdate() Options {
+	return func(r *Reader) *Reader {
+		r.updateLockfile = true
+		return r
+	}
+}
+
 type Reader struct {
 	input string
 
 	//options
 	skipSourceCodeImportValidation bool
+	packageCacheDir                string
+	requireSignatures              bool
+	trustedKeys                    []ed25519.PublicKey
+	updateLockfile                 bool
+
+	options []Options // passed on to the readers of imports
 }
 
 func NewReader(input string, opts ...Options) *Reader {
-	r := &Reader{input: input}
+	r := &Reader{input: input, packageCacheDir: DefaultPackageCacheDir(), options: opts}
 	for _, opt := range opts {
 		r = opt(r)
 	}
@@ -56,18 +93,20 @@ func (r *Reader) newPkgFromFile(inputFilePath string) (pkg *pbsubstreams.Package
 	if err != nil {
 		return nil, fmt.Errorf("error reading %q: %w", inputFilePath, err)
 	}
+	if err := r.verifySignature(inputFilePath, cnt, ""); err != nil {
+		return nil, err
+	}
 
 	return r.fromContents(cnt)
 }
 
 func (r *Reader) newPkgFromURL(fileURL string) (pkg *pbsubstreams.Package, err error) {
-	resp, err := http.DefaultClient.Get(fileURL)
+	cnt, err := downloadPackage(fileURL)
 	if err != nil {
-		return nil, fmt.Errorf("error downloading %q: %w", fileURL, err)
+		return nil, fmt.Errorf("error fetching %q: %w", fileURL, err)
 	}
-	cnt, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading %q: %w", fileURL, err)
+	if err := r.verifySignature(fileURL, cnt, ""); err != nil {
+		return nil, err
 	}
 	return r.fromContents(cnt)
 }
@@ -86,6 +125,9 @@ func (r *Reader) newPkgFromManifest(inputPath string) (pkg *pbsubstreams.Package
 	if err := r.validate(pkg); err != nil {
 		return nil, fmt.Errorf("failed validation: %w", err)
 	}
+	if err := validateProtoReferences(pkg); err != nil {
+		return nil, fmt.Errorf("failed validation: %w", err)
+	}
 
 	return pkg, nil
 }
@@ -99,6 +141,7 @@ func (r *Reader) fromContents(contents []byte) (pkg *pbsubstreams.Package, err e
 	if err := r.validate(pkg); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
+	warnProtoReferences(pkg)
 
 	return pkg, nil
 }
@@ -152,20 +195,35 @@ func (r *Reader) validatePackage(pkg *pbsubstreams.Package) error {
 				return fmt.Errorf("module %q: invalid valueType %q", mod.Name, valueType)
 			}
 		}
-
-		for _, in := range mod.Inputs {
-			_ = in
-			// TODO: do more proto ref checking for those inputs listed
-		}
 	}
+	return nil
+}
 
-	// TODO: Loop through inputs, outputs, and check that all internal proto references are satisfied by the FileDescriptors
+type ValidateOption func(o *validateOptions)
 
-	return nil
+type validateOptions struct {
+	wasmExtensions      map[string][]string
+	knownWASMExtensions bool
+}
+
+// WithWASMExtensions validates the imports of the wasm binaries against
+// `extensions`, the names of the functions of the only extensions
+// available by namespace, instead of accepting any extension namespace.
+// Meant for the server, see `wasm.ExtensionFunctions`.
+func WithWASMExtensions(extensions map[string][]string) ValidateOption {
+	return func(o *validateOptions) {
+		o.wasmExtensions = extensions
+		o.knownWASMExtensions = true
+	}
 }
 
 // ValidateModules is run both by the client _and_ the server.
-func ValidateModules(mods *pbsubstreams.Modules) error {
+func ValidateModules(mods *pbsubstreams.Modules, opts ...ValidateOption) error {
+	options := &validateOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	var sumCode int
 	for _, binary := range mods.Binaries {
 		sumCode += len(binary.Content)
@@ -228,13 +286,68 @@ func ValidateModules(mods *pbsubstreams.Modules) error {
 				default:
 					return fmt.Errorf("module %q: input index %d: unknown store mode value %d", mod.Name, idx, i.Store.Mode)
 				}
+			case *pbsubstreams.Module_Input_Params_:
+				if idx != paramsInputIndex(mod) {
+					return fmt.Errorf("module %q: input index %d: only one 'params' input allowed", mod.Name, idx)
+				}
+			default:
+				return fmt.Errorf("module %q: input index %d: unknown input type %T", mod.Name, idx, in.Input)
 			}
 		}
 	}
 
+	return validateBinaries(mods, options)
+}
+
+// validateBinaries checks the imports of the wasm binaries and the
+// entrypoints of the modules, which would otherwise only fail when
+// instantiated on the server.
+func validateBinaries(mods *pbsubstreams.Modules, options *validateOptions) error {
+	entrypoints := make([]map[string]int, len(mods.Binaries))
+	for _, mod := range mods.Modules {
+		idx := int(mod.BinaryIndex)
+		if idx >= len(mods.Binaries) {
+			return fmt.Errorf("module %q: invalid binary index %d, only %d binaries", mod.Name, idx, len(mods.Binaries))
+		}
+		if entrypoints[idx] == nil {
+			entrypoints[idx] = map[string]int{}
+		}
+
+		params := entrypointParams(mod)
+		if other, found := entrypoints[idx][mod.BinaryEntrypoint]; found && other != params {
+			return fmt.Errorf("module %q: entrypoint %q shared with a module taking %d parameters, this one takes %d", mod.Name, mod.BinaryEntrypoint, other, params)
+		}
+		entrypoints[idx][mod.BinaryEntrypoint] = params
+	}
+
+	for idx, binary := range mods.Binaries {
+		if binary.Type != "wasm/rust-v1" {
+			continue
+		}
+		if err := wasmbin.Validate(binary.Content, entrypoints[idx], options.wasmExtensions, !options.knownWASMExtensions); err != nil {
+			return fmt.Errorf("binary %d: %w", idx, err)
+		}
+	}
 	return nil
 }
 
+// entrypointParams is the number of parameters the runtime passes to the
+// entrypoint of a module: a pointer and a length for sources, maps and
+// store deltas and params, and a store index for stores in get mode.
+func entrypointParams(mod *pbsubstreams.Module) (params int) {
+	for _, in := range mod.Inputs {
+		switch i := in.Input.(type) {
+		case *pbsubstreams.Module_Input_Store_:
+			if i.Store.Mode == pbsubstreams.Module_Input_Store_GET {
+				params++
+				continue
+			}
+		}
+		params += 2
+	}
+	return params
+}
+
 func loadManifestFile(inputPath string) (*Manifest, error) {
 	m, err := decodeYamlManifestFromFile(inputPath)
 	if err != nil {
@@ -252,6 +365,24 @@ func loadManifestFile(inputPath string) (*Manifest, error) {
 		return nil, fmt.Errorf("invalid 'specVersion', must be v0.1.0")
 	}
 
+	for _, imp := range m.Imports {
+		if err := imp.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, target := range sortedOverrideTargets(m.Overrides) {
+		if err := m.Overrides[target].validate(target, m); err != nil {
+			return nil, err
+		}
+	}
+
+	switch m.Protobuf.ConflictPolicy {
+	case "", ProtoConflictError, ProtoConflictKeepFirst:
+	default:
+		return nil, fmt.Errorf("invalid 'protobuf.conflictPolicy' %q, must be %q or %q", m.Protobuf.ConflictPolicy, ProtoConflictError, ProtoConflictKeepFirst)
+	}
+
 	// TODO: put some limits on the NUMBER of modules (max 50 ?)
 	// TODO: put a limit on the SIZE of the WASM payload (max 10MB per binary?)
 
@@ -282,26 +413,6 @@ func loadManifestFile(inputPath string) (*Manifest, error) {
 	return m, nil
 }
 
-func loadImports(pkg *pbsubstreams.Package, manif *Manifest) error {
-	for _, kv := range manif.Imports {
-		importName := kv[0]
-		importPath := kv[1]
-
-		subpkgReader := NewReader(importPath)
-		subpkg, err := subpkgReader.Read()
-		if err != nil {
-			return fmt.Errorf("importing %q: %w", importPath, err)
-		}
-
-		prefixModules(subpkg.Modules.Modules, importName)
-		reindexAndMergePackage(subpkg, pkg)
-		mergeProtoFiles(subpkg, pkg)
-	}
-	// loop through the Manifest, and get the `imports` statements,
-	// pull the Package files from Disk, and merge them into this one
-	return nil
-}
-
 const PrefixSeparator = ":"
 
 func prefixModules(mods []*pbsubstreams.Module, prefix string) {
@@ -314,6 +425,7 @@ func prefixModules(mods []*pbsubstreams.Module, prefix string) {
 				input.Store.ModuleName = prefix + PrefixSeparator + input.Store.ModuleName
 			case *pbsubstreams.Module_Input_Map_:
 				input.Map.ModuleName = prefix + PrefixSeparator + input.Map.ModuleName
+			case *pbsubstreams.Module_Input_Params_:
 			default:
 				panic(fmt.Sprintf("unsupported module type %s", inputIface.Input))
 			}
@@ -339,31 +451,6 @@ func reindexAndMergePackage(src, dest *pbsubstreams.Package) {
 	dest.PackageMeta = append(dest.PackageMeta, src.PackageMeta...)
 }
 
-func mergeProtoFiles(src, dest *pbsubstreams.Package) {
-	seenFiles := map[string]bool{}
-
-	for _, file := range dest.ProtoFiles {
-		seenFiles[*file.Name] = true
-	}
-
-	for _, file := range src.ProtoFiles {
-		key := *file.Name
-		if seenFiles[key] {
-			zlog.Debug("skipping protofile already seen", zap.String("proto_file", *file.Name))
-			continue
-		}
-		seenFiles[key] = true
-		dest.ProtoFiles = append(dest.ProtoFiles, file)
-	}
-
-	// TODO: do DEDUPLICATION of those protofiles and/or of the messages therein,
-	// otherwise they can duplicate quickly.
-
-	// TODO: eventually, we want the last Message type to win, or perhaps we'd search in reverse order
-	// upon `print` or generation? The thing is we'll want tools like `protoc` and `buf` to use the most
-	// recent, but it'll simply go in list order..
-}
-
 // manifestToPkg will take a Manifest object, most likely generated from a YAML file, and will create a Proto Pakcage object
 // in some cases we do not want to validate the package and ensure that all the code and dependencies are there fro example
 // when we are using the generated package transitively
@@ -377,10 +464,18 @@ func (r *Reader) manifestToPkg(m *Manifest) (*pbsubstreams.Package, error) {
 		return nil, fmt.Errorf("error loading protobuf: %w", err)
 	}
 
-	if err := loadImports(pkg, m); err != nil {
+	if err := r.loadImports(pkg, m); err != nil {
 		return nil, fmt.Errorf("error loading imports: %w", err)
 	}
 
+	if err := r.applyOverrides(pkg, m); err != nil {
+		return nil, fmt.Errorf("error applying overrides: %w", err)
+	}
+
+	if err := ApplyParams(m.Params, pkg.Modules); err != nil {
+		return nil, fmt.Errorf("error applying params: %w", err)
+	}
+
 	return pkg, nil
 }
 
@@ -415,28 +510,19 @@ func (r *Reader) convertToPkg(m *Manifest) (pkg *pbsubstreams.Package, err error
 			return nil, fmt.Errorf("module %q refers to %sbinary %q, which is not defined in the 'binaries' section of the manifest", mod.Name, implicit, binaryName)
 		}
 
-		switch binaryDef.Type {
-		case "wasm/rust-v1":
-			// OPTIM(abourget): also check if it's not already in
-			// `Binaries`, by comparing its, length + hash or value.
-			codeIndex, found := moduleCodeIndexes[binaryDef.File]
-			if !found {
-				codePath := binaryDef.File
-				var byteCode []byte
-				if !r.skipSourceCodeImportValidation {
-					byteCode, err = ioutil.ReadFile(codePath)
-					if err != nil {
-						return nil, fmt.Errorf("failed to read source code %q: %w", codePath, err)
-					}
-				}
-				pkg.Modules.Binaries = append(pkg.Modules.Binaries, &pbsubstreams.Binary{Type: binaryDef.Type, Content: byteCode})
-				codeIndex = len(pkg.Modules.Binaries) - 1
-				moduleCodeIndexes[binaryDef.File] = codeIndex
+		// OPTIM(abourget): also check if it's not already in
+		// `Binaries`, by comparing its, length + hash or value.
+		codeIndex, found := moduleCodeIndexes[binaryDef.File]
+		if !found {
+			code, err := r.readBinary(binaryDef)
+			if err != nil {
+				return nil, fmt.Errorf("module %q: %w", mod.Name, err)
 			}
-			pbmod, err = mod.ToProtoWASM(uint32(codeIndex))
-		default:
-			return nil, fmt.Errorf("module %q: invalid code type %q", mod.Name, binaryDef.Type)
+			pkg.Modules.Binaries = append(pkg.Modules.Binaries, code)
+			codeIndex = len(pkg.Modules.Binaries) - 1
+			moduleCodeIndexes[binaryDef.File] = codeIndex
 		}
+		pbmod, err = mod.ToProtoWASM(uint32(codeIndex))
 		if err != nil {
 			return nil, err
 		}
@@ -449,6 +535,23 @@ func (r *Reader) convertToPkg(m *Manifest) (pkg *pbsubstreams.Package, err error
 	return
 }
 
+func (r *Reader) readBinary(binaryDef Binary) (*pbsubstreams.Binary, error) {
+	switch binaryDef.Type {
+	case "wasm/rust-v1":
+		var byteCode []byte
+		if !r.skipSourceCodeImportValidation {
+			var err error
+			byteCode, err = ioutil.ReadFile(binaryDef.File)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read source code %q: %w", binaryDef.File, err)
+			}
+		}
+		return &pbsubstreams.Binary{Type: binaryDef.Type, Content: byteCode}, nil
+	default:
+		return nil, fmt.Errorf("invalid code type %q", binaryDef.Type)
+	}
+}
+
 var validValueTypes = map[string]bool{
 	"bigint":   true,
 	"int64":    true,
@@ -10,9 +10,103 @@ import (
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 )
 
+// ModuleHashVersion is the version of the hashes computed by
+// `HashModule`, written first in the hashed content. Bump it on any
+// change of what is hashed, caches are keyed by module hash.
+const ModuleHashVersion = 2
+
 type ModuleHash []byte
 
+// HashModule hashes everything that determines the outputs of `module`:
+// its kind, binary and entrypoint, initial block, and each of its inputs,
+// with the hash of the module they reference.
 func HashModule(modules *pbsubstreams.Modules, module *pbsubstreams.Module, graph *ModuleGraph) ModuleHash {
+	return hashModule(modules, module, graph, map[string]ModuleHash{})
+}
+
+func hashModule(modules *pbsubstreams.Modules, module *pbsubstreams.Module, graph *ModuleGraph, cache map[string]ModuleHash) ModuleHash {
+	if hash, found := cache[module.Name]; found {
+		return hash
+	}
+
+	buf := bytes.NewBuffer(nil)
+	writeHashField(buf, "version", fmt.Sprintf("v%d", ModuleHashVersion))
+
+	initialBlockBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(initialBlockBytes, module.InitialBlock) // start block resolved at this point
+	writeHashField(buf, "initial_block", string(initialBlockBytes))
+
+	switch kind := module.Kind.(type) {
+	case *pbsubstreams.Module_KindMap_:
+		writeHashField(buf, "kind", "map")
+		writeHashField(buf, "output_type", kind.KindMap.OutputType)
+	case *pbsubstreams.Module_KindStore_:
+		writeHashField(buf, "kind", "store")
+		writeHashField(buf, "update_policy", kind.KindStore.UpdatePolicy.String())
+		writeHashField(buf, "value_type", kind.KindStore.ValueType)
+	default:
+		panic(fmt.Sprintf("invalid module file %T", module.Kind))
+	}
+
+	code := modules.Binaries[module.BinaryIndex]
+	writeHashField(buf, "binary_type", code.Type)
+	writeHashField(buf, "binary", string(code.Content))
+	writeHashField(buf, "entrypoint", module.BinaryEntrypoint)
+
+	if module.Output != nil {
+		writeHashField(buf, "output", module.Output.Type)
+	}
+
+	for _, input := range module.Inputs {
+		writeHashField(buf, "input", inputName(input))
+		switch in := input.Input.(type) {
+		case *pbsubstreams.Module_Input_Source_:
+			writeHashField(buf, "type", in.Source.Type)
+		case *pbsubstreams.Module_Input_Map_:
+			writeHashField(buf, "module", in.Map.ModuleName)
+			writeHashField(buf, "module_hash", string(hashInputModule(modules, in.Map.ModuleName, graph, cache)))
+		case *pbsubstreams.Module_Input_Store_:
+			writeHashField(buf, "module", in.Store.ModuleName)
+			writeHashField(buf, "mode", in.Store.Mode.String())
+			writeHashField(buf, "module_hash", string(hashInputModule(modules, in.Store.ModuleName, graph, cache)))
+		case *pbsubstreams.Module_Input_Params_:
+			writeHashField(buf, "value", in.Params.Value)
+		}
+	}
+
+	h := sha1.New()
+	h.Write(buf.Bytes())
+
+	hash := h.Sum(nil)
+	cache[module.Name] = hash
+	return hash
+}
+
+func hashInputModule(modules *pbsubstreams.Modules, moduleName string, graph *ModuleGraph, cache map[string]ModuleHash) ModuleHash {
+	idx, found := graph.moduleIndex[moduleName]
+	if !found {
+		panic(fmt.Sprintf("input module %q not found in graph", moduleName))
+	}
+	return hashModule(modules, graph.indexIndex[idx], graph, cache)
+}
+
+// writeHashField writes `value` length-prefixed, so the content of
+// adjacent fields cannot be shifted from one to the other.
+func writeHashField(buf *bytes.Buffer, key, value string) {
+	lenBytes := make([]byte, binary.MaxVarintLen64)
+	buf.WriteString(key)
+	buf.Write(lenBytes[:binary.PutUvarint(lenBytes, uint64(len(value)))])
+	buf.WriteString(value)
+}
+
+func HashModuleAsString(modules *pbsubstreams.Modules, graph *ModuleGraph, module *pbsubstreams.Module) string {
+	return hex.EncodeToString(HashModule(modules, module, graph))
+}
+
+// HashModuleV1 is the hash of `module` before `ModuleHashVersion` 2,
+// which covered neither the modules its inputs read, nor store modes,
+// nor output types. Only meant to find caches written with it.
+func HashModuleV1(modules *pbsubstreams.Modules, module *pbsubstreams.Module, graph *ModuleGraph) ModuleHash {
 	buf := bytes.NewBuffer(nil)
 
 	initialBlockBytes := make([]byte, 8)
@@ -43,7 +137,7 @@ func HashModule(modules *pbsubstreams.Modules, module *pbsubstreams.Module, grap
 	buf.WriteString("ancestors")
 	ancestors, _ := graph.AncestorsOf(module.Name)
 	for _, ancestor := range ancestors {
-		sig := HashModule(modules, ancestor, graph)
+		sig := HashModuleV1(modules, ancestor, graph)
 		buf.Write(sig)
 	}
 
@@ -52,9 +146,11 @@ func HashModule(modules *pbsubstreams.Modules, module *pbsubstreams.Module, grap
 
 	return h.Sum(nil)
 }
-func HashModuleAsString(modules *pbsubstreams.Modules, graph *ModuleGraph, module *pbsubstreams.Module) string {
-	return hex.EncodeToString(HashModule(modules, module, graph))
+
+func HashModuleV1AsString(modules *pbsubstreams.Modules, graph *ModuleGraph, module *pbsubstreams.Module) string {
+	return hex.EncodeToString(HashModuleV1(modules, module, graph))
 }
+
 func inputName(input *pbsubstreams.Module_Input) string {
 	switch input.Input.(type) {
 	case *pbsubstreams.Module_Input_Store_:
@@ -63,6 +159,8 @@ func inputName(input *pbsubstreams.Module_Input) string {
 		return "source"
 	case *pbsubstreams.Module_Input_Map_:
 		return "map"
+	case *pbsubstreams.Module_Input_Params_:
+		return "params"
 	default:
 		panic(fmt.Sprintf("invalid input %T", input.Input))
 	}
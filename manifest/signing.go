@@ -0,0 +1,193 @@
+package manifest
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+)
+
+// SignatureFileSuffix is appended to the path of a package to get the
+// path of its detached signature, ex: `pcs-v0.1.0.spkg.sig`.
+const SignatureFileSuffix = ".sig"
+
+// signatureContext prefixes the signed content, so package signatures
+// cannot be mistaken for signatures of anything else.
+const signatureContext = "substreams package signature v1\x00"
+
+// PackageSignature is a detached ed25519 signature of a marshalled
+// package, the content of an `.spkg` file.
+type PackageSignature struct {
+	PublicKey []byte `json:"public_key"`
+	Signature []byte `json:"signature"`
+}
+
+func SignPackage(cnt []byte, key ed25519.PrivateKey) *PackageSignature {
+	return &PackageSignature{
+		PublicKey: key.Public().(ed25519.PublicKey),
+		Signature: ed25519.Sign(key, append([]byte(signatureContext), cnt...)),
+	}
+}
+
+// VerifyPackage checks that `sig` is a valid signature of `cnt` by one
+// of the `trustedKeys`.
+func VerifyPackage(cnt []byte, sig *PackageSignature, trustedKeys []ed25519.PublicKey) error {
+	if len(sig.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid signature public key")
+	}
+	publicKey := ed25519.PublicKey(sig.PublicKey)
+
+	trusted := false
+	for _, key := range trustedKeys {
+		if key.Equal(publicKey) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return fmt.Errorf("signed by untrusted key %s", KeyID(publicKey))
+	}
+
+	if !ed25519.Verify(publicKey, append([]byte(signatureContext), cnt...), sig.Signature) {
+		return fmt.Errorf("invalid signature by key %s", KeyID(publicKey))
+	}
+	return nil
+}
+
+func EncodePackageSignature(sig *PackageSignature) ([]byte, error) {
+	return json.Marshal(sig)
+}
+
+func DecodePackageSignature(cnt []byte) (*PackageSignature, error) {
+	sig := &PackageSignature{}
+	if err := json.Unmarshal(cnt, sig); err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+	return sig, nil
+}
+
+// KeyID is a short identifier of a public key, to show to users.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// EncodePrivateKey encodes `key` as a PKCS #8 PEM block.
+func EncodePrivateKey(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodePublicKey encodes `key` as a PKIX PEM block.
+func EncodePublicKey(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ReadPrivateKeyFile reads an ed25519 private key written by
+// `EncodePrivateKey`.
+func ReadPrivateKeyFile(filename string) (ed25519.PrivateKey, error) {
+	cnt, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading private key %q: %w", filename, err)
+	}
+
+	block, _ := pem.Decode(cnt)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("private key %q: expected a PEM 'PRIVATE KEY' block", filename)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("private key %q: %w", filename, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %q: expected an ed25519 key, got %T", filename, key)
+	}
+	return privateKey, nil
+}
+
+// ReadPublicKeysFile reads the ed25519 public keys of a file of PEM
+// blocks written by `EncodePublicKey`.
+func ReadPublicKeysFile(filename string) (keys []ed25519.PublicKey, err error) {
+	cnt, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading public keys %q: %w", filename, err)
+	}
+
+	for {
+		var block *pem.Block
+		block, cnt = pem.Decode(cnt)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("public keys %q: %w", filename, err)
+		}
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public keys %q: expected ed25519 keys, got %T", filename, key)
+		}
+		keys = append(keys, publicKey)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("public keys %q: no PEM 'PUBLIC KEY' block found", filename)
+	}
+	return keys, nil
+}
+
+// verifySignature checks the signature of the package at `location`,
+// read from `<location>.sig`, when the reader requires signatures.
+// Signatures of remote packages are kept in the package cache with
+// them, under the package sha256 `sum`, when given.
+func (r *Reader) verifySignature(location string, cnt []byte, sum string) error {
+	if !r.requireSignatures {
+		return nil
+	}
+
+	var sigCnt []byte
+	cacheable := sum != "" && isRemotePath(location)
+	if cacheable {
+		sigCnt, _ = r.cachedFile(sum, ".spkg"+SignatureFileSuffix)
+	}
+	if sigCnt == nil {
+		var err error
+		if isRemotePath(location) {
+			sigCnt, err = downloadPackage(location + SignatureFileSuffix)
+		} else {
+			sigCnt, err = ioutil.ReadFile(location + SignatureFileSuffix)
+		}
+		if err != nil {
+			return fmt.Errorf("package %q: reading signature: %w", location, err)
+		}
+	}
+
+	sig, err := DecodePackageSignature(sigCnt)
+	if err != nil {
+		return fmt.Errorf("package %q: %w", location, err)
+	}
+	if err := VerifyPackage(cnt, sig, r.trustedKeys); err != nil {
+		return fmt.Errorf("package %q: %w", location, err)
+	}
+
+	if cacheable {
+		r.cacheFile(sum, ".spkg"+SignatureFileSuffix, sigCnt)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package manifest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return publicKey, privateKey
+}
+
+func TestVerifyPackage(t *testing.T) {
+	publicKey, privateKey := newTestKey(t)
+	otherKey, _ := newTestKey(t)
+
+	cnt := []byte("package content")
+	sig := SignPackage(cnt, privateKey)
+
+	assert.NoError(t, VerifyPackage(cnt, sig, []ed25519.PublicKey{otherKey, publicKey}))
+	assert.EqualError(t, VerifyPackage(cnt, sig, []ed25519.PublicKey{otherKey}), fmt.Sprintf("signed by untrusted key %s", KeyID(publicKey)))
+	assert.EqualError(t, VerifyPackage([]byte("package contents"), sig, []ed25519.PublicKey{publicKey}), fmt.Sprintf("invalid signature by key %s", KeyID(publicKey)))
+
+	encoded, err := EncodePackageSignature(sig)
+	require.NoError(t, err)
+	decoded, err := DecodePackageSignature(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, sig, decoded)
+}
+
+func TestKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	publicKey, privateKey := newTestKey(t)
+	otherKey, _ := newTestKey(t)
+
+	privateCnt, err := EncodePrivateKey(privateKey)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "signer.key.pem"), privateCnt, 0600))
+
+	var publicCnt []byte
+	for _, key := range []ed25519.PublicKey{publicKey, otherKey} {
+		cnt, err := EncodePublicKey(key)
+		require.NoError(t, err)
+		publicCnt = append(publicCnt, cnt...)
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "trusted.pem"), publicCnt, 0644))
+
+	readPrivateKey, err := ReadPrivateKeyFile(filepath.Join(dir, "signer.key.pem"))
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, readPrivateKey)
+
+	readPublicKeys, err := ReadPublicKeysFile(filepath.Join(dir, "trusted.pem"))
+	require.NoError(t, err)
+	assert.Equal(t, []ed25519.PublicKey{publicKey, otherKey}, readPublicKeys)
+
+	_, err = ReadPublicKeysFile(filepath.Join(dir, "signer.key.pem"))
+	assert.Error(t, err)
+}
+
+func TestReader_WithTrustedKeys(t *testing.T) {
+	publicKey, privateKey := newTestKey(t)
+	otherKey, _ := newTestKey(t)
+
+	pkg, err := NewReader("./test/test_manifest.yaml").Read()
+	require.NoError(t, err)
+	cnt, err := proto.Marshal(pkg)
+	require.NoError(t, err)
+	sigCnt, err := EncodePackageSignature(SignPackage(cnt, privateKey))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	pkgPath := filepath.Join(dir, "pcs.spkg")
+	require.NoError(t, ioutil.WriteFile(pkgPath, cnt, 0644))
+
+	_, err = NewReader(pkgPath, WithTrustedKeys([]ed25519.PublicKey{publicKey})).Read()
+	assert.True(t, strings.HasPrefix(err.Error(), fmt.Sprintf("package %q: reading signature:", pkgPath)), err.Error())
+
+	require.NoError(t, ioutil.WriteFile(pkgPath+SignatureFileSuffix, sigCnt, 0644))
+	_, err = NewReader(pkgPath, WithTrustedKeys([]ed25519.PublicKey{publicKey})).Read()
+	assert.NoError(t, err)
+	_, err = NewReader(pkgPath, WithTrustedKeys([]ed25519.PublicKey{otherKey})).Read()
+	assert.EqualError(t, err, fmt.Sprintf("package %q: signed by untrusted key %s", pkgPath, KeyID(publicKey)))
+
+	// Imported packages, with their signature cached
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pcs.spkg":
+			w.Write(cnt)
+		case "/pcs.spkg" + SignatureFileSuffix:
+			w.Write(sigCnt)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	manifestPath := writeImportTestManifest(t, t.TempDir(), fmt.Sprintf("  pcs: %s/pcs.spkg", server.URL))
+	_, err = NewReader(manifestPath, WithPackageCacheDir(cacheDir), WithTrustedKeys([]ed25519.PublicKey{publicKey}), WithLockfileUpdate()).Read()
+	require.NoError(t, err)
+
+	server.Close()
+	_, err = NewReader(manifestPath, WithPackageCacheDir(cacheDir), WithTrustedKeys([]ed25519.PublicKey{publicKey})).Read()
+	require.NoError(t, err)
+	_, err = NewReader(manifestPath, WithPackageCacheDir(cacheDir), WithTrustedKeys([]ed25519.PublicKey{otherKey})).Read()
+	assert.EqualError(t, err, fmt.Sprintf("error loading imports: package %q: signed by untrusted key %s", server.URL+"/pcs.spkg", KeyID(publicKey)))
+}
@@ -93,6 +93,29 @@ func (p *JobPool) Add(ctx context.Context, reverseIdx int, job *Job, waiter Wait
 	return nil
 }
 
+// SnapshotBlocks returns, per store, the blocks jobs of the pool wait on.
+// Their subrequests start there, fetching the full snapshot of the store
+// at that block.
+func (p *JobPool) SnapshotBlocks() map[string]map[uint64]bool {
+	p.waitersMutex.RLock()
+	defer p.waitersMutex.RUnlock()
+
+	out := map[string]map[uint64]bool{}
+	for _, jw := range p.jobWaiters {
+		blockWaiter, ok := jw.Waiter.(*BlockWaiter)
+		if !ok {
+			continue
+		}
+		for _, item := range blockWaiter.items {
+			if out[item.StoreName] == nil {
+				out[item.StoreName] = map[uint64]bool{}
+			}
+			out[item.StoreName][item.BlockNum] = true
+		}
+	}
+	return out
+}
+
 func (p *JobPool) Start(ctx context.Context) {
 	zlog.Debug("starting job pool")
 	p.start.Do(func() {
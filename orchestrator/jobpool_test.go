@@ -55,6 +55,22 @@ func TestNotify(t *testing.T) {
 	require.Equal(t, 2, *signalCounter)
 }
 
+func TestJobPool_SnapshotBlocks(t *testing.T) {
+	p := NewJobPool()
+	ctx := context.Background()
+
+	storeA := &pbsubstreams.Module{Name: "A", InitialBlock: 0}
+	storeB := &pbsubstreams.Module{Name: "B", InitialBlock: 20_000}
+	_ = p.Add(ctx, 0, &Job{}, NewWaiter("C", 10_000, storeA, storeB))
+	_ = p.Add(ctx, 0, &Job{}, NewWaiter("C", 30_000, storeA, storeB))
+	_ = p.Add(ctx, 0, &Job{}, NewTestWaiter(new(int)))
+
+	require.Equal(t, map[string]map[uint64]bool{
+		"A": {10_000: true, 30_000: true},
+		"B": {30_000: true},
+	}, p.SnapshotBlocks())
+}
+
 func TestGetOrdered(t *testing.T) {
 	p := NewJobPool()
 	ctx := context.Background()
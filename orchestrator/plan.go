@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/streamingfast/substreams/block"
+	"github.com/streamingfast/substreams/state"
+)
+
+// BuildWorkPlan splits the work needed to bring each of the `stores`
+// up to `upToBlock`, given the snapshots found in `storageState`.
+// Stores that have nothing to process are not part of the returned
+// plan.
+func BuildWorkPlan(stores map[string]*state.Store, storageState *StorageState, upToBlock uint64) WorkPlan {
+	workPlan := WorkPlan{}
+	for storeName, store := range stores {
+		snapshots := storageState.Snapshots[storeName]
+		if workUnit := SplitWork(storeName, store.SaveInterval, store.ModuleInitialBlock, upToBlock, snapshots); workUnit != nil {
+			workPlan[storeName] = workUnit
+		}
+	}
+	return workPlan
+}
+
+// Plan is a dry-run view of the back-processing that a request
+// starting at `UpToBlock` would trigger. Nothing is executed to
+// produce it.
+type Plan struct {
+	UpToBlock           uint64       `json:"up_to_block"`
+	SubrequestSplitSize uint64       `json:"subrequest_split_size"`
+	Stores              []*StorePlan `json:"stores"`
+}
+
+type StorePlan struct {
+	ModuleName         string `json:"module_name"`
+	ModuleHash         string `json:"module_hash"`
+	ModuleInitialBlock uint64 `json:"module_initial_block"`
+	SaveInterval       uint64 `json:"save_interval"`
+
+	CompleteSnapshots block.Ranges `json:"complete_snapshots"`
+	PartialSnapshots  block.Ranges `json:"partial_snapshots"`
+
+	InitialStoreFile *block.Range `json:"initial_store_file,omitempty"` // complete .kv file loaded before squashing
+	PartialsPresent  block.Ranges `json:"partials_present"`             // partials already on storage, squashed without any job
+	PartialsMissing  block.Ranges `json:"partials_missing"`             // partials that jobs need to produce
+	Jobs             block.Ranges `json:"jobs"`                         // the subrequests ranges, at the configured split size
+
+	SquashOperations int `json:"squash_operations"` // number of partials merged into the complete store
+}
+
+func (p *Plan) JobCount() (out int) {
+	for _, store := range p.Stores {
+		out += len(store.Jobs)
+	}
+	return
+}
+
+func (p *Plan) SquashOperations() (out int) {
+	for _, store := range p.Stores {
+		out += store.SquashOperations
+	}
+	return
+}
+
+// DryRun fetches the storage state of `stores` and plans the work
+// needed to reach `upToBlock`, without scheduling anything.
+func DryRun(ctx context.Context, stores map[string]*state.Store, subrequestSplitSize, upToBlock uint64) (*Plan, error) {
+	storageState, err := FetchStorageState(ctx, stores)
+	if err != nil {
+		return nil, fmt.Errorf("fetching stores states: %w", err)
+	}
+
+	return NewPlan(stores, storageState, subrequestSplitSize, upToBlock), nil
+}
+
+func NewPlan(stores map[string]*state.Store, storageState *StorageState, subrequestSplitSize, upToBlock uint64) *Plan {
+	workPlan := BuildWorkPlan(stores, storageState, upToBlock)
+
+	plan := &Plan{
+		UpToBlock:           upToBlock,
+		SubrequestSplitSize: subrequestSplitSize,
+	}
+
+	for storeName, store := range stores {
+		storePlan := &StorePlan{
+			ModuleName:         storeName,
+			ModuleHash:         store.ModuleHash,
+			ModuleInitialBlock: store.ModuleInitialBlock,
+			SaveInterval:       store.SaveInterval,
+		}
+
+		if snapshots := storageState.Snapshots[storeName]; snapshots != nil {
+			storePlan.CompleteSnapshots = snapshots.Completes
+			storePlan.PartialSnapshots = snapshots.Partials
+		}
+
+		if workUnit := workPlan[storeName]; workUnit != nil {
+			storePlan.InitialStoreFile = workUnit.initialStoreFile
+			storePlan.PartialsPresent = workUnit.partialsPresent
+			storePlan.PartialsMissing = workUnit.partialsMissing
+			storePlan.Jobs = workUnit.batchRequests(subrequestSplitSize)
+			storePlan.SquashOperations = len(workUnit.partialsPresent) + len(workUnit.partialsMissing)
+		}
+
+		plan.Stores = append(plan.Stores, storePlan)
+	}
+
+	sort.Slice(plan.Stores, func(i, j int) bool {
+		return plan.Stores[i].ModuleName < plan.Stores[j].ModuleName
+	})
+
+	return plan
+}
@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/streamingfast/substreams/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPlan(t *testing.T) {
+	stores := map[string]*state.Store{
+		"pairs":    {Name: "pairs", ModuleHash: "abc", SaveInterval: 10, ModuleInitialBlock: 50},
+		"reserves": {Name: "reserves", ModuleHash: "def", SaveInterval: 10, ModuleInitialBlock: 50},
+		"prices":   {Name: "prices", ModuleHash: "ghi", SaveInterval: 10, ModuleInitialBlock: 90},
+	}
+	storageState := &StorageState{Snapshots: map[string]*Snapshots{
+		"pairs":    parseSnapshotSpec("50-60,p70-80"),
+		"reserves": parseSnapshotSpec(""),
+		"prices":   parseSnapshotSpec("90-100"),
+	}}
+
+	plan := NewPlan(stores, storageState, 20, 100)
+
+	require.Len(t, plan.Stores, 3)
+	assert.Equal(t, uint64(100), plan.UpToBlock)
+
+	pairs := plan.Stores[0]
+	assert.Equal(t, "pairs", pairs.ModuleName)
+	assert.Equal(t, parseRange("50-60"), pairs.InitialStoreFile)
+	assert.Equal(t, "[50, 60)", pairs.CompleteSnapshots.String())
+	assert.Equal(t, "[70, 80)", pairs.PartialSnapshots.String())
+	assert.Equal(t, parseRanges("70-80").String(), pairs.PartialsPresent.String())
+	assert.Equal(t, parseRanges("60-70,80-90,90-100").String(), pairs.PartialsMissing.String())
+	assert.Equal(t, parseRanges("60-70,80-100").String(), pairs.Jobs.String())
+	assert.Equal(t, 4, pairs.SquashOperations)
+
+	prices := plan.Stores[1]
+	assert.Equal(t, "prices", prices.ModuleName)
+	assert.Nil(t, prices.InitialStoreFile)
+	assert.Len(t, prices.Jobs, 0)
+	assert.Equal(t, 0, prices.SquashOperations)
+
+	reserves := plan.Stores[2]
+	assert.Equal(t, "reserves", reserves.ModuleName)
+	assert.Equal(t, parseRanges("50-60,60-70,70-80,80-90,90-100").String(), reserves.PartialsMissing.String())
+	assert.Equal(t, parseRanges("50-70,70-90,90-100").String(), reserves.Jobs.String())
+	assert.Equal(t, 5, reserves.SquashOperations)
+
+	assert.Equal(t, 5, plan.JobCount())
+	assert.Equal(t, 9, plan.SquashOperations())
+}
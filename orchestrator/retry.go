@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorClass tells how a job should react to a worker error.
+type ErrorClass int
+
+const (
+	// ErrorClassRetryable is for transport errors, or anything that
+	// could succeed on a second attempt.
+	ErrorClassRetryable ErrorClass = iota
+	// ErrorClassDeterministic is for module failures, which will fail
+	// the same way every time the job is run.
+	ErrorClassDeterministic
+	// ErrorClassCanceled is for jobs canceled by the caller.
+	ErrorClassCanceled
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassRetryable:
+		return "retryable"
+	case ErrorClassDeterministic:
+		return "deterministic"
+	case ErrorClassCanceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// ModuleFailedError is returned by a Worker when its subrequest reported
+// a module execution failure, before terminating in error.
+type ModuleFailedError struct {
+	ModuleName string
+	Failure    *pbsubstreams.ModuleProgress_Failed
+
+	inner error
+}
+
+func (e *ModuleFailedError) Error() string {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("module %q failed", e.ModuleName))
+	if e.Failure.Reason != "" {
+		s.WriteString(": " + e.Failure.Reason)
+	}
+	if len(e.Failure.Logs) != 0 {
+		s.WriteString(", logs: " + strings.Join(pbsubstreams.FormatLogs(e.Failure.Logs), "; "))
+		if e.Failure.LogsTruncated {
+			s.WriteString(" <logs truncated>")
+		}
+	}
+	return s.String()
+}
+
+func (e *ModuleFailedError) Unwrap() error { return e.inner }
+
+// ClassifyError sorts worker errors between retryable, deterministic
+// and canceled ones.
+func ClassifyError(ctx context.Context, err error) ErrorClass {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+
+	var moduleFailed *ModuleFailedError
+	if errors.As(err, &moduleFailed) {
+		return ErrorClassDeterministic
+	}
+
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.Canceled:
+			return ErrorClassCanceled
+		case codes.InvalidArgument, codes.FailedPrecondition, codes.Unimplemented, codes.PermissionDenied, codes.Unauthenticated:
+			return ErrorClassDeterministic
+		}
+	}
+
+	return ErrorClassRetryable
+}
+
+// RetryPolicy applies an exponential backoff between the attempts of a
+// job, for retryable errors only.
+type RetryPolicy struct {
+	MaxAttempts    int // including the first one
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+}
+
+// Backoff returns the delay to wait before retrying, after `attempt`
+// failed attempts.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= p.Multiplier
+		if p.MaxBackoff != 0 && backoff >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(backoff)
+}
+
+func (p RetryPolicy) Run(ctx context.Context, f func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+
+		class := ClassifyError(ctx, err)
+		if class != ErrorClassRetryable || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		backoff := p.Backoff(attempt)
+		zlog.Info("retrying job", zap.Error(err), zap.Int("attempt", attempt), zap.Duration("backoff", backoff))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
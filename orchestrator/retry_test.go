@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyError(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	moduleFailed := &ModuleFailedError{
+		ModuleName: "map_pairs",
+		Failure:    &pbsubstreams.ModuleProgress_Failed{Reason: "panic in wasm"},
+		inner:      status.Error(codes.Unknown, "unexpected termination"),
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		err    error
+		expect ErrorClass
+	}{
+		{"transport", context.Background(), fmt.Errorf("receiving stream resp: %w", status.Error(codes.Unavailable, "connection reset")), ErrorClassRetryable},
+		{"io error", context.Background(), io.ErrUnexpectedEOF, ErrorClassRetryable},
+		{"module failure", context.Background(), fmt.Errorf("wrapped: %w", moduleFailed), ErrorClassDeterministic},
+		{"invalid argument", context.Background(), fmt.Errorf("getting block stream: %w", status.Error(codes.InvalidArgument, "bad modules")), ErrorClassDeterministic},
+		{"canceled status", context.Background(), status.Error(codes.Canceled, "source canceled"), ErrorClassCanceled},
+		{"canceled context", canceledCtx, status.Error(codes.Unavailable, "connection reset"), ErrorClassCanceled},
+		{"context canceled error", context.Background(), fmt.Errorf("sending progress: %w", context.Canceled), ErrorClassCanceled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, ClassifyError(test.ctx, test.err))
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 3}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 900*time.Millisecond, policy.Backoff(3))
+	assert.Equal(t, time.Second, policy.Backoff(4))
+}
+
+func TestRetryPolicy_Run(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+
+	attempts := 0
+	err := policy.Run(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return status.Error(codes.Unavailable, "connection reset")
+	})
+	require.Error(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = policy.Run(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts < 2 {
+			return status.Error(codes.Unavailable, "connection reset")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	attempts = 0
+	err = policy.Run(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return &ModuleFailedError{ModuleName: "map_pairs", Failure: &pbsubstreams.ModuleProgress_Failed{Reason: "panic", Logs: []*pbsubstreams.LogEntry{
+			{Level: pbsubstreams.LogLevel_LOG_LEVEL_ERROR, Message: "hello", Fields: []*pbsubstreams.LogField{{Key: "pair", Value: "0xabc"}}},
+		}}}
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts, "deterministic failures are not retried")
+	assert.Equal(t, `module "map_pairs" failed: panic, logs: ERROR hello pair=0xabc`, err.Error())
+}
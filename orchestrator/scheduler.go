@@ -6,26 +6,45 @@ import (
 	"time"
 
 	"github.com/streamingfast/substreams/block"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 
-	"github.com/streamingfast/derr"
 	"github.com/streamingfast/substreams"
 	"go.uber.org/zap"
 )
 
+const throughputEmitInterval = time.Second
+
 type Scheduler struct {
 	workerPool *WorkerPool
 	respFunc   substreams.ResponseFunc
 
 	squasher       *Squasher
 	requestsStream <-chan *Job
+
+	throughput map[string]*substreams.ThroughputTracker // per store module
+
+	retryPolicy RetryPolicy
+}
+
+type SchedulerOption func(s *Scheduler)
+
+func WithRetryPolicy(policy RetryPolicy) SchedulerOption {
+	return func(s *Scheduler) {
+		s.retryPolicy = policy
+	}
 }
 
-func NewScheduler(ctx context.Context, strategy *OrderedStrategy, squasher *Squasher, workerPool *WorkerPool, respFunc substreams.ResponseFunc) (*Scheduler, error) {
+func NewScheduler(ctx context.Context, strategy *OrderedStrategy, squasher *Squasher, workerPool *WorkerPool, workPlan WorkPlan, respFunc substreams.ResponseFunc, opts ...SchedulerOption) (*Scheduler, error) {
 	s := &Scheduler{
 		squasher:       squasher,
 		requestsStream: strategy.getRequestStream(ctx),
 		workerPool:     workerPool,
 		respFunc:       respFunc,
+		throughput:     workPlan.ThroughputTrackers(),
+		retryPolicy:    DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 	return s, nil
 }
@@ -80,9 +99,10 @@ func (s *Scheduler) Launch(ctx context.Context, result chan error) {
 
 func (s *Scheduler) runSingleJob(ctx context.Context, jobWorker *Worker, job *Job) error {
 	var partialsWritten []*block.Range
-	err := derr.RetryContext(ctx, 3, func(ctx context.Context) error {
+	respFunc := s.trackProgress(job)
+	err := s.retryPolicy.Run(ctx, func(ctx context.Context) error {
 		var err error
-		partialsWritten, err = jobWorker.Run(ctx, job, s.respFunc)
+		partialsWritten, err = jobWorker.Run(ctx, job, respFunc)
 		if err != nil {
 			return err
 		}
@@ -90,7 +110,7 @@ func (s *Scheduler) runSingleJob(ctx context.Context, jobWorker *Worker, job *Jo
 	})
 	s.workerPool.ReturnWorker(jobWorker)
 	if err != nil {
-		return err
+		return fmt.Errorf("%s %s error: %w", job, ClassifyError(ctx, err), err)
 	}
 
 	if err = s.Callback(ctx, job, partialsWritten); err != nil {
@@ -99,3 +119,66 @@ func (s *Scheduler) runSingleJob(ctx context.Context, jobWorker *Worker, job *Jo
 	return nil
 
 }
+
+// trackProgress wraps the response func, to measure the throughput of
+// the job's module from the progress messages of its subrequest. A
+// throughput message is sent back, at most once per second per module.
+// The throughput messages of the subrequest itself only cover its job,
+// they are dropped so the client only gets the aggregate one.
+func (s *Scheduler) trackProgress(job *Job) substreams.ResponseFunc {
+	tracker := s.throughput[job.moduleName]
+
+	var jobProcessedBlocks uint64 // survives retries, so blocks are counted only once
+	return func(resp *pbsubstreams.Response) error {
+		progress, ok := resp.Message.(*pbsubstreams.Response_Progress)
+		if !ok {
+			return s.respFunc(resp)
+		}
+
+		if modules := withoutThroughput(progress.Progress.Modules); len(modules) != 0 {
+			if err := s.respFunc(substreams.NewModulesProgressResponse(modules)); err != nil {
+				return err
+			}
+		}
+
+		if tracker == nil {
+			return nil
+		}
+
+		for _, module := range progress.Progress.Modules {
+			if module.Name != job.moduleName {
+				continue
+			}
+			processedRanges, ok := module.Type.(*pbsubstreams.ModuleProgress_ProcessedRanges)
+			if !ok {
+				continue
+			}
+			for _, rng := range processedRanges.ProcessedRanges.ProcessedRanges {
+				if rng.EndBlock <= job.requestRange.StartBlock {
+					continue
+				}
+				processed := rng.EndBlock - job.requestRange.StartBlock
+				if processed > jobProcessedBlocks {
+					tracker.Add(processed - jobProcessedBlocks)
+					jobProcessedBlocks = processed
+				}
+			}
+		}
+
+		if !tracker.ShouldEmit(throughputEmitInterval) {
+			return nil
+		}
+		return s.respFunc(substreams.NewModulesProgressResponse([]*pbsubstreams.ModuleProgress{tracker.ProgressMessage(job.moduleName)}))
+	}
+}
+
+func withoutThroughput(modules []*pbsubstreams.ModuleProgress) []*pbsubstreams.ModuleProgress {
+	out := make([]*pbsubstreams.ModuleProgress, 0, len(modules))
+	for _, module := range modules {
+		if _, ok := module.Type.(*pbsubstreams.ModuleProgress_Throughput_); ok {
+			continue
+		}
+		out = append(out, module)
+	}
+	return out
+}
@@ -1 +1,43 @@
 package orchestrator
+
+import (
+	"testing"
+
+	"github.com/streamingfast/substreams"
+	"github.com/streamingfast/substreams/block"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_TrackProgressDropsSubrequestThroughput(t *testing.T) {
+	var sent []*pbsubstreams.Response
+	s := &Scheduler{
+		respFunc: func(resp *pbsubstreams.Response) error {
+			sent = append(sent, resp)
+			return nil
+		},
+		throughput: map[string]*substreams.ThroughputTracker{"A": substreams.NewThroughputTracker(100)},
+	}
+	respFunc := s.trackProgress(&Job{moduleName: "A", requestRange: block.NewRange(0, 100)})
+
+	processed := &pbsubstreams.ModuleProgress{
+		Name: "A",
+		Type: &pbsubstreams.ModuleProgress_ProcessedRanges{ProcessedRanges: &pbsubstreams.ModuleProgress_ProcessedRange{
+			ProcessedRanges: []*pbsubstreams.BlockRange{{StartBlock: 0, EndBlock: 10}},
+		}},
+	}
+	subrequestThroughput := substreams.NewThroughputTracker(10).ProgressMessage("A")
+
+	require.NoError(t, respFunc(substreams.NewModulesProgressResponse([]*pbsubstreams.ModuleProgress{subrequestThroughput})))
+	require.NoError(t, respFunc(substreams.NewModulesProgressResponse([]*pbsubstreams.ModuleProgress{processed, subrequestThroughput})))
+
+	require.Len(t, sent, 2)
+
+	// The aggregate throughput of the scheduler, emitted at most once per second
+	aggregate := sent[0].GetProgress().Modules
+	require.Len(t, aggregate, 1)
+	assert.Equal(t, uint64(100), aggregate[0].GetThroughput().TotalBlocks)
+
+	assert.Equal(t, []*pbsubstreams.ModuleProgress{processed}, sent[1].GetProgress().Modules)
+}
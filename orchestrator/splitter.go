@@ -3,22 +3,35 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"sync"
+
 	"go.uber.org/zap"
 
+	"github.com/streamingfast/substreams"
 	"github.com/streamingfast/substreams/block"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 )
 
 type WorkPlan map[string]*WorkUnit
 
+// SquashPartialsPresent squashes the partials already in storage, for
+// all stores in parallel.
 func (p WorkPlan) SquashPartialsPresent(ctx context.Context, squasher *Squasher) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, len(p))
 	for _, w := range p {
-		err := squasher.Squash(ctx, w.modName, w.partialsPresent)
-		if err != nil {
-			return fmt.Errorf("squash partials present for module %s: %w", w.modName, err)
-		}
+		wg.Add(1)
+		go func(w *WorkUnit) {
+			defer wg.Done()
+			if err := squasher.Squash(ctx, w.modName, w.partialsPresent); err != nil {
+				errs <- fmt.Errorf("squash partials present for module %s: %w", w.modName, err)
+			}
+		}(w)
 	}
-	return nil
+	wg.Wait()
+	close(errs)
+
+	return <-errs
 }
 
 func (p WorkPlan) ProgressMessages() (out []*pbsubstreams.ModuleProgress) {
@@ -54,6 +67,20 @@ func (p WorkPlan) ProgressMessages() (out []*pbsubstreams.ModuleProgress) {
 	return
 }
 
+// ThroughputTrackers returns a tracker per store module, sized for
+// the blocks that are missing from its partials.
+func (p WorkPlan) ThroughputTrackers() map[string]*substreams.ThroughputTracker {
+	out := map[string]*substreams.ThroughputTracker{}
+	for storeName, unit := range p {
+		var totalBlocks uint64
+		for _, rng := range unit.partialsMissing {
+			totalBlocks += rng.Size()
+		}
+		out[storeName] = substreams.NewThroughputTracker(totalBlocks)
+	}
+	return out
+}
+
 type WorkUnit struct {
 	modName string
 
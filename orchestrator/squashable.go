@@ -22,6 +22,10 @@ type Squashable struct {
 	targetExclusiveBlock   uint64
 	nextExpectedStartBlock uint64
 
+	checkpointInterval uint64          // 0 means every store save interval
+	snapshotBlocks     map[uint64]bool // where jobs waiting on the store fetch it, written even between checkpoints
+	mergedPartials     block.Ranges    // merged, but not yet covered by a written checkpoint
+
 	notifier Notifier
 
 	targetReached bool
@@ -58,35 +62,31 @@ func (s *Squashable) squash(ctx context.Context, partialsChunks block.Ranges) er
 func (s *Squashable) mergeAvailablePartials(ctx context.Context) error {
 	zlog.Info("squashing", zap.String("module_name", s.store.Name))
 
-	for {
+	mergeable, err := s.contiguousRanges()
+	if err != nil {
+		return err
+	}
+	if len(mergeable) == 0 {
+		return nil
+	}
+
+	// Load the next partial while the current one is being merged.
+	next := s.loadPartial(ctx, mergeable[0])
+	for idx, squashableRange := range mergeable {
+		var loaded partialLoad
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		default:
-		}
-
-		if len(s.ranges) == 0 {
-			break
-		}
-
-		// TODO(abourget): we still need to keep track of which ranges were completed in order
-		squashableRange := s.ranges[0]
-		zlog.Info("testing first range", zap.String("module_name", s.store.Name), zap.Object("range", squashableRange), zap.Uint64("next_expected_start_block", s.nextExpectedStartBlock))
-
-		if squashableRange.StartBlock < s.nextExpectedStartBlock {
-			return fmt.Errorf("module %q: non contiguous ranges were added to the store squasher, expected %d, got %d, ranges: %s", s.name, s.nextExpectedStartBlock, squashableRange.StartBlock, s.ranges)
+		case loaded = <-next:
 		}
-		if s.nextExpectedStartBlock != squashableRange.StartBlock {
-			break
+		if loaded.err != nil {
+			return fmt.Errorf("initializing next partial store %q: %w", s.name, loaded.err)
 		}
-
-		zlog.Debug("found range to merge", zap.Stringer("squashable", s), zap.Stringer("squashable_range", squashableRange))
-
-		nextStore, err := s.store.LoadFrom(ctx, block.NewRange(squashableRange.StartBlock, squashableRange.ExclusiveEndBlock))
-		if err != nil {
-			return fmt.Errorf("initializing next partial store %q: %w", s.name, err)
+		if idx+1 < len(mergeable) {
+			next = s.loadPartial(ctx, mergeable[idx+1])
 		}
 
+		nextStore := loaded.store
 		zlog.Debug("next store loaded", zap.Object("store", nextStore))
 
 		err = s.store.Merge(nextStore)
@@ -97,32 +97,95 @@ func (s *Squashable) mergeAvailablePartials(ctx context.Context) error {
 		zlog.Debug("store merge", zap.Object("store", s.store))
 
 		s.nextExpectedStartBlock = squashableRange.ExclusiveEndBlock
+		s.ranges = s.ranges[1:]
+		s.mergedPartials = append(s.mergedPartials, squashableRange)
 
-		zlog.Info("deleting temp store", zap.Object("store", nextStore))
-		err = nextStore.DeleteStore(ctx, squashableRange.ExclusiveEndBlock)
-		if err != nil {
-			zlog.Warn("deleting partial file", zap.Error(err))
+		if squashableRange.ExclusiveEndBlock == s.targetExclusiveBlock {
+			s.targetReached = true
 		}
 
-		if squashableRange.ExclusiveEndBlock%nextStore.SaveInterval == 0 {
-			err = s.store.WriteState(ctx, squashableRange.ExclusiveEndBlock)
-			if err != nil {
-				return fmt.Errorf("writing state: %w", err)
-			}
+		if !s.isCheckpoint(squashableRange.ExclusiveEndBlock) && !s.targetReached {
+			continue
 		}
 
-		s.ranges = s.ranges[1:]
-
-		if squashableRange.ExclusiveEndBlock == s.targetExclusiveBlock {
-			s.targetReached = true
+		err = s.store.WriteState(ctx, squashableRange.ExclusiveEndBlock)
+		if err != nil {
+			return fmt.Errorf("writing state: %w", err)
 		}
+		s.deleteMergedPartials(ctx)
 
+		// Only once written, the jobs waiting on it fetch the state
 		s.notifyWaiters(squashableRange.ExclusiveEndBlock)
 	}
 
 	return nil
 }
 
+// contiguousRanges returns the ranges that can be merged right away,
+// meaning those following `nextExpectedStartBlock` without gaps.
+func (s *Squashable) contiguousRanges() (out block.Ranges, err error) {
+	nextStartBlock := s.nextExpectedStartBlock
+	for _, squashableRange := range s.ranges {
+		zlog.Info("testing range", zap.String("module_name", s.store.Name), zap.Object("range", squashableRange), zap.Uint64("next_expected_start_block", nextStartBlock))
+
+		if squashableRange.StartBlock < nextStartBlock {
+			return nil, fmt.Errorf("module %q: non contiguous ranges were added to the store squasher, expected %d, got %d, ranges: %s", s.name, nextStartBlock, squashableRange.StartBlock, s.ranges)
+		}
+		if squashableRange.StartBlock != nextStartBlock {
+			break
+		}
+
+		zlog.Debug("found range to merge", zap.Stringer("squashable", s), zap.Stringer("squashable_range", squashableRange))
+		out = append(out, squashableRange)
+		nextStartBlock = squashableRange.ExclusiveEndBlock
+	}
+	return out, nil
+}
+
+type partialLoad struct {
+	store *state.Store
+	err   error
+}
+
+func (s *Squashable) loadPartial(ctx context.Context, partialRange *block.Range) <-chan partialLoad {
+	out := make(chan partialLoad, 1)
+	go func() {
+		store, err := s.store.LoadFrom(ctx, block.NewRange(partialRange.StartBlock, partialRange.ExclusiveEndBlock))
+		out <- partialLoad{store: store, err: err}
+	}()
+	return out
+}
+
+// isCheckpoint tells whether the full store should be written at
+// `blockNum`, either on the checkpoint interval or because a job waits on
+// it there. Partials merged since the last checkpoint are only deleted
+// once it is written.
+func (s *Squashable) isCheckpoint(blockNum uint64) bool {
+	if s.snapshotBlocks[blockNum] {
+		return true
+	}
+	interval := s.checkpointInterval
+	if interval == 0 {
+		interval = s.store.SaveInterval
+	}
+	return blockNum%interval == 0
+}
+
+func (s *Squashable) deleteMergedPartials(ctx context.Context) {
+	for _, partialRange := range s.mergedPartials {
+		partialStore := s.store.CloneStructure(partialRange.StartBlock)
+		if !partialStore.IsPartial() {
+			// Starts at the module's initial block, so it is the complete snapshot itself
+			continue
+		}
+		zlog.Info("deleting temp store", zap.Object("store", partialStore), zap.Stringer("range", partialRange))
+		if err := partialStore.DeleteStore(ctx, partialRange.ExclusiveEndBlock); err != nil {
+			zlog.Warn("deleting partial file", zap.Error(err))
+		}
+	}
+	s.mergedPartials = nil
+}
+
 func (s *Squashable) notifyWaiters(lastSquashedBlock uint64) {
 	if s.notifier != nil {
 		s.notifier.Notify(s.store.Name, lastSquashedBlock)
@@ -14,10 +14,33 @@ type Squasher struct {
 	squashables          map[string]*Squashable
 	storeSaveInterval    uint64
 	targetExclusiveBlock uint64
+	checkpointInterval   uint64
+	snapshotBlocks       map[string]map[uint64]bool
 
 	notifier Notifier
 }
 
+type SquasherOption func(s *Squasher)
+
+// WithStoreCheckpointInterval makes the squasher write full `.kv`
+// snapshots only every `blocks` blocks, instead of at every store save
+// interval. It should be a multiple of the stores save interval.
+func WithStoreCheckpointInterval(blocks uint64) SquasherOption {
+	return func(s *Squasher) {
+		s.checkpointInterval = blocks
+	}
+}
+
+// WithStoreSnapshotBlocks makes the squasher write full `.kv` snapshots
+// of each store at the given blocks, whatever the checkpoint interval.
+// These are the blocks jobs depending on the stores start at, see
+// `JobPool.SnapshotBlocks()`.
+func WithStoreSnapshotBlocks(blocks map[string]map[uint64]bool) SquasherOption {
+	return func(s *Squasher) {
+		s.snapshotBlocks = blocks
+	}
+}
+
 // NewSquasher receives stores, initializes them and fetches them from
 // the existing storage. It prepares itself to receive Squash()
 // requests that should correspond to what is missing for those stores
@@ -26,8 +49,16 @@ type Squasher struct {
 // synchronizes around the actual data: the state of storages
 // present, the requests needed to fill in those stores up to the
 // target block, etc..
-func NewSquasher(ctx context.Context, workPlan WorkPlan, stores map[string]*state.Store, reqStartBlock uint64, notifier Notifier) (*Squasher, error) {
-	squashables := map[string]*Squashable{}
+func NewSquasher(ctx context.Context, workPlan WorkPlan, stores map[string]*state.Store, reqStartBlock uint64, notifier Notifier, opts ...SquasherOption) (*Squasher, error) {
+	squasher := &Squasher{
+		squashables:          map[string]*Squashable{},
+		targetExclusiveBlock: reqStartBlock,
+		notifier:             notifier,
+	}
+	for _, opt := range opts {
+		opt(squasher)
+	}
+
 	for modName, workUnit := range workPlan {
 		store := stores[modName]
 		var squashable *Squashable
@@ -40,24 +71,23 @@ func NewSquasher(ctx context.Context, workPlan WorkPlan, stores map[string]*stat
 			}
 			squashable = NewSquashable(squish, reqStartBlock, workUnit.initialStoreFile.ExclusiveEndBlock, notifier)
 		}
+		squashable.checkpointInterval = squasher.checkpointInterval
+		squashable.snapshotBlocks = squasher.snapshotBlocks[modName]
 
 		if len(workUnit.partialsMissing) == 0 {
 			squashable.targetReached = true
 			squashable.notifyWaiters(reqStartBlock)
 		}
 
-		squashables[store.Name] = squashable
-	}
-
-	squasher := &Squasher{
-		squashables:          squashables,
-		targetExclusiveBlock: reqStartBlock,
-		notifier:             notifier,
+		squasher.squashables[store.Name] = squashable
 	}
 
 	return squasher, nil
 }
 
+// Squash merges the given partials into the module's store. Each store
+// serializes its own merges, so calls for different modules can run
+// concurrently.
 func (s *Squasher) Squash(ctx context.Context, moduleName string, partialsRanges block.Ranges) error {
 	squashable, ok := s.squashables[moduleName]
 	if !ok {
@@ -86,6 +86,84 @@ func TestSquash(t *testing.T) {
 	require.Equal(t, 2, notificationsSent)
 }
 
+// newCheckpointTestStore serves the partials of the checkpoint tests,
+// and records the files written and deleted.
+func newCheckpointTestStore() (store *dstore.MockStore, written, deleted *[]string) {
+	written, deleted = &[]string{}, &[]string{}
+	store = dstore.NewMockStore(nil)
+	store.WriteObjectFunc = func(ctx context.Context, base string, f io.Reader) error {
+		if base != state.InfoFileName() {
+			*written = append(*written, base)
+		}
+		return nil
+	}
+	store.DeleteObjectFunc = func(ctx context.Context, base string) error {
+		*deleted = append(*deleted, base)
+		return nil
+	}
+	store.OpenObjectFunc = func(ctx context.Context, name string) (out io.ReadCloser, err error) {
+		switch name {
+		case "0000020000-0000010000.kv", "0000030000-0000020000.partial", "0000040000-0000030000.partial", "0000045000-0000040000.partial":
+			return io.NopCloser(bytes.NewReader([]byte("{}"))), nil
+		}
+		return nil, dstore.ErrNotFound
+	}
+	return store, written, deleted
+}
+
+type notifications []uint64
+
+func (n *notifications) Notify(builder string, blockNum uint64) {
+	*n = append(*n, blockNum)
+}
+
+func TestSquash_CheckpointInterval(t *testing.T) {
+	ctx := context.Background()
+	store, written, deleted := newCheckpointTestStore()
+
+	notified := &notifications{}
+	squashable := NewSquashable(testStateBuilder(store), 40_000, 10_000, notified)
+	squashable.checkpointInterval = 20_000
+
+	require.NoError(t, squashable.squash(ctx, []*block.Range{{10_000, 20_000}, {20_000, 30_000}}))
+	require.Equal(t, []string{"0000020000-0000010000.kv"}, *written)
+	require.Len(t, *deleted, 0, "partials past the last checkpoint are kept")
+	require.Equal(t, notifications{20_000}, *notified, "waiters are only notified of written states")
+
+	require.NoError(t, squashable.squash(ctx, []*block.Range{{30_000, 40_000}}))
+	require.Equal(t, []string{"0000020000-0000010000.kv", "0000040000-0000010000.kv"}, *written)
+	require.Equal(t, []string{
+		"0000030000-0000020000.partial", "__0000030000-0000020000.partial.info.json",
+		"0000040000-0000030000.partial", "__0000040000-0000030000.partial.info.json",
+	}, *deleted)
+	require.Equal(t, notifications{20_000, 40_000}, *notified)
+	require.True(t, squashable.targetReached)
+}
+
+func TestSquash_CheckpointIntervalUnalignedTarget(t *testing.T) {
+	ctx := context.Background()
+	store, written, deleted := newCheckpointTestStore()
+
+	notified := &notifications{}
+	squashable := NewSquashable(testStateBuilder(store), 45_000, 20_000, notified)
+	squashable.checkpointInterval = 40_000
+	squashable.snapshotBlocks = map[uint64]bool{30_000: true} // a job waits on it
+
+	require.NoError(t, squashable.squash(ctx, []*block.Range{{20_000, 30_000}, {30_000, 40_000}}))
+	require.Equal(t, []string{"0000030000-0000010000.kv", "0000040000-0000010000.kv"}, *written)
+	require.Equal(t, notifications{30_000, 40_000}, *notified)
+
+	require.NoError(t, squashable.squash(ctx, []*block.Range{{40_000, 45_000}}))
+	require.Equal(t, []string{"0000030000-0000010000.kv", "0000040000-0000010000.kv", "0000045000-0000010000.kv"}, *written, "the state is written at the target")
+	require.Equal(t, []string{
+		"0000030000-0000020000.partial", "__0000030000-0000020000.partial.info.json",
+		"0000040000-0000030000.partial", "__0000040000-0000030000.partial.info.json",
+		"0000045000-0000040000.partial", "__0000045000-0000040000.partial.info.json",
+	}, *deleted)
+	require.Equal(t, notifications{30_000, 40_000, 45_000}, *notified)
+	require.True(t, squashable.targetReached)
+}
+
 func testStateBuilder(store dstore.Store) *state.Store {
 	s, _ := state.NewBuilder("test", 10_000, 10_000, "abc", pbsubstreams.Module_KindStore_UPDATE_POLICY_SET, state.OutputValueTypeString, store)
 	return s
@@ -68,6 +68,7 @@ func (w *Worker) Run(ctx context.Context, job *Job, respFunc substreams.Response
 		return nil, fmt.Errorf("getting block stream: %w", err)
 	}
 
+	var moduleFailure *ModuleFailedError
 	for {
 		select {
 		case <-ctx.Done():
@@ -90,11 +91,20 @@ func (w *Worker) Run(ctx context.Context, job *Job, respFunc substreams.Response
 				return partialsWritten, nil
 			}
 			zlog.Warn("worker done on stream error", zap.Error(err))
+			if moduleFailure != nil {
+				moduleFailure.inner = err
+				return nil, moduleFailure
+			}
 			return nil, fmt.Errorf("receiving stream resp: %w", err)
 		}
 
 		switch r := resp.Message.(type) {
 		case *pbsubstreams.Response_Progress:
+			for _, module := range r.Progress.Modules {
+				if failed, ok := module.Type.(*pbsubstreams.ModuleProgress_Failed_); ok && failed.Failed.Reason != "" {
+					moduleFailure = &ModuleFailedError{ModuleName: module.Name, Failure: failed.Failed}
+				}
+			}
 			err := respFunc(resp)
 			if err != nil {
 				zlog.Warn("worker done on respFunc error", zap.Error(err))
@@ -2,6 +2,8 @@ package pbsubstreams
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/streamingfast/bstream"
 )
@@ -49,3 +51,29 @@ func ValidateRequest(req *Request) error {
 
 	return nil
 }
+
+// Format renders the entry on one line, as `LEVEL message key=value...`,
+// quoting values when needed.
+func (x *LogEntry) Format() string {
+	var s strings.Builder
+	s.WriteString(strings.TrimPrefix(x.Level.String(), "LOG_LEVEL_"))
+	s.WriteString(" ")
+	s.WriteString(x.Message)
+	for _, field := range x.Fields {
+		value := field.Value
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		s.WriteString(" " + field.Key + "=" + value)
+	}
+	return s.String()
+}
+
+// FormatLogs renders the entries with `Format`, one per line.
+func FormatLogs(entries []*LogEntry) []string {
+	out := make([]string, len(entries))
+	for i, entry := range entries {
+		out[i] = entry.Format()
+	}
+	return out
+}
@@ -77,6 +77,61 @@ func (ForkStep) EnumDescriptor() ([]byte, []int) {
 	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{0}
 }
 
+type LogLevel int32
+
+const (
+	LogLevel_LOG_LEVEL_UNSPECIFIED LogLevel = 0
+	LogLevel_LOG_LEVEL_DEBUG       LogLevel = 1
+	LogLevel_LOG_LEVEL_INFO        LogLevel = 2
+	LogLevel_LOG_LEVEL_WARN        LogLevel = 3
+	LogLevel_LOG_LEVEL_ERROR       LogLevel = 4
+)
+
+// Enum value maps for LogLevel.
+var (
+	LogLevel_name = map[int32]string{
+		0: "LOG_LEVEL_UNSPECIFIED",
+		1: "LOG_LEVEL_DEBUG",
+		2: "LOG_LEVEL_INFO",
+		3: "LOG_LEVEL_WARN",
+		4: "LOG_LEVEL_ERROR",
+	}
+	LogLevel_value = map[string]int32{
+		"LOG_LEVEL_UNSPECIFIED": 0,
+		"LOG_LEVEL_DEBUG":       1,
+		"LOG_LEVEL_INFO":        2,
+		"LOG_LEVEL_WARN":        3,
+		"LOG_LEVEL_ERROR":       4,
+	}
+)
+
+func (x LogLevel) Enum() *LogLevel {
+	p := new(LogLevel)
+	*p = x
+	return p
+}
+
+func (x LogLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_sf_substreams_v1_substreams_proto_enumTypes[1].Descriptor()
+}
+
+func (LogLevel) Type() protoreflect.EnumType {
+	return &file_sf_substreams_v1_substreams_proto_enumTypes[1]
+}
+
+func (x LogLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogLevel.Descriptor instead.
+func (LogLevel) EnumDescriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{1}
+}
+
 type StoreDelta_Operation int32
 
 const (
@@ -113,11 +168,11 @@ func (x StoreDelta_Operation) String() string {
 }
 
 func (StoreDelta_Operation) Descriptor() protoreflect.EnumDescriptor {
-	return file_sf_substreams_v1_substreams_proto_enumTypes[1].Descriptor()
+	return file_sf_substreams_v1_substreams_proto_enumTypes[2].Descriptor()
 }
 
 func (StoreDelta_Operation) Type() protoreflect.EnumType {
-	return &file_sf_substreams_v1_substreams_proto_enumTypes[1]
+	return &file_sf_substreams_v1_substreams_proto_enumTypes[2]
 }
 
 func (x StoreDelta_Operation) Number() protoreflect.EnumNumber {
@@ -126,7 +181,7 @@ func (x StoreDelta_Operation) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use StoreDelta_Operation.Descriptor instead.
 func (StoreDelta_Operation) EnumDescriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{10, 0}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{12, 0}
 }
 
 type Request struct {
@@ -142,6 +197,9 @@ type Request struct {
 	Modules                        *Modules   `protobuf:"bytes,6,opt,name=modules,proto3" json:"modules,omitempty"`
 	OutputModules                  []string   `protobuf:"bytes,7,rep,name=output_modules,json=outputModules,proto3" json:"output_modules,omitempty"`
 	InitialStoreSnapshotForModules []string   `protobuf:"bytes,8,rep,name=initial_store_snapshot_for_modules,json=initialStoreSnapshotForModules,proto3" json:"initial_store_snapshot_for_modules,omitempty"`
+	// Minimum level of the module logs to collect, all of them when
+	// unspecified.
+	MinLogLevel LogLevel `protobuf:"varint,9,opt,name=min_log_level,json=minLogLevel,proto3,enum=sf.substreams.v1.LogLevel" json:"min_log_level,omitempty"`
 }
 
 func (x *Request) Reset() {
@@ -232,6 +290,13 @@ func (x *Request) GetInitialStoreSnapshotForModules() []string {
 	return nil
 }
 
+func (x *Request) GetMinLogLevel() LogLevel {
+	if x != nil {
+		return x.MinLogLevel
+	}
+	return LogLevel_LOG_LEVEL_UNSPECIFIED
+}
+
 type Response struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -539,9 +604,9 @@ type ModuleOutput struct {
 	//	*ModuleOutput_MapOutput
 	//	*ModuleOutput_StoreDeltas
 	Data isModuleOutput_Data `protobuf_oneof:"data"`
-	Logs []string            `protobuf:"bytes,4,rep,name=logs,proto3" json:"logs,omitempty"`
+	Logs []*LogEntry         `protobuf:"bytes,6,rep,name=logs,proto3" json:"logs,omitempty"`
 	// LogsTruncated is a flag that tells you if you received all the logs or if they
-	// were truncated because you logged too much (fixed limit currently is set to 128 KiB).
+	// were truncated because you logged too much (limit set by the server, 128 KiB by default).
 	LogsTruncated bool `protobuf:"varint,5,opt,name=logs_truncated,json=logsTruncated,proto3" json:"logs_truncated,omitempty"`
 }
 
@@ -605,7 +670,7 @@ func (x *ModuleOutput) GetStoreDeltas() *StoreDeltas {
 	return nil
 }
 
-func (x *ModuleOutput) GetLogs() []string {
+func (x *ModuleOutput) GetLogs() []*LogEntry {
 	if x != nil {
 		return x.Logs
 	}
@@ -635,6 +700,126 @@ func (*ModuleOutput_MapOutput) isModuleOutput_Data() {}
 
 func (*ModuleOutput_StoreDeltas) isModuleOutput_Data() {}
 
+// LogEntry is a log emitted by a module. It is also the message modules
+// pass, encoded, to the `logger.log` host function.
+type LogEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Level   LogLevel    `protobuf:"varint,1,opt,name=level,proto3,enum=sf.substreams.v1.LogLevel" json:"level,omitempty"`
+	Message string      `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Fields  []*LogField `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LogEntry) GetLevel() LogLevel {
+	if x != nil {
+		return x.Level
+	}
+	return LogLevel_LOG_LEVEL_UNSPECIFIED
+}
+
+func (x *LogEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogEntry) GetFields() []*LogField {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type LogField struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *LogField) Reset() {
+	*x = LogField{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogField) ProtoMessage() {}
+
+func (x *LogField) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogField.ProtoReflect.Descriptor instead.
+func (*LogField) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LogField) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *LogField) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
 type ModulesProgress struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -646,7 +831,7 @@ type ModulesProgress struct {
 func (x *ModulesProgress) Reset() {
 	*x = ModulesProgress{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[6]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -659,7 +844,7 @@ func (x *ModulesProgress) String() string {
 func (*ModulesProgress) ProtoMessage() {}
 
 func (x *ModulesProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[6]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -672,7 +857,7 @@ func (x *ModulesProgress) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModulesProgress.ProtoReflect.Descriptor instead.
 func (*ModulesProgress) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{6}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ModulesProgress) GetModules() []*ModuleProgress {
@@ -693,13 +878,15 @@ type ModuleProgress struct {
 	//	*ModuleProgress_InitialState_
 	//	*ModuleProgress_ProcessedBytes_
 	//	*ModuleProgress_Failed_
+	//	*ModuleProgress_Throughput_
+	//	*ModuleProgress_ExecutionStats_
 	Type isModuleProgress_Type `protobuf_oneof:"type"`
 }
 
 func (x *ModuleProgress) Reset() {
 	*x = ModuleProgress{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[7]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -712,7 +899,7 @@ func (x *ModuleProgress) String() string {
 func (*ModuleProgress) ProtoMessage() {}
 
 func (x *ModuleProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[7]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -725,7 +912,7 @@ func (x *ModuleProgress) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleProgress.ProtoReflect.Descriptor instead.
 func (*ModuleProgress) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{7}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ModuleProgress) GetName() string {
@@ -770,6 +957,20 @@ func (x *ModuleProgress) GetFailed() *ModuleProgress_Failed {
 	return nil
 }
 
+func (x *ModuleProgress) GetThroughput() *ModuleProgress_Throughput {
+	if x, ok := x.GetType().(*ModuleProgress_Throughput_); ok {
+		return x.Throughput
+	}
+	return nil
+}
+
+func (x *ModuleProgress) GetExecutionStats() *ModuleProgress_ExecutionStats {
+	if x, ok := x.GetType().(*ModuleProgress_ExecutionStats_); ok {
+		return x.ExecutionStats
+	}
+	return nil
+}
+
 type isModuleProgress_Type interface {
 	isModuleProgress_Type()
 }
@@ -790,6 +991,14 @@ type ModuleProgress_Failed_ struct {
 	Failed *ModuleProgress_Failed `protobuf:"bytes,5,opt,name=failed,proto3,oneof"`
 }
 
+type ModuleProgress_Throughput_ struct {
+	Throughput *ModuleProgress_Throughput `protobuf:"bytes,6,opt,name=throughput,proto3,oneof"`
+}
+
+type ModuleProgress_ExecutionStats_ struct {
+	ExecutionStats *ModuleProgress_ExecutionStats `protobuf:"bytes,7,opt,name=execution_stats,json=executionStats,proto3,oneof"`
+}
+
 func (*ModuleProgress_ProcessedRanges) isModuleProgress_Type() {}
 
 func (*ModuleProgress_InitialState_) isModuleProgress_Type() {}
@@ -798,6 +1007,10 @@ func (*ModuleProgress_ProcessedBytes_) isModuleProgress_Type() {}
 
 func (*ModuleProgress_Failed_) isModuleProgress_Type() {}
 
+func (*ModuleProgress_Throughput_) isModuleProgress_Type() {}
+
+func (*ModuleProgress_ExecutionStats_) isModuleProgress_Type() {}
+
 type BlockRange struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -810,7 +1023,7 @@ type BlockRange struct {
 func (x *BlockRange) Reset() {
 	*x = BlockRange{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[8]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -823,7 +1036,7 @@ func (x *BlockRange) String() string {
 func (*BlockRange) ProtoMessage() {}
 
 func (x *BlockRange) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[8]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -836,7 +1049,7 @@ func (x *BlockRange) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlockRange.ProtoReflect.Descriptor instead.
 func (*BlockRange) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{8}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *BlockRange) GetStartBlock() uint64 {
@@ -864,7 +1077,7 @@ type StoreDeltas struct {
 func (x *StoreDeltas) Reset() {
 	*x = StoreDeltas{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[9]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -877,7 +1090,7 @@ func (x *StoreDeltas) String() string {
 func (*StoreDeltas) ProtoMessage() {}
 
 func (x *StoreDeltas) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[9]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -890,7 +1103,7 @@ func (x *StoreDeltas) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StoreDeltas.ProtoReflect.Descriptor instead.
 func (*StoreDeltas) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *StoreDeltas) GetDeltas() []*StoreDelta {
@@ -915,7 +1128,7 @@ type StoreDelta struct {
 func (x *StoreDelta) Reset() {
 	*x = StoreDelta{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[10]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -928,7 +1141,7 @@ func (x *StoreDelta) String() string {
 func (*StoreDelta) ProtoMessage() {}
 
 func (x *StoreDelta) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[10]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -941,7 +1154,7 @@ func (x *StoreDelta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StoreDelta.ProtoReflect.Descriptor instead.
 func (*StoreDelta) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{10}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *StoreDelta) GetOperation() StoreDelta_Operation {
@@ -993,7 +1206,7 @@ type Output struct {
 func (x *Output) Reset() {
 	*x = Output{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[11]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1006,7 +1219,7 @@ func (x *Output) String() string {
 func (*Output) ProtoMessage() {}
 
 func (x *Output) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[11]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1019,7 +1232,7 @@ func (x *Output) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Output.ProtoReflect.Descriptor instead.
 func (*Output) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{11}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Output) GetBlockNum() uint64 {
@@ -1061,7 +1274,7 @@ type ModuleProgress_ProcessedRange struct {
 func (x *ModuleProgress_ProcessedRange) Reset() {
 	*x = ModuleProgress_ProcessedRange{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[12]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1074,7 +1287,7 @@ func (x *ModuleProgress_ProcessedRange) String() string {
 func (*ModuleProgress_ProcessedRange) ProtoMessage() {}
 
 func (x *ModuleProgress_ProcessedRange) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[12]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1087,7 +1300,7 @@ func (x *ModuleProgress_ProcessedRange) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleProgress_ProcessedRange.ProtoReflect.Descriptor instead.
 func (*ModuleProgress_ProcessedRange) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{7, 0}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9, 0}
 }
 
 func (x *ModuleProgress_ProcessedRange) GetProcessedRanges() []*BlockRange {
@@ -1108,7 +1321,7 @@ type ModuleProgress_InitialState struct {
 func (x *ModuleProgress_InitialState) Reset() {
 	*x = ModuleProgress_InitialState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[13]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1121,7 +1334,7 @@ func (x *ModuleProgress_InitialState) String() string {
 func (*ModuleProgress_InitialState) ProtoMessage() {}
 
 func (x *ModuleProgress_InitialState) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[13]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1134,7 +1347,7 @@ func (x *ModuleProgress_InitialState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleProgress_InitialState.ProtoReflect.Descriptor instead.
 func (*ModuleProgress_InitialState) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{7, 1}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9, 1}
 }
 
 func (x *ModuleProgress_InitialState) GetAvailableUpToBlock() uint64 {
@@ -1156,7 +1369,7 @@ type ModuleProgress_ProcessedBytes struct {
 func (x *ModuleProgress_ProcessedBytes) Reset() {
 	*x = ModuleProgress_ProcessedBytes{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[14]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1169,7 +1382,7 @@ func (x *ModuleProgress_ProcessedBytes) String() string {
 func (*ModuleProgress_ProcessedBytes) ProtoMessage() {}
 
 func (x *ModuleProgress_ProcessedBytes) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[14]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1182,7 +1395,7 @@ func (x *ModuleProgress_ProcessedBytes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleProgress_ProcessedBytes.ProtoReflect.Descriptor instead.
 func (*ModuleProgress_ProcessedBytes) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{7, 2}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9, 2}
 }
 
 func (x *ModuleProgress_ProcessedBytes) GetTotalBytesRead() uint64 {
@@ -1204,17 +1417,17 @@ type ModuleProgress_Failed struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Reason string   `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
-	Logs   []string `protobuf:"bytes,2,rep,name=logs,proto3" json:"logs,omitempty"`
+	Reason string      `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Logs   []*LogEntry `protobuf:"bytes,4,rep,name=logs,proto3" json:"logs,omitempty"`
 	// FailureLogsTruncated is a flag that tells you if you received all the logs or if they
-	// were truncated because you logged too much (fixed limit currently is set to 128 KiB).
+	// were truncated because you logged too much (limit set by the server, 128 KiB by default).
 	LogsTruncated bool `protobuf:"varint,3,opt,name=logs_truncated,json=logsTruncated,proto3" json:"logs_truncated,omitempty"`
 }
 
 func (x *ModuleProgress_Failed) Reset() {
 	*x = ModuleProgress_Failed{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[15]
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1227,7 +1440,7 @@ func (x *ModuleProgress_Failed) String() string {
 func (*ModuleProgress_Failed) ProtoMessage() {}
 
 func (x *ModuleProgress_Failed) ProtoReflect() protoreflect.Message {
-	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[15]
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1240,7 +1453,7 @@ func (x *ModuleProgress_Failed) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ModuleProgress_Failed.ProtoReflect.Descriptor instead.
 func (*ModuleProgress_Failed) Descriptor() ([]byte, []int) {
-	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{7, 3}
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9, 3}
 }
 
 func (x *ModuleProgress_Failed) GetReason() string {
@@ -1250,7 +1463,7 @@ func (x *ModuleProgress_Failed) GetReason() string {
 	return ""
 }
 
-func (x *ModuleProgress_Failed) GetLogs() []string {
+func (x *ModuleProgress_Failed) GetLogs() []*LogEntry {
 	if x != nil {
 		return x.Logs
 	}
@@ -1264,6 +1477,129 @@ func (x *ModuleProgress_Failed) GetLogsTruncated() bool {
 	return false
 }
 
+type ModuleProgress_Throughput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Blocks processed per second, averaged over a recent time window.
+	BlocksPerSecond float64 `protobuf:"fixed64,1,opt,name=blocks_per_second,json=blocksPerSecond,proto3" json:"blocks_per_second,omitempty"`
+	ProcessedBlocks uint64  `protobuf:"varint,2,opt,name=processed_blocks,json=processedBlocks,proto3" json:"processed_blocks,omitempty"`
+	// Number of blocks to process to complete the work, 0 when unbounded
+	// (for example when live streaming without a stop block).
+	TotalBlocks uint64 `protobuf:"varint,3,opt,name=total_blocks,json=totalBlocks,proto3" json:"total_blocks,omitempty"`
+	// Estimated number of seconds until `total_blocks` are processed, 0 when unknown.
+	EtaSeconds uint64 `protobuf:"varint,4,opt,name=eta_seconds,json=etaSeconds,proto3" json:"eta_seconds,omitempty"`
+}
+
+func (x *ModuleProgress_Throughput) Reset() {
+	*x = ModuleProgress_Throughput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModuleProgress_Throughput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleProgress_Throughput) ProtoMessage() {}
+
+func (x *ModuleProgress_Throughput) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleProgress_Throughput.ProtoReflect.Descriptor instead.
+func (*ModuleProgress_Throughput) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9, 4}
+}
+
+func (x *ModuleProgress_Throughput) GetBlocksPerSecond() float64 {
+	if x != nil {
+		return x.BlocksPerSecond
+	}
+	return 0
+}
+
+func (x *ModuleProgress_Throughput) GetProcessedBlocks() uint64 {
+	if x != nil {
+		return x.ProcessedBlocks
+	}
+	return 0
+}
+
+func (x *ModuleProgress_Throughput) GetTotalBlocks() uint64 {
+	if x != nil {
+		return x.TotalBlocks
+	}
+	return 0
+}
+
+func (x *ModuleProgress_Throughput) GetEtaSeconds() uint64 {
+	if x != nil {
+		return x.EtaSeconds
+	}
+	return 0
+}
+
+type ModuleProgress_ExecutionStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Largest wasm linear memory reached by an instance of the module, in bytes.
+	PeakMemoryBytes uint64 `protobuf:"varint,1,opt,name=peak_memory_bytes,json=peakMemoryBytes,proto3" json:"peak_memory_bytes,omitempty"`
+}
+
+func (x *ModuleProgress_ExecutionStats) Reset() {
+	*x = ModuleProgress_ExecutionStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sf_substreams_v1_substreams_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModuleProgress_ExecutionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleProgress_ExecutionStats) ProtoMessage() {}
+
+func (x *ModuleProgress_ExecutionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_sf_substreams_v1_substreams_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleProgress_ExecutionStats.ProtoReflect.Descriptor instead.
+func (*ModuleProgress_ExecutionStats) Descriptor() ([]byte, []int) {
+	return file_sf_substreams_v1_substreams_proto_rawDescGZIP(), []int{9, 5}
+}
+
+func (x *ModuleProgress_ExecutionStats) GetPeakMemoryBytes() uint64 {
+	if x != nil {
+		return x.PeakMemoryBytes
+	}
+	return 0
+}
+
 var File_sf_substreams_v1_substreams_proto protoreflect.FileDescriptor
 
 var file_sf_substreams_v1_substreams_proto_rawDesc = []byte{
@@ -1278,7 +1614,7 @@ var file_sf_substreams_v1_substreams_proto_rawDesc = []byte{
 	0x2f, 0x76, 0x31, 0x2f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x1a, 0x1c, 0x73, 0x66, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
 	0x2f, 0x76, 0x31, 0x2f, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0x9a, 0x03, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x73,
+	0xda, 0x03, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x73,
 	0x74, 0x61, 0x72, 0x74, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
 	0x4e, 0x75, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x63, 0x75, 0x72,
@@ -1303,7 +1639,11 @@ var file_sf_substreams_v1_substreams_proto_rawDesc = []byte{
 	0x65, 0x5f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x66, 0x6f, 0x72, 0x5f, 0x6d,
 	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x1e, 0x69, 0x6e,
 	0x69, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
-	0x6f, 0x74, 0x46, 0x6f, 0x72, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x22, 0xb7, 0x02, 0x0a,
+	0x6f, 0x74, 0x46, 0x6f, 0x72, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x3e, 0x0a, 0x0d,
+	0x6d, 0x69, 0x6e, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52,
+	0x0b, 0x6d, 0x69, 0x6e, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0xb7, 0x02, 0x0a,
 	0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x08, 0x70, 0x72, 0x6f,
 	0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73, 0x66,
 	0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
@@ -1349,7 +1689,7 @@ var file_sf_substreams_v1_substreams_proto_rawDesc = []byte{
 	0x32, 0x1a, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
 	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x6b, 0x53, 0x74, 0x65, 0x70, 0x52, 0x04, 0x73, 0x74,
 	0x65, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x0a, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0xe0, 0x01, 0x0a, 0x0c, 0x4d,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0x82, 0x02, 0x0a, 0x0c, 0x4d,
 	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e,
 	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
 	0x35, 0x0a, 0x0a, 0x6d, 0x61, 0x70, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20,
@@ -1359,114 +1699,163 @@ var file_sf_substreams_v1_substreams_proto_rawDesc = []byte{
 	0x64, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x73,
 	0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e,
 	0x53, 0x74, 0x6f, 0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x48, 0x00, 0x52, 0x0b, 0x73,
-	0x74, 0x6f, 0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x6f,
-	0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x12, 0x25,
-	0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x73, 0x5f, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x6c, 0x6f, 0x67, 0x73, 0x54, 0x72, 0x75, 0x6e,
-	0x63, 0x61, 0x74, 0x65, 0x64, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x4d, 0x0a,
-	0x0f, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
-	0x12, 0x3a, 0x0a, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x20, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
-	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72,
-	0x65, 0x73, 0x73, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x22, 0xe6, 0x05, 0x0a,
-	0x0e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12,
-	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x12, 0x5c, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64,
-	0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e,
-	0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31,
-	0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x2e,
-	0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x00,
-	0x52, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x52, 0x61, 0x6e, 0x67, 0x65,
-	0x73, 0x12, 0x54, 0x0a, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x73, 0x74, 0x61,
-	0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75,
-	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75,
-	0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x49, 0x6e, 0x69, 0x74, 0x69,
-	0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x69, 0x6e, 0x69, 0x74, 0x69,
-	0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x5a, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65,
-	0x73, 0x73, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x74, 0x6f, 0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x12, 0x2e, 0x0a, 0x04, 0x6c, 0x6f,
+	0x67, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75,
+	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x6f,
+	0x67, 0x73, 0x5f, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0d, 0x6c, 0x6f, 0x67, 0x73, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x22,
+	0x8a, 0x01, 0x0a, 0x08, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x30, 0x0a, 0x05,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x66,
+	0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
+	0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x32, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c,
+	0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75,
+	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x46,
+	0x69, 0x65, 0x6c, 0x64, 0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x22, 0x32, 0x0a, 0x08,
+	0x4c, 0x6f, 0x67, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x22, 0x4d, 0x0a, 0x0f, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x50, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x3a, 0x0a, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x22,
+	0x9b, 0x09, 0x0a, 0x0e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x5c, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x65, 0x64, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
 	0x32, 0x2f, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
 	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
-	0x73, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65,
-	0x73, 0x48, 0x00, 0x52, 0x0e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x42, 0x79,
-	0x74, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65,
+	0x73, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x48, 0x00, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x73, 0x12, 0x54, 0x0a, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x73, 0x66,
+	0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x49, 0x6e,
+	0x69, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x69, 0x6e,
+	0x69, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x5a, 0x0a, 0x0f, 0x70, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65,
 	0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f,
-	0x67, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x48, 0x00, 0x52, 0x06,
-	0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x1a, 0x59, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73,
-	0x73, 0x65, 0x64, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x47, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63,
-	0x65, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61, 0x6e, 0x67, 0x65,
-	0x52, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x52, 0x61, 0x6e, 0x67, 0x65,
-	0x73, 0x1a, 0x41, 0x0a, 0x0c, 0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74,
-	0x65, 0x12, 0x31, 0x0a, 0x15, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x75,
-	0x70, 0x5f, 0x74, 0x6f, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x12, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x55, 0x70, 0x54, 0x6f, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x1a, 0x6a, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65,
-	0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f,
-	0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x61, 0x64,
-	0x12, 0x2e, 0x0a, 0x13, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f,
-	0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x74,
-	0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e,
-	0x1a, 0x5b, 0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
-	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73,
-	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
-	0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x73, 0x5f, 0x74,
-	0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d,
-	0x6c, 0x6f, 0x67, 0x73, 0x54, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x42, 0x06, 0x0a,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x4a, 0x0a, 0x0a, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61,
-	0x6e, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x6e, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x65, 0x6e, 0x64, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x22, 0x43, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x73,
-	0x12, 0x34, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x48, 0x00, 0x52, 0x0e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65,
+	0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
+	0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x48,
+	0x00, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x4d, 0x0a, 0x0a, 0x74, 0x68, 0x72,
+	0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e,
+	0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x2e,
+	0x54, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x48, 0x00, 0x52, 0x0a, 0x74, 0x68,
+	0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x12, 0x5a, 0x0a, 0x0f, 0x65, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x2f, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x48, 0x00, 0x52, 0x0e, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x1a, 0x59, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65,
+	0x64, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x47, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x65, 0x64, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
 	0x32, 0x1c, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
-	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x52, 0x06,
-	0x64, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x22, 0xf4, 0x01, 0x0a, 0x0a, 0x53, 0x74, 0x6f, 0x72, 0x65,
-	0x44, 0x65, 0x6c, 0x74, 0x61, 0x12, 0x44, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x26, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x0f,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x1a,
+	0x41, 0x0a, 0x0c, 0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x31, 0x0a, 0x15, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x75, 0x70, 0x5f,
+	0x74, 0x6f, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12,
+	0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x55, 0x70, 0x54, 0x6f, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x1a, 0x6a, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x61, 0x64, 0x12, 0x2e,
+	0x0a, 0x13, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x77, 0x72,
+	0x69, 0x74, 0x74, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x1a, 0x7d,
+	0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x12, 0x2e, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73,
+	0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x73, 0x5f, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x6c, 0x6f, 0x67, 0x73, 0x54, 0x72,
+	0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x1a, 0xa7, 0x01,
+	0x0a, 0x0a, 0x54, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x12, 0x2a, 0x0a, 0x11,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x50,
+	0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x74, 0x61, 0x5f, 0x73, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x65, 0x74, 0x61,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x1a, 0x3c, 0x0a, 0x0e, 0x45, 0x78, 0x65, 0x63, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x65, 0x61,
+	0x6b, 0x5f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x70, 0x65, 0x61, 0x6b, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x4a, 0x0a,
+	0x0a, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1b, 0x0a, 0x09,
+	0x65, 0x6e, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x08, 0x65, 0x6e, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x43, 0x0a, 0x0b, 0x53, 0x74, 0x6f,
+	0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x12, 0x34, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x74,
+	0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75,
 	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72,
-	0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6f,
-	0x72, 0x64, 0x69, 0x6e, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6f, 0x72,
-	0x64, 0x69, 0x6e, 0x61, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x6c, 0x64, 0x5f, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6f, 0x6c, 0x64, 0x56,
-	0x61, 0x6c, 0x75, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x56, 0x61, 0x6c, 0x75,
-	0x65, 0x22, 0x3a, 0x0a, 0x09, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x09,
-	0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x52, 0x45,
-	0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10,
-	0x02, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x03, 0x22, 0xa6, 0x01,
-	0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69,
-	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64,
-	0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
-	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x2a, 0x5c, 0x0a, 0x08, 0x46, 0x6f, 0x72, 0x6b, 0x53, 0x74,
-	0x65, 0x70, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54, 0x45, 0x50, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f,
-	0x57, 0x4e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x54, 0x45, 0x50, 0x5f, 0x4e, 0x45, 0x57,
-	0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x54, 0x45, 0x50, 0x5f, 0x55, 0x4e, 0x44, 0x4f, 0x10,
-	0x02, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x54, 0x45, 0x50, 0x5f, 0x49, 0x52, 0x52, 0x45, 0x56, 0x45,
-	0x52, 0x53, 0x49, 0x42, 0x4c, 0x45, 0x10, 0x04, 0x22, 0x04, 0x08, 0x03, 0x10, 0x03, 0x22, 0x04,
-	0x08, 0x05, 0x10, 0x05, 0x32, 0x4b, 0x0a, 0x06, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x41,
-	0x0a, 0x06, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x19, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75,
-	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65,
-	0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30,
-	0x01, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73, 0x74, 0x2f, 0x73, 0x75,
-	0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x73, 0x66, 0x2f, 0x73,
-	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x70, 0x62, 0x73,
-	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x22, 0xf4,
+	0x01, 0x0a, 0x0a, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x12, 0x44, 0x0a,
+	0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x26, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x2e, 0x4f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x6c, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x1b, 0x0a, 0x09, 0x6f, 0x6c, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x08, 0x6f, 0x6c, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1b, 0x0a, 0x09,
+	0x6e, 0x65, 0x77, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x08, 0x6e, 0x65, 0x77, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x3a, 0x0a, 0x09, 0x4f, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10,
+	0x00, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a,
+	0x06, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c,
+	0x45, 0x54, 0x45, 0x10, 0x03, 0x22, 0xa6, 0x01, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x12, 0x1b, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x12, 0x19, 0x0a,
+	0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x2a, 0x5c,
+	0x0a, 0x08, 0x46, 0x6f, 0x72, 0x6b, 0x53, 0x74, 0x65, 0x70, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54,
+	0x45, 0x50, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08,
+	0x53, 0x54, 0x45, 0x50, 0x5f, 0x4e, 0x45, 0x57, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x54,
+	0x45, 0x50, 0x5f, 0x55, 0x4e, 0x44, 0x4f, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x54, 0x45,
+	0x50, 0x5f, 0x49, 0x52, 0x52, 0x45, 0x56, 0x45, 0x52, 0x53, 0x49, 0x42, 0x4c, 0x45, 0x10, 0x04,
+	0x22, 0x04, 0x08, 0x03, 0x10, 0x03, 0x22, 0x04, 0x08, 0x05, 0x10, 0x05, 0x2a, 0x77, 0x0a, 0x08,
+	0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x19, 0x0a, 0x15, 0x4c, 0x4f, 0x47, 0x5f,
+	0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x4c, 0x4f, 0x47, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c,
+	0x5f, 0x44, 0x45, 0x42, 0x55, 0x47, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x4f, 0x47, 0x5f,
+	0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e,
+	0x4c, 0x4f, 0x47, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x57, 0x41, 0x52, 0x4e, 0x10, 0x03,
+	0x12, 0x13, 0x0a, 0x0f, 0x4c, 0x4f, 0x47, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x10, 0x04, 0x32, 0x4b, 0x0a, 0x06, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
+	0x41, 0x0a, 0x06, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x19, 0x2e, 0x73, 0x66, 0x2e, 0x73,
+	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x73, 0x66, 0x2e, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x30, 0x01, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x66, 0x61, 0x73, 0x74, 0x2f, 0x73,
+	0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x73, 0x66, 0x2f,
+	0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x70, 0x62,
+	0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -1481,62 +1870,74 @@ func file_sf_substreams_v1_substreams_proto_rawDescGZIP() []byte {
 	return file_sf_substreams_v1_substreams_proto_rawDescData
 }
 
-var file_sf_substreams_v1_substreams_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_sf_substreams_v1_substreams_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_sf_substreams_v1_substreams_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_sf_substreams_v1_substreams_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_sf_substreams_v1_substreams_proto_goTypes = []interface{}{
 	(ForkStep)(0),                         // 0: sf.substreams.v1.ForkStep
-	(StoreDelta_Operation)(0),             // 1: sf.substreams.v1.StoreDelta.Operation
-	(*Request)(nil),                       // 2: sf.substreams.v1.Request
-	(*Response)(nil),                      // 3: sf.substreams.v1.Response
-	(*InitialSnapshotComplete)(nil),       // 4: sf.substreams.v1.InitialSnapshotComplete
-	(*InitialSnapshotData)(nil),           // 5: sf.substreams.v1.InitialSnapshotData
-	(*BlockScopedData)(nil),               // 6: sf.substreams.v1.BlockScopedData
-	(*ModuleOutput)(nil),                  // 7: sf.substreams.v1.ModuleOutput
-	(*ModulesProgress)(nil),               // 8: sf.substreams.v1.ModulesProgress
-	(*ModuleProgress)(nil),                // 9: sf.substreams.v1.ModuleProgress
-	(*BlockRange)(nil),                    // 10: sf.substreams.v1.BlockRange
-	(*StoreDeltas)(nil),                   // 11: sf.substreams.v1.StoreDeltas
-	(*StoreDelta)(nil),                    // 12: sf.substreams.v1.StoreDelta
-	(*Output)(nil),                        // 13: sf.substreams.v1.Output
-	(*ModuleProgress_ProcessedRange)(nil), // 14: sf.substreams.v1.ModuleProgress.ProcessedRange
-	(*ModuleProgress_InitialState)(nil),   // 15: sf.substreams.v1.ModuleProgress.InitialState
-	(*ModuleProgress_ProcessedBytes)(nil), // 16: sf.substreams.v1.ModuleProgress.ProcessedBytes
-	(*ModuleProgress_Failed)(nil),         // 17: sf.substreams.v1.ModuleProgress.Failed
-	(*Modules)(nil),                       // 18: sf.substreams.v1.Modules
-	(*Clock)(nil),                         // 19: sf.substreams.v1.Clock
-	(*anypb.Any)(nil),                     // 20: google.protobuf.Any
-	(*timestamppb.Timestamp)(nil),         // 21: google.protobuf.Timestamp
+	(LogLevel)(0),                         // 1: sf.substreams.v1.LogLevel
+	(StoreDelta_Operation)(0),             // 2: sf.substreams.v1.StoreDelta.Operation
+	(*Request)(nil),                       // 3: sf.substreams.v1.Request
+	(*Response)(nil),                      // 4: sf.substreams.v1.Response
+	(*InitialSnapshotComplete)(nil),       // 5: sf.substreams.v1.InitialSnapshotComplete
+	(*InitialSnapshotData)(nil),           // 6: sf.substreams.v1.InitialSnapshotData
+	(*BlockScopedData)(nil),               // 7: sf.substreams.v1.BlockScopedData
+	(*ModuleOutput)(nil),                  // 8: sf.substreams.v1.ModuleOutput
+	(*LogEntry)(nil),                      // 9: sf.substreams.v1.LogEntry
+	(*LogField)(nil),                      // 10: sf.substreams.v1.LogField
+	(*ModulesProgress)(nil),               // 11: sf.substreams.v1.ModulesProgress
+	(*ModuleProgress)(nil),                // 12: sf.substreams.v1.ModuleProgress
+	(*BlockRange)(nil),                    // 13: sf.substreams.v1.BlockRange
+	(*StoreDeltas)(nil),                   // 14: sf.substreams.v1.StoreDeltas
+	(*StoreDelta)(nil),                    // 15: sf.substreams.v1.StoreDelta
+	(*Output)(nil),                        // 16: sf.substreams.v1.Output
+	(*ModuleProgress_ProcessedRange)(nil), // 17: sf.substreams.v1.ModuleProgress.ProcessedRange
+	(*ModuleProgress_InitialState)(nil),   // 18: sf.substreams.v1.ModuleProgress.InitialState
+	(*ModuleProgress_ProcessedBytes)(nil), // 19: sf.substreams.v1.ModuleProgress.ProcessedBytes
+	(*ModuleProgress_Failed)(nil),         // 20: sf.substreams.v1.ModuleProgress.Failed
+	(*ModuleProgress_Throughput)(nil),     // 21: sf.substreams.v1.ModuleProgress.Throughput
+	(*ModuleProgress_ExecutionStats)(nil), // 22: sf.substreams.v1.ModuleProgress.ExecutionStats
+	(*Modules)(nil),                       // 23: sf.substreams.v1.Modules
+	(*Clock)(nil),                         // 24: sf.substreams.v1.Clock
+	(*anypb.Any)(nil),                     // 25: google.protobuf.Any
+	(*timestamppb.Timestamp)(nil),         // 26: google.protobuf.Timestamp
 }
 var file_sf_substreams_v1_substreams_proto_depIdxs = []int32{
 	0,  // 0: sf.substreams.v1.Request.fork_steps:type_name -> sf.substreams.v1.ForkStep
-	18, // 1: sf.substreams.v1.Request.modules:type_name -> sf.substreams.v1.Modules
-	8,  // 2: sf.substreams.v1.Response.progress:type_name -> sf.substreams.v1.ModulesProgress
-	5,  // 3: sf.substreams.v1.Response.snapshot_data:type_name -> sf.substreams.v1.InitialSnapshotData
-	4,  // 4: sf.substreams.v1.Response.snapshot_complete:type_name -> sf.substreams.v1.InitialSnapshotComplete
-	6,  // 5: sf.substreams.v1.Response.data:type_name -> sf.substreams.v1.BlockScopedData
-	11, // 6: sf.substreams.v1.InitialSnapshotData.deltas:type_name -> sf.substreams.v1.StoreDeltas
-	7,  // 7: sf.substreams.v1.BlockScopedData.outputs:type_name -> sf.substreams.v1.ModuleOutput
-	19, // 8: sf.substreams.v1.BlockScopedData.clock:type_name -> sf.substreams.v1.Clock
-	0,  // 9: sf.substreams.v1.BlockScopedData.step:type_name -> sf.substreams.v1.ForkStep
-	20, // 10: sf.substreams.v1.ModuleOutput.map_output:type_name -> google.protobuf.Any
-	11, // 11: sf.substreams.v1.ModuleOutput.store_deltas:type_name -> sf.substreams.v1.StoreDeltas
-	9,  // 12: sf.substreams.v1.ModulesProgress.modules:type_name -> sf.substreams.v1.ModuleProgress
-	14, // 13: sf.substreams.v1.ModuleProgress.processed_ranges:type_name -> sf.substreams.v1.ModuleProgress.ProcessedRange
-	15, // 14: sf.substreams.v1.ModuleProgress.initial_state:type_name -> sf.substreams.v1.ModuleProgress.InitialState
-	16, // 15: sf.substreams.v1.ModuleProgress.processed_bytes:type_name -> sf.substreams.v1.ModuleProgress.ProcessedBytes
-	17, // 16: sf.substreams.v1.ModuleProgress.failed:type_name -> sf.substreams.v1.ModuleProgress.Failed
-	12, // 17: sf.substreams.v1.StoreDeltas.deltas:type_name -> sf.substreams.v1.StoreDelta
-	1,  // 18: sf.substreams.v1.StoreDelta.operation:type_name -> sf.substreams.v1.StoreDelta.Operation
-	21, // 19: sf.substreams.v1.Output.timestamp:type_name -> google.protobuf.Timestamp
-	20, // 20: sf.substreams.v1.Output.value:type_name -> google.protobuf.Any
-	10, // 21: sf.substreams.v1.ModuleProgress.ProcessedRange.processed_ranges:type_name -> sf.substreams.v1.BlockRange
-	2,  // 22: sf.substreams.v1.Stream.Blocks:input_type -> sf.substreams.v1.Request
-	3,  // 23: sf.substreams.v1.Stream.Blocks:output_type -> sf.substreams.v1.Response
-	23, // [23:24] is the sub-list for method output_type
-	22, // [22:23] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	23, // 1: sf.substreams.v1.Request.modules:type_name -> sf.substreams.v1.Modules
+	1,  // 2: sf.substreams.v1.Request.min_log_level:type_name -> sf.substreams.v1.LogLevel
+	11, // 3: sf.substreams.v1.Response.progress:type_name -> sf.substreams.v1.ModulesProgress
+	6,  // 4: sf.substreams.v1.Response.snapshot_data:type_name -> sf.substreams.v1.InitialSnapshotData
+	5,  // 5: sf.substreams.v1.Response.snapshot_complete:type_name -> sf.substreams.v1.InitialSnapshotComplete
+	7,  // 6: sf.substreams.v1.Response.data:type_name -> sf.substreams.v1.BlockScopedData
+	14, // 7: sf.substreams.v1.InitialSnapshotData.deltas:type_name -> sf.substreams.v1.StoreDeltas
+	8,  // 8: sf.substreams.v1.BlockScopedData.outputs:type_name -> sf.substreams.v1.ModuleOutput
+	24, // 9: sf.substreams.v1.BlockScopedData.clock:type_name -> sf.substreams.v1.Clock
+	0,  // 10: sf.substreams.v1.BlockScopedData.step:type_name -> sf.substreams.v1.ForkStep
+	25, // 11: sf.substreams.v1.ModuleOutput.map_output:type_name -> google.protobuf.Any
+	14, // 12: sf.substreams.v1.ModuleOutput.store_deltas:type_name -> sf.substreams.v1.StoreDeltas
+	9,  // 13: sf.substreams.v1.ModuleOutput.logs:type_name -> sf.substreams.v1.LogEntry
+	1,  // 14: sf.substreams.v1.LogEntry.level:type_name -> sf.substreams.v1.LogLevel
+	10, // 15: sf.substreams.v1.LogEntry.fields:type_name -> sf.substreams.v1.LogField
+	12, // 16: sf.substreams.v1.ModulesProgress.modules:type_name -> sf.substreams.v1.ModuleProgress
+	17, // 17: sf.substreams.v1.ModuleProgress.processed_ranges:type_name -> sf.substreams.v1.ModuleProgress.ProcessedRange
+	18, // 18: sf.substreams.v1.ModuleProgress.initial_state:type_name -> sf.substreams.v1.ModuleProgress.InitialState
+	19, // 19: sf.substreams.v1.ModuleProgress.processed_bytes:type_name -> sf.substreams.v1.ModuleProgress.ProcessedBytes
+	20, // 20: sf.substreams.v1.ModuleProgress.failed:type_name -> sf.substreams.v1.ModuleProgress.Failed
+	21, // 21: sf.substreams.v1.ModuleProgress.throughput:type_name -> sf.substreams.v1.ModuleProgress.Throughput
+	22, // 22: sf.substreams.v1.ModuleProgress.execution_stats:type_name -> sf.substreams.v1.ModuleProgress.ExecutionStats
+	15, // 23: sf.substreams.v1.StoreDeltas.deltas:type_name -> sf.substreams.v1.StoreDelta
+	2,  // 24: sf.substreams.v1.StoreDelta.operation:type_name -> sf.substreams.v1.StoreDelta.Operation
+	26, // 25: sf.substreams.v1.Output.timestamp:type_name -> google.protobuf.Timestamp
+	25, // 26: sf.substreams.v1.Output.value:type_name -> google.protobuf.Any
+	13, // 27: sf.substreams.v1.ModuleProgress.ProcessedRange.processed_ranges:type_name -> sf.substreams.v1.BlockRange
+	9,  // 28: sf.substreams.v1.ModuleProgress.Failed.logs:type_name -> sf.substreams.v1.LogEntry
+	3,  // 29: sf.substreams.v1.Stream.Blocks:input_type -> sf.substreams.v1.Request
+	4,  // 30: sf.substreams.v1.Stream.Blocks:output_type -> sf.substreams.v1.Response
+	30, // [30:31] is the sub-list for method output_type
+	29, // [29:30] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_sf_substreams_v1_substreams_proto_init() }
@@ -1620,7 +2021,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModulesProgress); i {
+			switch v := v.(*LogEntry); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1632,7 +2033,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModuleProgress); i {
+			switch v := v.(*LogField); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1644,7 +2045,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BlockRange); i {
+			switch v := v.(*ModulesProgress); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1656,7 +2057,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StoreDeltas); i {
+			switch v := v.(*ModuleProgress); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1668,7 +2069,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StoreDelta); i {
+			switch v := v.(*BlockRange); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1680,7 +2081,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Output); i {
+			switch v := v.(*StoreDeltas); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1692,7 +2093,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModuleProgress_ProcessedRange); i {
+			switch v := v.(*StoreDelta); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1704,7 +2105,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModuleProgress_InitialState); i {
+			switch v := v.(*Output); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1716,7 +2117,7 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ModuleProgress_ProcessedBytes); i {
+			switch v := v.(*ModuleProgress_ProcessedRange); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1728,6 +2129,30 @@ func file_sf_substreams_v1_substreams_proto_init() {
 			}
 		}
 		file_sf_substreams_v1_substreams_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModuleProgress_InitialState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sf_substreams_v1_substreams_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModuleProgress_ProcessedBytes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sf_substreams_v1_substreams_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ModuleProgress_Failed); i {
 			case 0:
 				return &v.state
@@ -1739,6 +2164,30 @@ func file_sf_substreams_v1_substreams_proto_init() {
 				return nil
 			}
 		}
+		file_sf_substreams_v1_substreams_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModuleProgress_Throughput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sf_substreams_v1_substreams_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ModuleProgress_ExecutionStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_sf_substreams_v1_substreams_proto_msgTypes[1].OneofWrappers = []interface{}{
 		(*Response_Progress)(nil),
@@ -1750,19 +2199,21 @@ func file_sf_substreams_v1_substreams_proto_init() {
 		(*ModuleOutput_MapOutput)(nil),
 		(*ModuleOutput_StoreDeltas)(nil),
 	}
-	file_sf_substreams_v1_substreams_proto_msgTypes[7].OneofWrappers = []interface{}{
+	file_sf_substreams_v1_substreams_proto_msgTypes[9].OneofWrappers = []interface{}{
 		(*ModuleProgress_ProcessedRanges)(nil),
 		(*ModuleProgress_InitialState_)(nil),
 		(*ModuleProgress_ProcessedBytes_)(nil),
 		(*ModuleProgress_Failed_)(nil),
+		(*ModuleProgress_Throughput_)(nil),
+		(*ModuleProgress_ExecutionStats_)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_sf_substreams_v1_substreams_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   16,
+			NumEnums:      3,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -33,28 +33,29 @@ func (p *Pipeline) backProcessStores(
 		return nil, fmt.Errorf("fetching stores states: %w", err)
 	}
 
-	workPlan := orchestrator.WorkPlan{}
-	for _, mod := range p.storeModules {
-
-		snapshot := storageState.Snapshots[mod.Name]
-		if workUnit := orchestrator.SplitWork(mod.Name, p.storeSaveInterval, mod.InitialBlock, uint64(p.request.StartBlockNum), snapshot); workUnit != nil {
-			workPlan[mod.Name] = workUnit
-		}
-	}
+	workPlan := orchestrator.BuildWorkPlan(initialStoreMap, storageState, p.requestedStartBlockNum)
 
 	progressMessages := workPlan.ProgressMessages()
 	if err := p.respFunc(substreams.NewModulesProgressResponse(progressMessages)); err != nil {
 		return nil, fmt.Errorf("sending progress: %w", err)
 	}
 
-	upToBlock := uint64(p.request.StartBlockNum)
+	upToBlock := p.requestedStartBlockNum
 
 	strategy, err := orchestrator.NewOrderedStrategy(ctx, workPlan, uint64(p.subrequestSplitSize), initialStoreMap, p.graph, jobPool)
 	if err != nil {
 		return nil, fmt.Errorf("creating strategy: %w", err)
 	}
 
-	squasher, err := orchestrator.NewSquasher(ctx, workPlan, initialStoreMap, upToBlock, jobPool)
+	var squasherOpts []orchestrator.SquasherOption
+	if p.storeCheckpointInterval != 0 {
+		squasherOpts = append(squasherOpts,
+			orchestrator.WithStoreCheckpointInterval(p.storeCheckpointInterval),
+			orchestrator.WithStoreSnapshotBlocks(jobPool.SnapshotBlocks()),
+		)
+	}
+
+	squasher, err := orchestrator.NewSquasher(ctx, workPlan, initialStoreMap, upToBlock, jobPool, squasherOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("initializing squasher: %w", err)
 	}
@@ -64,7 +65,12 @@ func (p *Pipeline) backProcessStores(
 		return nil, err
 	}
 
-	scheduler, err := orchestrator.NewScheduler(ctx, strategy, squasher, workerPool, p.respFunc)
+	var schedulerOpts []orchestrator.SchedulerOption
+	if p.subrequestRetryPolicy != nil {
+		schedulerOpts = append(schedulerOpts, orchestrator.WithRetryPolicy(*p.subrequestRetryPolicy))
+	}
+
+	scheduler, err := orchestrator.NewScheduler(ctx, strategy, squasher, workerPool, workPlan, p.respFunc, schedulerOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("initializing scheduler: %w", err)
 	}
@@ -24,8 +24,12 @@ type ModuleExecutor interface {
 
 	run(vals map[string][]byte, clock *pbsubstreams.Clock) error
 
-	moduleLogs() (logs []string, truncated bool)
+	moduleLogs() (logs []*pbsubstreams.LogEntry, truncated bool)
 	moduleOutputData() pbsubstreams.ModuleOutputData
+
+	// peakMemoryBytes returns the largest wasm memory used by the module
+	// on any block executed so far, 0 when it was never executed.
+	peakMemoryBytes() uint64
 }
 
 type BaseExecutor struct {
@@ -35,6 +39,12 @@ type BaseExecutor struct {
 	cache      *outputs.OutputCache
 	isOutput   bool // whether output is enabled for this module
 	entrypoint string
+
+	peakMemory uint64
+}
+
+func (e *BaseExecutor) peakMemoryBytes() uint64 {
+	return e.peakMemory
 }
 
 var _ ModuleExecutor = (*MapperModuleExecutor)(nil)
@@ -176,7 +186,7 @@ func (e *BaseExecutor) wasmCall(vals map[string][]byte, clock *pbsubstreams.Cloc
 			}
 		case wasm.InputStore:
 			hasInput = true
-		case wasm.OutputStore:
+		case wasm.OutputStore, wasm.InputParams:
 
 		default:
 			panic(fmt.Sprintf("Invalid input type %d", input.Type))
@@ -192,14 +202,18 @@ func (e *BaseExecutor) wasmCall(vals map[string][]byte, clock *pbsubstreams.Cloc
 		if err != nil {
 			return nil, fmt.Errorf("new wasm instance: %w", err)
 		}
-		if err = instance.Execute(); err != nil {
+		err = instance.Execute()
+		if peak := instance.PeakMemoryBytes(); peak > e.peakMemory {
+			e.peakMemory = peak
+		}
+		if err != nil {
 			return nil, fmt.Errorf("block %d: module %q: wasm execution failed: %w", clock.Number, e.moduleName, err)
 		}
 	}
 	return
 }
 
-func (e *StoreModuleExecutor) moduleLogs() (logs []string, truncated bool) {
+func (e *StoreModuleExecutor) moduleLogs() (logs []*pbsubstreams.LogEntry, truncated bool) {
 	if instance := e.wasmModule.CurrentInstance; instance != nil {
 		return instance.Logs, instance.ReachedLogsMaxByteCount()
 	}
@@ -246,7 +260,7 @@ func (e *StoreModuleExecutor) Reset() { e.wasmModule.CurrentInstance = nil }
 
 func (e *MapperModuleExecutor) Reset() { e.wasmModule.CurrentInstance = nil }
 
-func (e *MapperModuleExecutor) moduleLogs() (logs []string, truncated bool) {
+func (e *MapperModuleExecutor) moduleLogs() (logs []*pbsubstreams.LogEntry, truncated bool) {
 	if instance := e.wasmModule.CurrentInstance; instance != nil {
 		return instance.Logs, instance.ReachedLogsMaxByteCount()
 	}
@@ -2,9 +2,12 @@ package pipeline
 
 import (
 	"context"
+	"time"
 
 	"github.com/streamingfast/substreams"
+	"github.com/streamingfast/substreams/orchestrator"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/wasm"
 )
 
 type PipelineOptioner interface {
@@ -28,6 +31,94 @@ func WithStoresSaveInterval(seconds uint64) Option {
 	}
 }
 
+// WithStoresCheckpointInterval sets how often, in blocks, full store
+// snapshots are written while squashing partials during back-processing.
+func WithStoresCheckpointInterval(blocks uint64) Option {
+	return func(p *Pipeline) {
+		p.storeCheckpointInterval = blocks
+	}
+}
+
+// WithSubrequestRetryPolicy sets the backoff applied when retrying
+// subrequests that failed with a retryable error.
+func WithSubrequestRetryPolicy(policy orchestrator.RetryPolicy) Option {
+	return func(p *Pipeline) {
+		p.subrequestRetryPolicy = &policy
+	}
+}
+
+// WithOutputCacheReplay sends the outputs already in the output caches
+// without reading blocks, for historical requests without cursor. Normal
+// processing resumes at the first block the caches do not cover.
+func WithOutputCacheReplay() Option {
+	return func(p *Pipeline) {
+		p.outputCacheReplay = true
+	}
+}
+
+// WithWASMInstructionBudget caps the wasm instructions each module can
+// execute per block.
+func WithWASMInstructionBudget(instructions uint64) Option {
+	return func(p *Pipeline) {
+		p.wasmOptions = append(p.wasmOptions, wasm.WithInstructionBudget(instructions))
+	}
+}
+
+// WithWASMExecutionTimeout caps the time each module can run per block.
+func WithWASMExecutionTimeout(timeout time.Duration) Option {
+	return func(p *Pipeline) {
+		p.wasmOptions = append(p.wasmOptions, wasm.WithExecutionTimeout(timeout))
+	}
+}
+
+// WithWASMMaxMemoryPages caps the wasm memory of each module instance, in
+// pages of 64 KiB.
+func WithWASMMaxMemoryPages(pages uint32) Option {
+	return func(p *Pipeline) {
+		p.wasmOptions = append(p.wasmOptions, wasm.WithMaxMemoryPages(pages))
+	}
+}
+
+// WithWASMInstanceReuse reuses the wasm instance of every module across
+// blocks, not only of modules opting in with `reuseInstance`.
+func WithWASMInstanceReuse() Option {
+	return func(p *Pipeline) {
+		p.wasmOptions = append(p.wasmOptions, wasm.WithInstanceReuse())
+	}
+}
+
+// WithWASMModuleCache skips the compilation of wasm binaries already
+// compiled by a previous request.
+func WithWASMModuleCache(cache *wasm.ModuleCache) Option {
+	return func(p *Pipeline) {
+		p.wasmOptions = append(p.wasmOptions, wasm.WithModuleCache(cache))
+	}
+}
+
+// WithWASMMaxLogBytes limits the logs collected per module per block,
+// instead of `wasm.DefaultMaxLogBytes`.
+func WithWASMMaxLogBytes(bytes uint64) Option {
+	return func(p *Pipeline) {
+		p.wasmOptions = append(p.wasmOptions, wasm.WithMaxLogBytes(bytes))
+	}
+}
+
+// WithWASMExtensionCallCache memoizes the calls of cacheable wasm
+// extensions in the state store, along the output caches of each module.
+func WithWASMExtensionCallCache() Option {
+	return func(p *Pipeline) {
+		p.cacheExtensionCalls = true
+	}
+}
+
+// WithWASMBackend runs the modules with `backend` instead of the
+// default wasm backend.
+func WithWASMBackend(backend wasm.Backend) Option {
+	return func(p *Pipeline) {
+		p.wasmOptions = append(p.wasmOptions, wasm.WithBackend(backend))
+	}
+}
+
 func WithPreBlockHook(f substreams.BlockHook) Option {
 	return func(p *Pipeline) {
 		p.preBlockHooks = append(p.preBlockHooks, f)
@@ -3,13 +3,14 @@ package outputs
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/streamingfast/bstream"
 	"github.com/streamingfast/derr"
@@ -20,18 +21,35 @@ import (
 )
 
 type CacheItem struct {
-	BlockNum uint64 `json:"block_num"`
-	BlockID  string
-	Payload  []byte `json:"payload"`
+	BlockNum  uint64 `json:"block_num"`
+	BlockID   string
+	ParentID  string    `json:"parent_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Payload   []byte    `json:"payload"`
+}
+
+// blockLink is kept for every block seen in the current range, even
+// those for which the module produced no output, to walk the chain
+// back from a canonical head.
+type blockLink struct {
+	num      uint64
+	parentID string
 }
 type outputKV map[string]*CacheItem
 type OutputCache struct {
 	lock sync.RWMutex
 
+	uploads      sync.WaitGroup
+	uploadsLock  sync.Mutex
+	uploadErrors []error
+
 	ModuleName        string
 	CurrentBlockRange *block.Range
 	//kv                map[string]*bstream.Block
 	kv                outputKV
+	links             map[string]*blockLink // block ID -> link, for the current range
+	currentBlock      string                // ID of the block being processed
+	covered           *block.Range          // blocks of the current range fully processed, nil if none
 	Store             dstore.Store
 	saveBlockInterval uint64
 	//Completed         bool
@@ -42,15 +60,17 @@ func (c *OutputCache) currentFilename() string {
 }
 
 type ModulesOutputCache struct {
-	OutputCaches      map[string]*OutputCache
-	SaveBlockInterval uint64
+	OutputCaches        map[string]*OutputCache
+	ExtensionCallCaches map[string]*ExtensionCallCache
+	SaveBlockInterval   uint64
 }
 
 func NewModuleOutputCache(saveBlockInterval uint64) *ModulesOutputCache {
 	zlog.Debug("creating cache with modules")
 	moduleOutputCache := &ModulesOutputCache{
-		OutputCaches:      make(map[string]*OutputCache),
-		SaveBlockInterval: saveBlockInterval,
+		OutputCaches:        make(map[string]*OutputCache),
+		ExtensionCallCaches: make(map[string]*ExtensionCallCache),
+		SaveBlockInterval:   saveBlockInterval,
 	}
 
 	return moduleOutputCache
@@ -63,40 +83,82 @@ func (c *ModulesOutputCache) RegisterModule(ctx context.Context, module *pbsubst
 		return cache, nil
 	}
 
-	moduleStore, err := baseCacheStore.SubStore(fmt.Sprintf("%s/outputs", hash))
+	moduleStore, err := ModuleStore(baseCacheStore, hash)
 	if err != nil {
 		return nil, fmt.Errorf("creating substore for module %q: %w", module.Name, err)
 	}
 
+	recordAccessInBackground(baseCacheStore, hash, time.Now())
+
 	cache := NewOutputCache(module.Name, moduleStore, c.SaveBlockInterval)
 
 	c.OutputCaches[module.Name] = cache
 
+	extensionStore, err := ExtensionCallStore(baseCacheStore, hash)
+	if err != nil {
+		return nil, fmt.Errorf("creating extension calls substore for module %q: %w", module.Name, err)
+	}
+	c.ExtensionCallCaches[module.Name] = NewExtensionCallCache(module.Name, extensionStore, c.SaveBlockInterval)
+
 	return cache, nil
 }
 
-func (c *ModulesOutputCache) Update(ctx context.Context, blockRef bstream.BlockRef) error {
+// Update moves the caches to `blockRef`, saving the current range of
+// the caches it falls out of, extension call caches included. When the block is irreversible, its
+// parent is the canonical head of everything before it, so the entries
+// of forks abandoned in the saved range are pruned.
+func (c *ModulesOutputCache) Update(ctx context.Context, blockRef bstream.BlockRef, parentID string, irreversible bool) error {
 	for _, moduleCache := range c.OutputCaches {
+		if err := moduleCache.uploadError(); err != nil {
+			return fmt.Errorf("uploading outputs of module %s: %w", moduleCache.ModuleName, err)
+		}
+
+		// Reaching `blockRef` means the previous block went through all modules
+		moduleCache.markCurrentBlockProcessed()
+
 		if moduleCache.IsOutOfRange(blockRef) {
 			zlog.Debug("updating cache", zap.Stringer("block_ref", blockRef))
 
-			previousFilename := moduleCache.currentFilename()
-			if err := moduleCache.save(ctx, previousFilename); err != nil {
-				return fmt.Errorf("saving blocks for module kv %s: %w", moduleCache.ModuleName, err)
+			if irreversible {
+				moduleCache.Prune(parentID)
 			}
 
+			// The next range is loaded before uploading the previous one,
+			// so the upload runs while the next blocks are processed.
+			previousFilename := moduleCache.currentFilename()
+			previousContent := moduleCache.encode()
+
 			if _, err := moduleCache.Load(ctx, moduleCache.CurrentBlockRange.ExclusiveEndBlock); err != nil {
 				return fmt.Errorf("loading blocks %d for module kv %s: %w", moduleCache.CurrentBlockRange.ExclusiveEndBlock, moduleCache.ModuleName, err)
 			}
+
+			moduleCache.upload(ctx, previousFilename, previousContent)
+		}
+
+		moduleCache.setCurrentBlock(blockRef, parentID)
+	}
+
+	for _, extensionCache := range c.ExtensionCallCaches {
+		if err := extensionCache.Update(ctx, blockRef.Num()); err != nil {
+			return fmt.Errorf("saving extension calls of module %s: %w", extensionCache.ModuleName, err)
 		}
 	}
 
 	return nil
 }
 
-func (c *ModulesOutputCache) Flush(ctx context.Context) error {
+// Flush saves the current block range of every module, and waits for
+// all the uploads still in flight, returning the errors they hit. When
+// `irreversible`, the parent of the last block passed to Update is
+// considered the canonical head, and forks are pruned. Extension call
+// caches are left out: their current range is not complete.
+func (c *ModulesOutputCache) Flush(ctx context.Context, irreversible bool) error {
 	zlog.Info("Saving caches")
 	for _, moduleCache := range c.OutputCaches {
+		if irreversible {
+			moduleCache.pruneFromCurrentBlock()
+		}
+
 		filename := moduleCache.currentFilename()
 		zlog.Debug("saving cache for current block range", zap.String("module_name", moduleCache.ModuleName),
 			zap.Uint64("start_block", moduleCache.CurrentBlockRange.StartBlock),
@@ -106,6 +168,17 @@ func (c *ModulesOutputCache) Flush(ctx context.Context) error {
 			return fmt.Errorf("save: saving outpust or module kv %s: %w", moduleCache.ModuleName, err)
 		}
 	}
+
+	var errs []string
+	for _, moduleCache := range c.OutputCaches {
+		moduleCache.uploads.Wait()
+		if err := moduleCache.uploadError(); err != nil {
+			errs = append(errs, fmt.Sprintf("module %s: %s", moduleCache.ModuleName, err))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("uploading outputs: %s", strings.Join(errs, "; "))
+	}
 	return nil
 }
 
@@ -140,6 +213,12 @@ func (o *OutputCache) Set(clock *pbsubstreams.Clock, data []byte) error {
 		BlockID:  clock.Id,
 		Payload:  data,
 	}
+	if clock.Timestamp != nil {
+		ci.Timestamp = clock.Timestamp.AsTime()
+	}
+	if link, found := o.links[clock.Id]; found {
+		ci.ParentID = link.parentID
+	}
 
 	o.kv[clock.Id] = ci
 
@@ -150,9 +229,10 @@ func (o *OutputCache) Get(clock *pbsubstreams.Clock) ([]byte, bool, error) {
 	o.lock.Lock()
 	defer o.lock.Unlock()
 
+	// Block IDs are unique across forks, the number guards against
+	// items of a different chain segment sharing an ID.
 	cacheItem, found := o.kv[clock.Id]
-
-	if !found {
+	if !found || cacheItem.BlockNum != clock.Number {
 		return nil, false, nil
 	}
 
@@ -162,7 +242,13 @@ func (o *OutputCache) Get(clock *pbsubstreams.Clock) ([]byte, bool, error) {
 func (o *OutputCache) Load(ctx context.Context, atBlock uint64) (foud bool, err error) {
 	zlog.Info("loading outputs", zap.String("module_name", o.ModuleName), zap.Uint64("at_block_num", atBlock))
 
+	// The store is not read while an upload to it is in flight
+	o.uploads.Wait()
+
 	o.kv = make(outputKV)
+	o.links = make(map[string]*blockLink)
+	o.currentBlock = ""
+	o.covered = nil
 
 	var found bool
 	o.CurrentBlockRange, found, err = findBlockRange(ctx, o.Store, atBlock)
@@ -184,9 +270,15 @@ func (o *OutputCache) Load(ctx context.Context, atBlock uint64) (foud bool, err
 		if err != nil {
 			return fmt.Errorf("loading block reader %s: %w", filename, err)
 		}
+		defer objectReader.Close()
 
-		if err = json.NewDecoder(objectReader).Decode(&o.kv); err != nil {
-			return fmt.Errorf("json decoding file %s: %w", filename, err)
+		cnt, err := io.ReadAll(objectReader)
+		if err != nil {
+			return fmt.Errorf("reading file %s: %w", filename, err)
+		}
+
+		if o.kv, o.covered, err = decodeOutputFile(cnt); err != nil {
+			return fmt.Errorf("decoding file %s: %w", filename, err)
 		}
 
 		return nil
@@ -199,27 +291,161 @@ func (o *OutputCache) Load(ctx context.Context, atBlock uint64) (foud bool, err
 	return found, nil
 }
 
-func (o *OutputCache) save(ctx context.Context, filename string) error {
-	zlog.Info("saving cache", zap.String("module_name", o.ModuleName), zap.Stringer("block_range", o.CurrentBlockRange), zap.String("filename", filename))
+func (o *OutputCache) setCurrentBlock(blockRef bstream.BlockRef, parentID string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
 
-	buffer := bytes.NewBuffer(nil)
-	err := json.NewEncoder(buffer).Encode(o.kv)
-	if err != nil {
-		return fmt.Errorf("json encoding outputs: %w", err)
+	if o.links == nil {
+		o.links = make(map[string]*blockLink)
+	}
+	o.currentBlock = blockRef.ID()
+	o.links[blockRef.ID()] = &blockLink{num: blockRef.Num(), parentID: parentID}
+}
+
+// markCurrentBlockProcessed extends the covered range with the
+// current block. Processing restarting past the covered range, like
+// when a request starts in the middle of it, resets the coverage.
+func (o *OutputCache) markCurrentBlockProcessed() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	link, found := o.links[o.currentBlock]
+	if !found {
+		return
 	}
-	cnt := buffer.Bytes()
 
+	if o.covered == nil || link.num < o.covered.StartBlock || link.num > o.covered.ExclusiveEndBlock {
+		o.covered = block.NewRange(link.num, link.num+1)
+		return
+	}
+	if link.num+1 > o.covered.ExclusiveEndBlock {
+		o.covered.ExclusiveEndBlock = link.num + 1
+	}
+}
+
+// CoveredRange returns the blocks of the current range known to be
+// processed, for which a missing item means the module produced no
+// output. It is nil when unknown.
+func (o *OutputCache) CoveredRange() *block.Range {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+
+	if o.covered == nil {
+		return nil
+	}
+	return block.NewRange(o.covered.StartBlock, o.covered.ExclusiveEndBlock)
+}
+
+func (o *OutputCache) pruneFromCurrentBlock() {
+	o.lock.RLock()
+	link, found := o.links[o.currentBlock]
+	o.lock.RUnlock()
+
+	if found {
+		o.Prune(link.parentID)
+	}
+}
+
+// canonicalBlocks walks the chain back from `headID`, through the
+// parent of the cached items and of the blocks seen in this range. It
+// returns the canonical block IDs, and the lowest block number the walk
+// reached: below it, canonicity is unknown.
+func (o *OutputCache) canonicalBlocks(headID string) (canonical map[string]bool, lowestBlockNum uint64) {
+	canonical = map[string]bool{}
+	lowestBlockNum = math.MaxUint64
+
+	for id := headID; id != ""; {
+		var num uint64
+		var parentID string
+		if link, found := o.links[id]; found {
+			num, parentID = link.num, link.parentID
+		} else if item, found := o.kv[id]; found {
+			num, parentID = item.BlockNum, item.ParentID
+		} else {
+			break
+		}
+
+		canonical[id] = true
+		lowestBlockNum = num
+		id = parentID
+	}
+	return
+}
+
+// Prune removes the items of the forks not leading to `headID`.
+func (o *OutputCache) Prune(headID string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	canonical, lowestBlockNum := o.canonicalBlocks(headID)
+	if len(canonical) == 0 {
+		zlog.Debug("canonical head not found, skipping prune", zap.String("module_name", o.ModuleName), zap.String("head_id", headID))
+		return
+	}
+
+	pruned := 0
+	for id, item := range o.kv {
+		if item.BlockNum >= lowestBlockNum && !canonical[id] {
+			delete(o.kv, id)
+			pruned++
+		}
+	}
+	if pruned != 0 {
+		zlog.Info("pruned forked outputs", zap.String("module_name", o.ModuleName), zap.Int("pruned", pruned), zap.String("head_id", headID))
+	}
+}
+
+func (o *OutputCache) save(ctx context.Context, filename string) error {
+	o.upload(ctx, filename, o.encode())
+	return nil
+}
+
+func (o *OutputCache) encode() []byte {
+	return encodeOutputFile(o.kv, o.CoveredRange())
+}
+
+// upload writes `cnt` in the background, after the previous upload,
+// so at most one is in flight. Its error is returned by the next call
+// to `uploadError`.
+func (o *OutputCache) upload(ctx context.Context, filename string, cnt []byte) {
+	zlog.Info("saving cache", zap.String("module_name", o.ModuleName), zap.String("filename", filename))
+
+	o.uploads.Wait()
+	o.uploads.Add(1)
 	go func() {
-		err = derr.RetryContext(ctx, 3, func(ctx context.Context) error {
+		defer o.uploads.Done()
+		err := derr.RetryContext(ctx, 3, func(ctx context.Context) error {
 			reader := bytes.NewReader(cnt)
-			return o.Store.WriteObject(ctx, filename, reader)
+			if err := o.Store.WriteObject(ctx, filename, reader); err != nil {
+				// Wrapped, as RetryContext unwraps the last error once retries are exhausted
+				return fmt.Errorf("writing object: %w", err)
+			}
+			return nil
 		})
 		if err != nil {
-			zlog.Warn("failed writing output cache", zap.Error(err))
+			zlog.Warn("failed writing output cache", zap.String("filename", filename), zap.Error(err))
+			o.uploadsLock.Lock()
+			o.uploadErrors = append(o.uploadErrors, fmt.Errorf("writing %s: %w", filename, err))
+			o.uploadsLock.Unlock()
 		}
 	}()
+}
 
-	return nil
+// uploadError returns the errors of the uploads completed since the
+// last call, and clears them: each failed upload is reported once.
+func (o *OutputCache) uploadError() error {
+	o.uploadsLock.Lock()
+	defer o.uploadsLock.Unlock()
+
+	if len(o.uploadErrors) == 0 {
+		return nil
+	}
+	var errs []string
+	for _, err := range o.uploadErrors {
+		errs = append(errs, err.Error())
+	}
+	o.uploadErrors = nil
+	return fmt.Errorf("%d failed uploads: %s", len(errs), strings.Join(errs, "; "))
 }
 
 func (o *OutputCache) String() string {
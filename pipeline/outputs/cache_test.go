@@ -0,0 +1,130 @@
+package outputs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/streamingfast/bstream"
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/block"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputCache_Prune(t *testing.T) {
+	cache := NewOutputCache("mod", dstore.NewMockStore(nil), 10)
+	cache.kv = outputKV{}
+	cache.CurrentBlockRange = block.NewRange(0, 10)
+
+	process := func(num uint64, id, parentID string, output bool) {
+		cache.setCurrentBlock(bstream.NewBlockRef(id, num), parentID)
+		if output {
+			require.NoError(t, cache.Set(&pbsubstreams.Clock{Number: num, Id: id}, []byte(id)))
+		}
+	}
+
+	process(1, "1a", "0a", true)
+	process(2, "2a", "1a", true)
+	process(3, "3a", "2a", false) // no output, still links the chain
+	process(2, "2b", "1a", true)  // fork
+	process(3, "3b", "2b", true)
+	process(4, "4a", "3a", true)
+
+	_, found, _ := cache.Get(&pbsubstreams.Clock{Number: 3, Id: "3b"})
+	assert.True(t, found)
+	_, found, _ = cache.Get(&pbsubstreams.Clock{Number: 4, Id: "3b"})
+	assert.False(t, found, "number must match")
+
+	cache.Prune("4a")
+
+	var ids []string
+	for _, item := range cache.SortedCacheItems() {
+		ids = append(ids, item.BlockID)
+	}
+	assert.Equal(t, []string{"1a", "2a", "4a"}, ids)
+	assert.Equal(t, "1a", cache.kv["2a"].ParentID)
+}
+
+func TestModulesOutputCache_CoveredRange(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	caches := NewModuleOutputCache(10)
+	cache := NewOutputCache("mod", store, 10)
+	caches.OutputCaches["mod"] = cache
+	ctx := context.Background()
+
+	_, err := cache.Load(ctx, 0)
+	require.NoError(t, err)
+
+	// Request starting in the middle of the range, stopping past it
+	for num := uint64(4); num <= 12; num++ {
+		ref := bstream.NewBlockRef(fmt.Sprintf("%d", num), num)
+		require.NoError(t, caches.Update(ctx, ref, fmt.Sprintf("%d", num-1), true))
+		if num == 11 {
+			assert.Equal(t, block.NewRange(10, 11), cache.CoveredRange())
+		}
+	}
+	require.NoError(t, caches.Flush(ctx, true))
+
+	reloaded := NewOutputCache("mod", store, 10)
+	_, err = reloaded.Load(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, block.NewRange(4, 10), reloaded.CoveredRange())
+
+	_, err = reloaded.Load(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, block.NewRange(10, 12), reloaded.CoveredRange(), "stop block itself is not processed")
+}
+
+func TestModulesOutputCache_FlushReturnsUploadErrors(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	store.WriteObjectFunc = func(ctx context.Context, base string, f io.Reader) error {
+		return fmt.Errorf("disk full")
+	}
+
+	caches := NewModuleOutputCache(10)
+	cache := NewOutputCache("mod", store, 10)
+	cache.kv = outputKV{}
+	cache.CurrentBlockRange = block.NewRange(0, 10)
+	caches.OutputCaches["mod"] = cache
+
+	require.NoError(t, cache.Set(&pbsubstreams.Clock{Number: 1, Id: "1"}, []byte("data")))
+
+	err := caches.Flush(context.Background(), true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+
+	err = caches.Update(context.Background(), bstream.NewBlockRef("2", 2), "1", true)
+	assert.NoError(t, err, "failed uploads are only reported once")
+}
+
+func TestModulesOutputCache_UpdateReportsUploadErrorsOnce(t *testing.T) {
+	ctx := context.Background()
+	store := dstore.NewMockStore(nil)
+	store.WriteObjectFunc = func(ctx context.Context, base string, f io.Reader) error {
+		if base == computeDBinFilename(0, 10) {
+			return fmt.Errorf("transient failure")
+		}
+		return nil
+	}
+
+	caches := NewModuleOutputCache(10)
+	cache := NewOutputCache("mod", store, 10)
+	caches.OutputCaches["mod"] = cache
+	_, err := cache.Load(ctx, 0)
+	require.NoError(t, err)
+
+	var errs []error
+	for num := uint64(1); num <= 25; num++ {
+		ref := bstream.NewBlockRef(fmt.Sprintf("%d", num), num)
+		if err := caches.Update(ctx, ref, fmt.Sprintf("%d", num-1), true); err != nil {
+			errs = append(errs, err)
+		}
+		cache.uploads.Wait() // so the failure is reported on the next update
+	}
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "transient failure")
+	require.NoError(t, caches.Flush(ctx, true))
+}
@@ -0,0 +1,227 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/streamingfast/bstream"
+	"github.com/streamingfast/derr"
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/block"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"go.uber.org/zap"
+)
+
+// ExtensionCall is the output of a cacheable wasm extension call.
+type ExtensionCall struct {
+	BlockNum uint64 `json:"block_num"`
+	BlockID  string `json:"block_id"`
+	Key      string `json:"key"`
+	Output   []byte `json:"output"`
+}
+
+// ExtensionCallCache persists the outputs of the cacheable wasm extension
+// calls of a module, in one file per range of `saveBlockInterval` blocks,
+// so reprocessing a range gets the same outputs without calling the
+// extensions again. It implements `wasm.ExtensionCallCache`, for one
+// module executed on increasing blocks.
+//
+// Only complete ranges are saved, and files are never rewritten: a range
+// already in the store is only read, calls added to it are not saved.
+type ExtensionCallCache struct {
+	ModuleName        string
+	Store             dstore.Store
+	saveBlockInterval uint64
+
+	currentRange *block.Range // nil until the first call
+	calls        map[string]*ExtensionCall
+	dirty        bool // calls were added since the range was loaded
+	existing     bool // the range was loaded from the store
+
+	started       bool   // Update was called
+	firstBlockNum uint64 // first block passed to Update
+}
+
+func NewExtensionCallCache(moduleName string, store dstore.Store, saveBlockInterval uint64) *ExtensionCallCache {
+	return &ExtensionCallCache{
+		ModuleName:        moduleName,
+		Store:             store,
+		saveBlockInterval: saveBlockInterval,
+	}
+}
+
+// ExtensionCallStore returns the store holding the extension call caches
+// of a module hash.
+func ExtensionCallStore(baseStore dstore.Store, moduleHash string) (dstore.Store, error) {
+	return baseStore.SubStore(fmt.Sprintf("%s/extensions", moduleHash))
+}
+
+func (c *ExtensionCallCache) Get(ctx context.Context, clock *pbsubstreams.Clock, key string) ([]byte, bool, error) {
+	if err := c.moveTo(ctx, bstream.NewBlockRef(clock.Id, clock.Number)); err != nil {
+		return nil, false, err
+	}
+
+	call, found := c.calls[key]
+	if !found {
+		return nil, false, nil
+	}
+	return call.Output, true, nil
+}
+
+func (c *ExtensionCallCache) Set(ctx context.Context, clock *pbsubstreams.Clock, key string, out []byte) error {
+	if err := c.moveTo(ctx, bstream.NewBlockRef(clock.Id, clock.Number)); err != nil {
+		return err
+	}
+
+	c.calls[key] = &ExtensionCall{
+		BlockNum: clock.Number,
+		BlockID:  clock.Id,
+		Key:      key,
+		Output:   out,
+	}
+	c.dirty = true
+	return nil
+}
+
+// Update saves the current range once `blockNum` is past it, when all
+// its blocks were processed, so each range is saved as soon as it is
+// complete. Ranges partially processed, before the first block passed to
+// Update or up to a stop block, are never saved: a file only partially
+// covering its range would be read as is by all the following requests.
+func (c *ExtensionCallCache) Update(ctx context.Context, blockNum uint64) error {
+	if !c.started {
+		c.started = true
+		c.firstBlockNum = blockNum
+	}
+
+	if c.currentRange == nil || blockNum < c.currentRange.ExclusiveEndBlock {
+		return nil
+	}
+
+	if c.dirty && !c.existing && c.complete() {
+		if err := c.save(ctx); err != nil {
+			return err
+		}
+	}
+	c.currentRange = nil
+	c.calls = nil
+	c.dirty = false
+	return nil
+}
+
+// complete returns whether all the blocks of the current range were
+// processed, blocks being processed in order from the first block
+// passed to Update.
+func (c *ExtensionCallCache) complete() bool {
+	rangeStart := c.currentRange.StartBlock
+	if firstStreamable := bstream.GetProtocolFirstStreamableBlock; rangeStart < firstStreamable {
+		rangeStart = firstStreamable
+	}
+	return c.started && c.firstBlockNum <= rangeStart
+}
+
+// moveTo loads the range of `blockRef`. The calls of the current range,
+// if any, are dropped: Update saves the ranges once they are complete.
+func (c *ExtensionCallCache) moveTo(ctx context.Context, blockRef bstream.BlockRef) error {
+	if c.currentRange != nil && c.currentRange.Contains(blockRef) {
+		return nil
+	}
+
+	start := ComputeStartBlock(blockRef.Num(), c.saveBlockInterval)
+	c.currentRange = block.NewRange(start, start+c.saveBlockInterval)
+	c.calls = map[string]*ExtensionCall{}
+	c.dirty = false
+	c.existing = false
+	return c.load(ctx)
+}
+
+func (c *ExtensionCallCache) filename() string {
+	return computeExtensionCallsFilename(c.currentRange.StartBlock, c.currentRange.ExclusiveEndBlock)
+}
+
+func (c *ExtensionCallCache) load(ctx context.Context) error {
+	filename := c.filename()
+
+	var cnt []byte
+	err := derr.RetryContext(ctx, 3, func(ctx context.Context) error {
+		exists, err := c.Store.FileExists(ctx, filename)
+		if err != nil {
+			return fmt.Errorf("checking file %s: %w", filename, err)
+		}
+		if !exists {
+			return nil
+		}
+
+		reader, err := c.Store.OpenObject(ctx, filename)
+		if err != nil {
+			return fmt.Errorf("opening file %s: %w", filename, err)
+		}
+		defer reader.Close()
+
+		if cnt, err = io.ReadAll(reader); err != nil {
+			return fmt.Errorf("reading file %s: %w", filename, err)
+		}
+		return nil
+	})
+	if err != nil || cnt == nil {
+		return err
+	}
+	c.existing = true
+
+	var calls []*ExtensionCall
+	if err := json.Unmarshal(cnt, &calls); err != nil {
+		return fmt.Errorf("decoding file %s: %w", filename, err)
+	}
+	for _, call := range calls {
+		c.calls[call.Key] = call
+	}
+
+	zlog.Debug("extension calls loaded", zap.String("module_name", c.ModuleName), zap.Int("call_count", len(calls)), zap.Stringer("block_range", c.currentRange))
+	return nil
+}
+
+func (c *ExtensionCallCache) save(ctx context.Context) error {
+	filename := c.filename()
+
+	calls := make([]*ExtensionCall, 0, len(c.calls))
+	for _, call := range c.calls {
+		calls = append(calls, call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].BlockNum != calls[j].BlockNum {
+			return calls[i].BlockNum < calls[j].BlockNum
+		}
+		return calls[i].Key < calls[j].Key
+	})
+
+	cnt, err := json.Marshal(calls)
+	if err != nil {
+		return fmt.Errorf("encoding extension calls: %w", err)
+	}
+
+	zlog.Info("saving extension calls", zap.String("module_name", c.ModuleName), zap.Stringer("block_range", c.currentRange), zap.Int("call_count", len(calls)))
+	return derr.RetryContext(ctx, 3, func(ctx context.Context) error {
+		// Stores may not overwrite files, and silently skip the write
+		exists, err := c.Store.FileExists(ctx, filename)
+		if err != nil {
+			return fmt.Errorf("checking file %s: %w", filename, err)
+		}
+		if exists {
+			zlog.Info("extension calls already saved by another request", zap.String("module_name", c.ModuleName), zap.String("filename", filename))
+			return nil
+		}
+
+		if err := c.Store.WriteObject(ctx, filename, bytes.NewReader(cnt)); err != nil {
+			return fmt.Errorf("writing %s: %w", filename, err)
+		}
+		return nil
+	})
+}
+
+func computeExtensionCallsFilename(startBlock, stopBlock uint64) string {
+	return fmt.Sprintf("%s-%s.calls.json", pad(startBlock), pad(stopBlock))
+}
@@ -0,0 +1,191 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/streamingfast/bstream"
+	"github.com/streamingfast/dstore"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionCallCache(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	ctx := context.Background()
+
+	cache := NewExtensionCallCache("mod", store, 10)
+	require.NoError(t, cache.Update(ctx, 0))
+	require.NoError(t, cache.Set(ctx, &pbsubstreams.Clock{Number: 3, Id: "3a"}, "k3", []byte("out3")))
+	require.NoError(t, cache.Update(ctx, 10)) // saves 0-10
+	require.NoError(t, cache.Set(ctx, &pbsubstreams.Clock{Number: 12, Id: "12a"}, "k12", []byte("out12")))
+	require.NoError(t, cache.Update(ctx, 20)) // saves 10-20
+
+	exists, err := store.FileExists(ctx, "0000000000-0000000010.calls.json")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = store.FileExists(ctx, "0000000010-0000000020.calls.json")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	reloaded := NewExtensionCallCache("mod", store, 10)
+	out, found, err := reloaded.Get(ctx, &pbsubstreams.Clock{Number: 3, Id: "3a"}, "k3")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("out3"), out)
+
+	_, found, err = reloaded.Get(ctx, &pbsubstreams.Clock{Number: 4, Id: "4a"}, "k12")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	out, found, err = reloaded.Get(ctx, &pbsubstreams.Clock{Number: 12, Id: "12a"}, "k12")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("out12"), out)
+}
+
+func TestExtensionCallCache_UnchangedNotSaved(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	ctx := context.Background()
+
+	cache := NewExtensionCallCache("mod", store, 10)
+	require.NoError(t, cache.Update(ctx, 0))
+	_, found, err := cache.Get(ctx, &pbsubstreams.Clock{Number: 3, Id: "3a"}, "k3")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, cache.Update(ctx, 10))
+	exists, err := store.FileExists(ctx, "0000000000-0000000010.calls.json")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestExtensionCallCache_SavedOnUpdate(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	ctx := context.Background()
+
+	cache := NewExtensionCallCache("mod", store, 10)
+	require.NoError(t, cache.Update(ctx, 0))
+	require.NoError(t, cache.Set(ctx, &pbsubstreams.Clock{Number: 3, Id: "3a"}, "k3", []byte("out3")))
+
+	require.NoError(t, cache.Update(ctx, 9))
+	exists, err := store.FileExists(ctx, "0000000000-0000000010.calls.json")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, cache.Update(ctx, 10))
+	exists, err = store.FileExists(ctx, "0000000000-0000000010.calls.json")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestExtensionCallCache_StartedMidRangeNotSaved(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	ctx := context.Background()
+
+	cache := NewExtensionCallCache("mod", store, 10)
+	require.NoError(t, cache.Update(ctx, 5))
+	require.NoError(t, cache.Set(ctx, &pbsubstreams.Clock{Number: 7, Id: "7a"}, "k7", []byte("out7")))
+	require.NoError(t, cache.Update(ctx, 10))
+	require.NoError(t, cache.Set(ctx, &pbsubstreams.Clock{Number: 12, Id: "12a"}, "k12", []byte("out12")))
+	require.NoError(t, cache.Update(ctx, 20))
+
+	exists, err := store.FileExists(ctx, "0000000000-0000000010.calls.json")
+	require.NoError(t, err)
+	assert.False(t, exists, "blocks 0 to 4 were not processed")
+	exists, err = store.FileExists(ctx, "0000000010-0000000020.calls.json")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestExtensionCallCache_StoppedMidRangeThenReprocessed(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	caches := NewModuleOutputCache(10)
+	ctx := context.Background()
+
+	process := func(stopBlock uint64, calls map[uint64]string) {
+		caches.ExtensionCallCaches["mod"] = NewExtensionCallCache("mod", store, 10)
+		for num := uint64(0); num <= stopBlock; num++ {
+			ref := bstream.NewBlockRef(fmt.Sprintf("%da", num), num)
+			require.NoError(t, caches.Update(ctx, ref, fmt.Sprintf("%da", num-1), true))
+			if num == stopBlock {
+				break
+			}
+
+			clock := &pbsubstreams.Clock{Number: num, Id: ref.ID()}
+			key, found := calls[num]
+			if !found {
+				continue
+			}
+			_, found, err := caches.ExtensionCallCaches["mod"].Get(ctx, clock, key)
+			require.NoError(t, err)
+			if !found {
+				require.NoError(t, caches.ExtensionCallCaches["mod"].Set(ctx, clock, key, []byte("out"+key)))
+			}
+		}
+		require.NoError(t, caches.Flush(ctx, true))
+	}
+
+	process(5, map[uint64]string{3: "k3"})
+	exists, err := store.FileExists(ctx, "0000000000-0000000010.calls.json")
+	require.NoError(t, err)
+	assert.False(t, exists, "range stopped at block 5 is not complete")
+
+	process(10, map[uint64]string{3: "k3", 7: "k7"})
+	var calls []*ExtensionCall
+	require.NoError(t, json.Unmarshal(readTestFile(t, store, "0000000000-0000000010.calls.json"), &calls))
+	var keys []string
+	for _, call := range calls {
+		keys = append(keys, call.Key)
+	}
+	assert.Equal(t, []string{"k3", "k7"}, keys)
+}
+
+func TestExtensionCallCache_ExistingRangeNotRewritten(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	ctx := context.Background()
+
+	cache := NewExtensionCallCache("mod", store, 10)
+	require.NoError(t, cache.Update(ctx, 0))
+	require.NoError(t, cache.Set(ctx, &pbsubstreams.Clock{Number: 3, Id: "3a"}, "k3", []byte("out3")))
+	require.NoError(t, cache.Update(ctx, 10))
+	saved := readTestFile(t, store, "0000000000-0000000010.calls.json")
+
+	reloaded := NewExtensionCallCache("mod", store, 10)
+	require.NoError(t, reloaded.Update(ctx, 0))
+	require.NoError(t, reloaded.Set(ctx, &pbsubstreams.Clock{Number: 4, Id: "4a"}, "k4", []byte("out4")))
+	require.NoError(t, reloaded.Update(ctx, 10))
+	assert.Equal(t, saved, readTestFile(t, store, "0000000000-0000000010.calls.json"))
+}
+
+func TestExtensionCallCache_SavedConcurrently(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	ctx := context.Background()
+
+	cache := NewExtensionCallCache("mod", store, 10)
+	require.NoError(t, cache.Update(ctx, 0))
+	require.NoError(t, cache.Set(ctx, &pbsubstreams.Clock{Number: 3, Id: "3a"}, "k3", []byte("out3")))
+
+	// Another request saves the range first
+	require.NoError(t, store.WriteObject(ctx, "0000000000-0000000010.calls.json", bytes.NewReader([]byte("[]"))))
+
+	require.NoError(t, cache.Update(ctx, 10))
+	assert.Equal(t, []byte("[]"), readTestFile(t, store, "0000000000-0000000010.calls.json"))
+}
+
+func readTestFile(t *testing.T, store dstore.Store, filename string) []byte {
+	t.Helper()
+
+	reader, err := store.OpenObject(context.Background(), filename)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	cnt, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return cnt
+}
@@ -0,0 +1,245 @@
+package outputs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/streamingfast/substreams/block"
+)
+
+// Output cache files are laid out as:
+//
+//   magic (4 bytes) | version (1 byte) |
+//   covered start block (uvarint) | covered end block (uvarint) |
+//   item count (uvarint)
+//   index, one entry per item, ordered by block number:
+//     block num (uvarint) | block id length (uvarint) | block id |
+//     parent id length (uvarint) | parent id |
+//     block timestamp, unix nanoseconds (uvarint) |
+//     payload offset (uvarint) | payload length (uvarint)
+//   payloads, each compressed separately with zstd
+//
+// The index allows seeking to a given block without decompressing
+// the rest of the file. The covered range holds the blocks that were
+// all processed when the file was written, blocks without output
+// included; a covered end block of 0 means the coverage is unknown.
+// Files not starting with the magic bytes are
+// legacy JSON-encoded `outputKV` maps.
+
+var outputFileMagic = []byte("SSOC")
+
+const outputFileVersion = 1
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func isLegacyOutputFile(cnt []byte) bool {
+	return !bytes.HasPrefix(cnt, outputFileMagic)
+}
+
+func encodeOutputFile(kv outputKV, covered *block.Range) []byte {
+	items := make([]*CacheItem, 0, len(kv))
+	for _, item := range kv {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].BlockNum == items[j].BlockNum {
+			return items[i].BlockID < items[j].BlockID
+		}
+		return items[i].BlockNum < items[j].BlockNum
+	})
+
+	var payloads []byte
+	index := make([]byte, 0, len(items)*40)
+	if covered != nil {
+		index = appendUvarint(index, covered.StartBlock)
+		index = appendUvarint(index, covered.ExclusiveEndBlock)
+	} else {
+		index = appendUvarint(index, 0)
+		index = appendUvarint(index, 0)
+	}
+	index = appendUvarint(index, uint64(len(items)))
+	for _, item := range items {
+		offset := len(payloads)
+		payloads = zstdEncoder.EncodeAll(item.Payload, payloads)
+
+		index = appendUvarint(index, item.BlockNum)
+		index = appendUvarint(index, uint64(len(item.BlockID)))
+		index = append(index, item.BlockID...)
+		index = appendUvarint(index, uint64(len(item.ParentID)))
+		index = append(index, item.ParentID...)
+		index = appendUvarint(index, timestampToUvarint(item.Timestamp))
+		index = appendUvarint(index, uint64(offset))
+		index = appendUvarint(index, uint64(len(payloads)-offset))
+	}
+
+	out := make([]byte, 0, len(outputFileMagic)+1+len(index)+len(payloads))
+	out = append(out, outputFileMagic...)
+	out = append(out, outputFileVersion)
+	out = append(out, index...)
+	return append(out, payloads...)
+}
+
+type outputFileEntry struct {
+	blockNum  uint64
+	blockID   string
+	parentID  string
+	timestamp time.Time
+	offset    uint64
+	length    uint64
+}
+
+// outputFile gives access to the items of an encoded output cache
+// file, decompressing payloads only when requested.
+type outputFile struct {
+	covered  *block.Range       // nil when unknown
+	entries  []*outputFileEntry // ordered by block number
+	payloads []byte
+}
+
+func parseOutputFile(cnt []byte) (*outputFile, error) {
+	if isLegacyOutputFile(cnt) {
+		return nil, fmt.Errorf("not a binary output file")
+	}
+	cnt = cnt[len(outputFileMagic):]
+	if len(cnt) == 0 {
+		return nil, fmt.Errorf("missing version")
+	}
+	version := cnt[0]
+	if version != outputFileVersion {
+		return nil, fmt.Errorf("unsupported output file version %d", version)
+	}
+
+	r := &byteReader{buf: cnt[1:]}
+	f := &outputFile{}
+	start, end := r.uvarint(), r.uvarint()
+	if end > start {
+		f.covered = block.NewRange(start, end)
+	}
+	count := r.uvarint()
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		entry := &outputFileEntry{blockNum: r.uvarint()}
+		entry.blockID = string(r.bytes(r.uvarint()))
+		entry.parentID = string(r.bytes(r.uvarint()))
+		entry.timestamp = timestampFromUvarint(r.uvarint())
+		entry.offset = r.uvarint()
+		entry.length = r.uvarint()
+		f.entries = append(f.entries, entry)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("reading index: %w", r.err)
+	}
+
+	f.payloads = r.buf
+	for _, entry := range f.entries {
+		if entry.offset+entry.length > uint64(len(f.payloads)) {
+			return nil, fmt.Errorf("payload of block %d out of bounds, file truncated", entry.blockNum)
+		}
+	}
+	return f, nil
+}
+
+func (f *outputFile) Len() int { return len(f.entries) }
+
+// Item decodes the i-th item, in block order.
+func (f *outputFile) Item(i int) (*CacheItem, error) {
+	entry := f.entries[i]
+	payload, err := zstdDecoder.DecodeAll(f.payloads[entry.offset:entry.offset+entry.length], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing payload of block %d: %w", entry.blockNum, err)
+	}
+	return &CacheItem{BlockNum: entry.blockNum, BlockID: entry.blockID, ParentID: entry.parentID, Timestamp: entry.timestamp, Payload: payload}, nil
+}
+
+// Search returns the index of the first item at or after `blockNum`.
+func (f *outputFile) Search(blockNum uint64) int {
+	return sort.Search(len(f.entries), func(i int) bool {
+		return f.entries[i].blockNum >= blockNum
+	})
+}
+
+// decodeOutputFile returns the items of a file, and the range of
+// blocks it covers, nil when unknown.
+func decodeOutputFile(cnt []byte) (outputKV, *block.Range, error) {
+	kv := outputKV{}
+	if isLegacyOutputFile(cnt) {
+		if err := json.Unmarshal(cnt, &kv); err != nil {
+			return nil, nil, fmt.Errorf("json decoding legacy file: %w", err)
+		}
+		return kv, nil, nil
+	}
+
+	f, err := parseOutputFile(cnt)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := 0; i < f.Len(); i++ {
+		item, err := f.Item(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		kv[item.BlockID] = item
+	}
+	return kv, f.covered, nil
+}
+
+var errTruncated = errors.New("unexpected end of data")
+
+type byteReader struct {
+	buf []byte
+	err error
+}
+
+func (r *byteReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *byteReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.buf)) < n {
+		r.err = errTruncated
+		return nil
+	}
+	out := r.buf[:n]
+	r.buf = r.buf[n:]
+	return out
+}
+
+func timestampToUvarint(t time.Time) uint64 {
+	if t.IsZero() || t.UnixNano() < 0 {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
+
+func timestampFromUvarint(v uint64) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(v)).UTC()
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
@@ -0,0 +1,60 @@
+package outputs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/streamingfast/substreams/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFile_RoundTrip(t *testing.T) {
+	kv := outputKV{
+		"c": {BlockNum: 12, BlockID: "c", ParentID: "b", Timestamp: time.Unix(1650000000, 0).UTC(), Payload: []byte("third")},
+		"a": {BlockNum: 10, BlockID: "a", Payload: []byte("first")},
+		"b": {BlockNum: 11, BlockID: "b", Payload: nil},
+	}
+
+	cnt := encodeOutputFile(kv, block.NewRange(10, 20))
+	assert.False(t, isLegacyOutputFile(cnt))
+
+	decoded, covered, err := decodeOutputFile(cnt)
+	require.NoError(t, err)
+	assert.Equal(t, block.NewRange(10, 20), covered)
+	require.Len(t, decoded, 3)
+	assert.Equal(t, []byte("first"), decoded["a"].Payload)
+	assert.Len(t, decoded["b"].Payload, 0)
+	assert.Equal(t, uint64(12), decoded["c"].BlockNum)
+
+	f, err := parseOutputFile(cnt)
+	require.NoError(t, err)
+	idx := f.Search(11)
+	assert.Equal(t, 1, idx)
+	item, err := f.Item(idx + 1)
+	require.NoError(t, err)
+	assert.Equal(t, &CacheItem{BlockNum: 12, BlockID: "c", ParentID: "b", Timestamp: time.Unix(1650000000, 0).UTC(), Payload: []byte("third")}, item)
+
+	_, covered, err = decodeOutputFile(encodeOutputFile(kv, nil))
+	require.NoError(t, err)
+	assert.Nil(t, covered)
+}
+
+func TestOutputFile_Legacy(t *testing.T) {
+	cnt := []byte(`{"a":{"block_num":10,"BlockID":"a","payload":"Zmlyc3Q="}}`)
+
+	decoded, _, err := decodeOutputFile(cnt)
+	require.NoError(t, err)
+	assert.Equal(t, &CacheItem{BlockNum: 10, BlockID: "a", Payload: []byte("first")}, decoded["a"])
+}
+
+func TestOutputFile_Corrupted(t *testing.T) {
+	cnt := encodeOutputFile(outputKV{"a": {BlockNum: 10, BlockID: "a", Payload: []byte("first")}}, nil)
+
+	_, _, err := decodeOutputFile(cnt[:len(cnt)-3])
+	assert.Error(t, err)
+
+	cnt[len(outputFileMagic)] = 42
+	_, _, err = decodeOutputFile(cnt)
+	assert.EqualError(t, err, "unsupported output file version 42")
+}
@@ -0,0 +1,243 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/block"
+	"go.uber.org/zap"
+)
+
+// AccessFileName is written in the outputs folder of each module hash,
+// recording the last time a request used these caches. Prefixed with
+// `__` so it never matches a block range.
+const AccessFileName = "__access.json"
+
+type AccessInfo struct {
+	LastAccessed time.Time `json:"last_accessed"`
+}
+
+// RecordAccess writes the access file of a module outputs store, which
+// must allow overwrites to update it.
+func RecordAccess(ctx context.Context, moduleStore dstore.Store, now time.Time) error {
+	content, err := json.Marshal(&AccessInfo{LastAccessed: now.UTC()})
+	if err != nil {
+		return fmt.Errorf("marshal access info: %w", err)
+	}
+	return moduleStore.WriteObject(ctx, AccessFileName, bytes.NewReader(content))
+}
+
+// accessRecordInterval is how often a process updates the access file
+// of a module hash, a precision of about an hour is plenty to find the
+// caches unused for days.
+const accessRecordInterval = time.Hour
+
+type accessRecorder struct {
+	sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time // by module hash
+}
+
+var moduleAccessRecorder = &accessRecorder{interval: accessRecordInterval, last: map[string]time.Time{}}
+
+func (r *accessRecorder) shouldRecord(hash string, now time.Time) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	if last, found := r.last[hash]; found && now.Sub(last) < r.interval {
+		return false
+	}
+	r.last[hash] = now
+	return true
+}
+
+// recordAccessInBackground records the access of the outputs of module
+// `hash`, at most once per `accessRecordInterval`, without holding the
+// request up.
+func recordAccessInBackground(baseCacheStore dstore.Store, hash string, now time.Time) {
+	if !moduleAccessRecorder.shouldRecord(hash, now) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		// A store of its own, the overwrite setting is not shared with the caches
+		moduleStore, err := ModuleStore(baseCacheStore, hash)
+		if err != nil {
+			zlog.Warn("recording output cache access", zap.String("module_hash", hash), zap.Error(err))
+			return
+		}
+		moduleStore.SetOverwrite(true)
+
+		if err := RecordAccess(ctx, moduleStore, now); err != nil {
+			zlog.Warn("recording output cache access", zap.String("module_hash", hash), zap.Error(err))
+		}
+	}()
+}
+
+// ReadAccess returns nil when the module outputs were never accessed
+// since access recording was introduced.
+func ReadAccess(ctx context.Context, moduleStore dstore.Store) (*AccessInfo, error) {
+	exists, err := moduleStore.FileExists(ctx, AccessFileName)
+	if err != nil {
+		return nil, fmt.Errorf("checking access file: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	reader, err := moduleStore.OpenObject(ctx, AccessFileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening access file: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading access file: %w", err)
+	}
+
+	info := &AccessInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("unmarshal access info: %w", err)
+	}
+	return info, nil
+}
+
+// CacheFile is an output cache file found in the base state store.
+type CacheFile struct {
+	ModuleHash string
+	Range      *block.Range
+	Filename   string // relative to the module outputs store
+}
+
+var cacheFilePathRegex = regexp.MustCompile(`^([0-9a-f]+)/outputs/(\d+)-(\d+)\.output$`)
+var accessFilePathRegex = regexp.MustCompile(`^([0-9a-f]+)/outputs/` + regexp.QuoteMeta(AccessFileName) + `$`)
+
+// ModuleCaches holds the output cache files of a module hash.
+type ModuleCaches struct {
+	ModuleHash string
+	Files      []*CacheFile // ordered by start block
+	HasAccess  bool         // whether an access file was found
+}
+
+// Ranges merges the contiguous files into ranges.
+func (m *ModuleCaches) Ranges() (out block.Ranges) {
+	for _, file := range m.Files {
+		if len(out) != 0 && out[len(out)-1].ExclusiveEndBlock >= file.Range.StartBlock {
+			last := out[len(out)-1]
+			if file.Range.ExclusiveEndBlock > last.ExclusiveEndBlock {
+				last.ExclusiveEndBlock = file.Range.ExclusiveEndBlock
+			}
+			continue
+		}
+		out = append(out, block.NewRange(file.Range.StartBlock, file.Range.ExclusiveEndBlock))
+	}
+	return out
+}
+
+// Gaps returns the ranges missing between the first and the last file.
+func (m *ModuleCaches) Gaps() (out block.Ranges) {
+	ranges := m.Ranges()
+	for i := 1; i < len(ranges); i++ {
+		out = append(out, block.NewRange(ranges[i-1].ExclusiveEndBlock, ranges[i].StartBlock))
+	}
+	return out
+}
+
+// ListModuleCaches walks the base state store for the output cache
+// files of every module hash, ordered by module hash.
+func ListModuleCaches(ctx context.Context, baseStore dstore.Store) ([]*ModuleCaches, error) {
+	byHash := map[string]*ModuleCaches{}
+	get := func(hash string) *ModuleCaches {
+		if m, found := byHash[hash]; found {
+			return m
+		}
+		m := &ModuleCaches{ModuleHash: hash}
+		byHash[hash] = m
+		return m
+	}
+
+	err := baseStore.Walk(ctx, "", func(filename string) error {
+		if res := cacheFilePathRegex.FindStringSubmatch(filename); res != nil {
+			start, _ := strconv.ParseUint(res[2], 10, 64)
+			end, _ := strconv.ParseUint(res[3], 10, 64)
+			if end <= start {
+				zlog.Warn("skipping output file with invalid range", zap.String("filename", filename))
+				return nil
+			}
+			m := get(res[1])
+			m.Files = append(m.Files, &CacheFile{
+				ModuleHash: res[1],
+				Range:      block.NewRange(start, end),
+				Filename:   path.Base(filename),
+			})
+			return nil
+		}
+		if res := accessFilePathRegex.FindStringSubmatch(filename); res != nil {
+			get(res[1]).HasAccess = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking store: %w", err)
+	}
+
+	out := make([]*ModuleCaches, 0, len(byHash))
+	for _, m := range byHash {
+		sort.Slice(m.Files, func(i, j int) bool {
+			return m.Files[i].Range.StartBlock < m.Files[j].Range.StartBlock
+		})
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ModuleHash < out[j].ModuleHash
+	})
+	return out, nil
+}
+
+// ModuleStore returns the store holding the output caches of a module hash.
+func ModuleStore(baseStore dstore.Store, moduleHash string) (dstore.Store, error) {
+	return baseStore.SubStore(fmt.Sprintf("%s/outputs", moduleHash))
+}
+
+// ReadCacheFile returns the items of an output cache file, ordered by
+// block number, and the range of blocks it covers, nil when unknown.
+func ReadCacheFile(ctx context.Context, moduleStore dstore.Store, filename string) ([]*CacheItem, *block.Range, error) {
+	reader, err := moduleStore.OpenObject(ctx, filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening file %s: %w", filename, err)
+	}
+	defer reader.Close()
+
+	cnt, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading file %s: %w", filename, err)
+	}
+
+	kv, covered, err := decodeOutputFile(cnt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding file %s: %w", filename, err)
+	}
+
+	items := make([]*CacheItem, 0, len(kv))
+	for _, item := range kv {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].BlockNum < items[j].BlockNum
+	})
+	return items, covered, nil
+}
@@ -0,0 +1,76 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListModuleCaches(t *testing.T) {
+	ctx := context.Background()
+	// Local store, as mock stores do not share files with their substores
+	store, err := dstore.NewStore(t.TempDir(), "", "", false)
+	require.NoError(t, err)
+	for _, filename := range []string{
+		"abc/outputs/0000000000-0000000010.output",
+		"abc/outputs/0000000010-0000000020.output",
+		"abc/outputs/0000000040-0000000050.output",
+		"abc/states/0000000000-0000000010.kv",
+		"def/outputs/0000000000-0000000010.output",
+	} {
+		require.NoError(t, store.WriteObject(ctx, filename, bytes.NewReader([]byte("x"))))
+	}
+
+	moduleStore, err := ModuleStore(store, "def")
+	require.NoError(t, err)
+	accessedAt := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, RecordAccess(ctx, moduleStore, accessedAt))
+
+	caches, err := ListModuleCaches(ctx, store)
+	require.NoError(t, err)
+	require.Len(t, caches, 2)
+
+	abc := caches[0]
+	assert.Equal(t, "abc", abc.ModuleHash)
+	assert.Len(t, abc.Files, 3)
+	assert.False(t, abc.HasAccess)
+	assert.Equal(t, block.Ranges{block.NewRange(0, 20), block.NewRange(40, 50)}, abc.Ranges())
+	assert.Equal(t, block.Ranges{block.NewRange(20, 40)}, abc.Gaps())
+
+	def := caches[1]
+	assert.True(t, def.HasAccess)
+	assert.Nil(t, def.Gaps())
+	access, err := ReadAccess(ctx, moduleStore)
+	require.NoError(t, err)
+	assert.Equal(t, accessedAt, access.LastAccessed)
+}
+
+func TestAccessRecorder_ShouldRecord(t *testing.T) {
+	recorder := &accessRecorder{interval: time.Hour, last: map[string]time.Time{}}
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, recorder.shouldRecord("abc", now))
+	assert.False(t, recorder.shouldRecord("abc", now.Add(59*time.Minute)))
+	assert.True(t, recorder.shouldRecord("def", now.Add(59*time.Minute)))
+	assert.True(t, recorder.shouldRecord("abc", now.Add(time.Hour)))
+}
+
+func TestRecordAccess_Overwrites(t *testing.T) {
+	ctx := context.Background()
+	store, err := dstore.NewStore(t.TempDir(), "", "", true)
+	require.NoError(t, err)
+
+	first := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, RecordAccess(ctx, store, first))
+	require.NoError(t, RecordAccess(ctx, store, first.Add(time.Hour)))
+
+	access, err := ReadAccess(ctx, store)
+	require.NoError(t, err)
+	assert.Equal(t, first.Add(time.Hour), access.LastAccessed)
+}
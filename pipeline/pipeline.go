@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/streamingfast/bstream"
 	"github.com/streamingfast/dstore"
@@ -24,6 +26,8 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const throughputEmitInterval = time.Second
+
 type Pipeline struct {
 	vmType    string // wasm/rust-v1, native
 	blockType string
@@ -38,6 +42,9 @@ type Pipeline struct {
 
 	wasmRuntime    *wasm.Runtime
 	wasmExtensions []wasm.WASMExtensioner
+	wasmOptions    []wasm.RuntimeOption
+
+	cacheExtensionCalls bool // of cacheable wasm extensions, in the state store
 
 	context  context.Context
 	request  *pbsubstreams.Request
@@ -54,8 +61,9 @@ type Pipeline struct {
 	wasmOutputs           map[string][]byte
 	nextStoreSaveBoundary uint64 // The next expected block at which we should flush stores (at save interval)
 
-	baseStateStore    dstore.Store
-	storeSaveInterval uint64
+	baseStateStore          dstore.Store
+	storeSaveInterval       uint64
+	storeCheckpointInterval uint64
 
 	clock         *pbsubstreams.Clock
 	moduleOutputs []*pbsubstreams.ModuleOutput
@@ -67,8 +75,13 @@ type Pipeline struct {
 
 	currentBlockRef bstream.BlockRef
 
+	throughput *substreams.ThroughputTracker
+
 	outputCacheSaveBlockInterval uint64
+	outputCacheReplay            bool
+	fullyReplayed                bool
 	subrequestSplitSize          int
+	subrequestRetryPolicy        *orchestrator.RetryPolicy
 	grpcClientFactory            func() (pbsubstreams.StreamClient, []grpc.CallOption, error)
 }
 
@@ -144,6 +157,25 @@ func (p *Pipeline) Init(workerPool *orchestrator.WorkerPool) (err error) {
 		}
 	}
 
+	if p.shouldReplayOutputCaches() {
+		resumeBlockNum, err := p.replayOutputCaches(ctx)
+		if err != nil {
+			return fmt.Errorf("replaying output caches: %w", err)
+		}
+		p.requestedStartBlockNum = resumeBlockNum
+		if p.requestedStartBlockNum >= p.request.StopBlockNum {
+			zlog.Info("requested range fully replayed from output caches")
+			p.fullyReplayed = true
+			return nil
+		}
+	}
+
+	var totalBlocks uint64
+	if p.request.StopBlockNum > p.requestedStartBlockNum {
+		totalBlocks = p.request.StopBlockNum - p.requestedStartBlockNum
+	}
+	p.throughput = substreams.NewThroughputTracker(totalBlocks)
+
 	zlog.Info("initializing and loading stores")
 	initialStoreMap, err := p.buildStoreMap()
 	zlog.Info("stores load", zap.Int("number_of_stores", len(initialStoreMap)))
@@ -218,6 +250,18 @@ func (p *Pipeline) Init(workerPool *orchestrator.WorkerPool) (err error) {
 	return nil
 }
 
+// StartBlockNum is the block at which the block source must start,
+// after the blocks replayed from output caches during Init.
+func (p *Pipeline) StartBlockNum() uint64 {
+	return p.requestedStartBlockNum
+}
+
+// FullyReplayed returns whether Init already sent everything up to the
+// stop block from output caches, in which case no block source is needed.
+func (p *Pipeline) FullyReplayed() bool {
+	return p.fullyReplayed
+}
+
 func (p *Pipeline) initStoreSaveBoundary() {
 	p.nextStoreSaveBoundary = p.computeNextStoreSaveBoundary(p.requestedStartBlockNum)
 }
@@ -261,7 +305,9 @@ func (p *Pipeline) ProcessBlock(block *bstream.Block, obj interface{}) (err erro
 	}
 	p.currentBlockRef = block.AsRef()
 
-	if err = p.moduleOutputCache.Update(ctx, p.currentBlockRef); err != nil {
+	// Only irreversible blocks are requested from the firehose for now
+	irreversible := obj.(bstream.Stepable).Step()&bstream.StepIrreversible != 0
+	if err = p.moduleOutputCache.Update(ctx, p.currentBlockRef, block.PreviousId, irreversible); err != nil {
 		return fmt.Errorf("updating module output cache: %w", err)
 	}
 
@@ -287,8 +333,8 @@ func (p *Pipeline) ProcessBlock(block *bstream.Block, obj interface{}) (err erro
 
 	if isStopBlockReached(blockNum, p.request.StopBlockNum) {
 		zlog.Debug("about to save cache output", zap.Uint64("clock", blockNum), zap.Uint64("stop_block", p.request.StopBlockNum))
-		if err := p.moduleOutputCache.Flush(ctx); err != nil {
-			return fmt.Errorf("saving partial caches")
+		if err := p.moduleOutputCache.Flush(ctx, irreversible); err != nil {
+			return fmt.Errorf("saving partial caches: %w", err)
 		}
 		return io.EOF
 	}
@@ -309,8 +355,13 @@ func (p *Pipeline) ProcessBlock(block *bstream.Block, obj interface{}) (err erro
 		}
 	}
 
+	if blockNum >= p.requestedStartBlockNum {
+		p.throughput.Set(blockNum - p.requestedStartBlockNum + 1)
+	}
+	emitThroughput := p.throughput.ShouldEmit(throughputEmitInterval)
+
 	if shouldReturnProgress(p.isSubrequest) {
-		if err := p.returnModuleProgressOutputs(); err != nil {
+		if err := p.returnModuleProgressOutputs(emitThroughput); err != nil {
 			return err
 		}
 	}
@@ -318,6 +369,11 @@ func (p *Pipeline) ProcessBlock(block *bstream.Block, obj interface{}) (err erro
 		if err := p.returnModuleDataOutputs(step, cursor); err != nil {
 			return err
 		}
+		if emitThroughput {
+			if err := p.returnThroughputProgress(); err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, s := range p.storeMap {
@@ -382,7 +438,10 @@ func isStopBlockReached(currentBlock uint64, stopBlock uint64) bool {
 	return currentBlock >= stopBlock
 }
 
-func (p *Pipeline) returnModuleProgressOutputs() error {
+// returnModuleProgressOutputs reports the processed ranges of the stores
+// of a subrequest. Their throughput is measured by the scheduler across
+// all of its jobs, not here.
+func (p *Pipeline) returnModuleProgressOutputs(withStats bool) error {
 	var progress []*pbsubstreams.ModuleProgress
 	for _, store := range p.backprocessingStores {
 		progress = append(progress, &pbsubstreams.ModuleProgress{
@@ -400,12 +459,51 @@ func (p *Pipeline) returnModuleProgressOutputs() error {
 		})
 	}
 
+	if withStats {
+		progress = append(progress, p.executionStatsProgress()...)
+	}
+
 	if err := p.respFunc(substreams.NewModulesProgressResponse(progress)); err != nil {
 		return fmt.Errorf("calling return func: %w", err)
 	}
 	return nil
 }
 
+// returnThroughputProgress reports the live processing rate of the
+// output modules, along with the time left to reach the stop block.
+func (p *Pipeline) returnThroughputProgress() error {
+	var progress []*pbsubstreams.ModuleProgress
+	for _, name := range p.request.OutputModules {
+		progress = append(progress, p.throughput.ProgressMessage(name))
+	}
+	progress = append(progress, p.executionStatsProgress()...)
+
+	if err := p.respFunc(substreams.NewModulesProgressResponse(progress)); err != nil {
+		return fmt.Errorf("calling return func: %w", err)
+	}
+	return nil
+}
+
+// executionStatsProgress reports the peak memory of every module
+// executed so far. It is sent along with the throughput progress.
+func (p *Pipeline) executionStatsProgress() (out []*pbsubstreams.ModuleProgress) {
+	for _, executor := range p.moduleExecutors {
+		peak := executor.peakMemoryBytes()
+		if peak == 0 {
+			continue
+		}
+		out = append(out, &pbsubstreams.ModuleProgress{
+			Name: executor.Name(),
+			Type: &pbsubstreams.ModuleProgress_ExecutionStats_{
+				ExecutionStats: &pbsubstreams.ModuleProgress_ExecutionStats{
+					PeakMemoryBytes: peak,
+				},
+			},
+		})
+	}
+	return out
+}
+
 func (p *Pipeline) returnModuleDataOutputs(step bstream.StepType, cursor *bstream.Cursor) error {
 	zlog.Debug("got modules outputs", zap.Int("module_output_count", len(p.moduleOutputs)))
 	out := &pbsubstreams.BlockScopedData{
@@ -425,10 +523,12 @@ func (p *Pipeline) returnModuleDataOutputs(step bstream.StepType, cursor *bstrea
 func (p *Pipeline) returnFailureProgress(err error, failedExecutor ModuleExecutor) error {
 	var out []*pbsubstreams.ModuleProgress
 
+	reported := false
 	for _, moduleOutput := range p.moduleOutputs {
 		var reason string
 		if moduleOutput.Name == failedExecutor.Name() {
 			reason = err.Error()
+			reported = true
 		}
 
 		// FIXME(abourget): eventually, would we also return the data for each of
@@ -447,6 +547,22 @@ func (p *Pipeline) returnFailureProgress(err error, failedExecutor ModuleExecuto
 		}
 	}
 
+	// The failed module is not always an output module, like when a
+	// store's dependency fails in a subrequest: report it anyway.
+	if !reported {
+		logs, truncated := failedExecutor.moduleLogs()
+		out = append(out, &pbsubstreams.ModuleProgress{
+			Name: failedExecutor.Name(),
+			Type: &pbsubstreams.ModuleProgress_Failed_{
+				Failed: &pbsubstreams.ModuleProgress_Failed{
+					Reason:        err.Error(),
+					Logs:          logs,
+					LogsTruncated: truncated,
+				},
+			},
+		})
+	}
+
 	return p.respFunc(substreams.NewModulesProgressResponse(out))
 }
 
@@ -506,7 +622,7 @@ func (p *Pipeline) buildModules() error {
 
 func (p *Pipeline) buildWASM(ctx context.Context, request *pbsubstreams.Request, modules []*pbsubstreams.Module) error {
 	p.wasmOutputs = map[string][]byte{}
-	p.wasmRuntime = wasm.NewRuntime(p.wasmExtensions)
+	p.wasmRuntime = wasm.NewRuntime(p.wasmExtensions, p.wasmOptions...)
 
 	for _, module := range modules {
 		isOutput := p.outputModuleMap[module.Name]
@@ -544,6 +660,12 @@ func (p *Pipeline) buildWASM(ctx context.Context, request *pbsubstreams.Request,
 					Type: wasm.InputSource,
 					Name: in.Source.Type,
 				})
+			case *pbsubstreams.Module_Input_Params_:
+				inputs = append(inputs, &wasm.Input{
+					Type:       wasm.InputParams,
+					Name:       "params",
+					StreamData: []byte(in.Params.Value),
+				})
 			default:
 				return fmt.Errorf("invalid input struct for module %q", module.Name)
 			}
@@ -556,6 +678,12 @@ func (p *Pipeline) buildWASM(ctx context.Context, request *pbsubstreams.Request,
 		if err != nil {
 			return fmt.Errorf("new wasm module: %w", err)
 		}
+		if module.ReuseInstance {
+			wasmModule.EnableInstanceReuse()
+		}
+		if p.cacheExtensionCalls {
+			wasmModule.SetExtensionCallCache(p.moduleOutputCache.ExtensionCallCaches[module.Name])
+		}
 
 		switch kind := module.Kind.(type) {
 		case *pbsubstreams.Module_KindMap_:
@@ -643,6 +771,7 @@ func (p *Pipeline) buildStoreMap() (storeMap map[string]*state.Store, err error)
 			storeModule.GetKindStore().UpdatePolicy,
 			storeModule.GetKindStore().ValueType,
 			p.baseStateStore,
+			state.WithPartialsOwner(partialsOwner()),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("creating builder %s: %w", storeModule.Name, err)
@@ -652,6 +781,13 @@ func (p *Pipeline) buildStoreMap() (storeMap map[string]*state.Store, err error)
 	return storeMap, nil
 }
 
+// partialsOwner identifies the process writing partials, to help
+// tracking down orphaned ones.
+func partialsOwner() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s/%d", hostname, os.Getpid())
+}
+
 func loadCompleteStores(ctx context.Context, storeMap map[string]*state.Store, requestedStartBlock uint64) error {
 	for _, store := range storeMap {
 		if store.StoreInitialBlock() == requestedStartBlock {
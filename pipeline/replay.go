@@ -0,0 +1,150 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/streamingfast/bstream"
+	"github.com/streamingfast/substreams"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/pipeline/outputs"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (p *Pipeline) shouldReplayOutputCaches() bool {
+	return p.outputCacheReplay &&
+		!p.isSubrequest &&
+		p.request.StopBlockNum > p.requestedStartBlockNum &&
+		p.request.StartCursor == "" &&
+		len(p.request.InitialStoreSnapshotForModules) == 0
+}
+
+// replayOutputCaches sends the outputs of the requested modules straight
+// from their output caches, without reading blocks, for as long as the
+// caches of all the output modules cover the requested range. It returns
+// the block at which normal processing must resume: the first block not
+// covered, or the stop block when everything was replayed.
+//
+// Only the blocks where at least one output module produced an output
+// are sent, and they carry no logs.
+func (p *Pipeline) replayOutputCaches(ctx context.Context) (resumeBlockNum uint64, err error) {
+	at := p.requestedStartBlockNum
+	stop := p.request.StopBlockNum
+
+	for at < stop {
+		end := stop
+		var items []*replayItem
+		for _, module := range p.modules {
+			if !p.isOutputModule(module.Name) {
+				continue
+			}
+
+			cache := outputs.NewOutputCache(module.Name, p.moduleOutputCache.OutputCaches[module.Name].Store, p.outputCacheSaveBlockInterval)
+			if _, err := cache.Load(ctx, outputs.ComputeStartBlock(at, p.outputCacheSaveBlockInterval)); err != nil {
+				return at, fmt.Errorf("loading outputs cache of module %q: %w", module.Name, err)
+			}
+
+			covered := cache.CoveredRange()
+			if covered == nil || covered.StartBlock > at || covered.ExclusiveEndBlock <= at {
+				zlog.Info("output cache gap, resuming normal processing", zap.String("module_name", module.Name), zap.Uint64("block_num", at))
+				return at, nil
+			}
+			if covered.ExclusiveEndBlock < end {
+				end = covered.ExclusiveEndBlock
+			}
+
+			for _, item := range cache.SortedCacheItems() {
+				items = append(items, &replayItem{module: module, item: item})
+			}
+		}
+
+		zlog.Debug("replaying outputs from cache", zap.Uint64("start_block", at), zap.Uint64("end_block", end))
+		if err := p.returnReplayedOutputs(items, at, end); err != nil {
+			return at, err
+		}
+		at = end
+	}
+
+	return at, nil
+}
+
+type replayItem struct {
+	module *pbsubstreams.Module
+	item   *outputs.CacheItem
+}
+
+// returnReplayedOutputs sends one BlockScopedData per block in
+// [startBlock, endBlock), with the outputs in module execution order.
+func (p *Pipeline) returnReplayedOutputs(items []*replayItem, startBlock, endBlock uint64) error {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].item.BlockNum < items[j].item.BlockNum
+	})
+
+	var data *pbsubstreams.BlockScopedData
+	send := func() error {
+		if data == nil {
+			return nil
+		}
+		if err := p.respFunc(substreams.NewBlockScopedDataResponse(data)); err != nil {
+			return fmt.Errorf("calling return func: %w", err)
+		}
+		return nil
+	}
+
+	for _, ri := range items {
+		item := ri.item
+		if item.BlockNum < startBlock || item.BlockNum >= endBlock {
+			continue
+		}
+
+		if data == nil || data.Clock.Id != item.BlockID {
+			if err := send(); err != nil {
+				return err
+			}
+
+			ref := bstream.NewBlockRef(item.BlockID, item.BlockNum)
+			cursor := &bstream.Cursor{Step: bstream.StepIrreversible, Block: ref, LIB: ref, HeadBlock: ref}
+			data = &pbsubstreams.BlockScopedData{
+				Clock: &pbsubstreams.Clock{
+					Id:        item.BlockID,
+					Number:    item.BlockNum,
+					Timestamp: timestamppb.New(item.Timestamp),
+				},
+				Step:   pbsubstreams.StepToProto(bstream.StepIrreversible),
+				Cursor: cursor.ToOpaque(),
+			}
+		}
+
+		outputData, err := replayedOutputData(ri.module, item.Payload)
+		if err != nil {
+			return fmt.Errorf("block %d: module %q: %w", item.BlockNum, ri.module.Name, err)
+		}
+		data.Outputs = append(data.Outputs, &pbsubstreams.ModuleOutput{
+			Name: ri.module.Name,
+			Data: outputData,
+		})
+	}
+
+	return send()
+}
+
+func replayedOutputData(module *pbsubstreams.Module, payload []byte) (pbsubstreams.ModuleOutputData, error) {
+	switch kind := module.Kind.(type) {
+	case *pbsubstreams.Module_KindMap_:
+		return &pbsubstreams.ModuleOutput_MapOutput{
+			MapOutput: &anypb.Any{TypeUrl: "type.googleapis.com/" + kind.KindMap.OutputType, Value: payload},
+		}, nil
+	case *pbsubstreams.Module_KindStore_:
+		deltas := &pbsubstreams.StoreDeltas{}
+		if err := proto.Unmarshal(payload, deltas); err != nil {
+			return nil, fmt.Errorf("unmarshalling output deltas: %w", err)
+		}
+		return &pbsubstreams.ModuleOutput_StoreDeltas{StoreDeltas: deltas}, nil
+	default:
+		return nil, fmt.Errorf("unsupported module kind %T", module.Kind)
+	}
+}
@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/bstream"
+	"github.com/streamingfast/dstore"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/pipeline/outputs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReplayOutputCaches(t *testing.T) {
+	ctx := context.Background()
+	module := &pbsubstreams.Module{
+		Name: "map_transfers",
+		Kind: &pbsubstreams.Module_KindMap_{KindMap: &pbsubstreams.Module_KindMap{OutputType: "test.Transfers"}},
+	}
+
+	store := dstore.NewMockStore(nil)
+	caches := outputs.NewModuleOutputCache(10)
+	cache := outputs.NewOutputCache(module.Name, store, 10)
+	caches.OutputCaches[module.Name] = cache
+
+	// A previous request processed blocks [0, 15), with outputs on odd blocks
+	_, err := cache.Load(ctx, 0)
+	require.NoError(t, err)
+	for num := uint64(0); num <= 15; num++ {
+		id := fmt.Sprintf("%d", num)
+		require.NoError(t, caches.Update(ctx, bstream.NewBlockRef(id, num), fmt.Sprintf("%d", num-1), true))
+		if num%2 == 1 && num < 15 {
+			clock := &pbsubstreams.Clock{Number: num, Id: id, Timestamp: timestamppb.New(time.Unix(int64(num), 0))}
+			require.NoError(t, cache.Set(clock, []byte(id)))
+		}
+	}
+	require.NoError(t, caches.Flush(ctx, true))
+
+	var received []*pbsubstreams.BlockScopedData
+	p := &Pipeline{
+		request:                      &pbsubstreams.Request{StartBlockNum: 2, StopBlockNum: 20, OutputModules: []string{module.Name}},
+		requestedStartBlockNum:       2,
+		outputCacheReplay:            true,
+		outputCacheSaveBlockInterval: 10,
+		modules:                      []*pbsubstreams.Module{module},
+		outputModuleMap:              map[string]bool{module.Name: true},
+		moduleOutputCache:            caches,
+		respFunc: func(resp *pbsubstreams.Response) error {
+			received = append(received, resp.GetData())
+			return nil
+		},
+	}
+	require.True(t, p.shouldReplayOutputCaches())
+
+	resumeBlockNum, err := p.replayOutputCaches(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(15), resumeBlockNum, "blocks past 15 were never processed")
+
+	var nums []uint64
+	for _, data := range received {
+		nums = append(nums, data.Clock.Number)
+		require.Len(t, data.Outputs, 1)
+		assert.Equal(t, "type.googleapis.com/test.Transfers", data.Outputs[0].GetMapOutput().TypeUrl)
+		assert.Equal(t, []byte(data.Clock.Id), data.Outputs[0].GetMapOutput().Value)
+		assert.Equal(t, int64(data.Clock.Number), data.Clock.Timestamp.AsTime().Unix())
+		assert.NotEmpty(t, data.Cursor)
+	}
+	assert.Equal(t, []uint64{3, 5, 7, 9, 11, 13}, nums)
+}
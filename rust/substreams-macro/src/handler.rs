@@ -30,6 +30,7 @@ pub fn main(_args: TokenStream, item: TokenStream, module_type: ModuleType) -> T
         }
     }
     let mut has_seen_writable_store = false;
+    let mut has_seen_params = false;
     let mut args : Vec<proc_macro2::TokenStream> = Vec::with_capacity(input.sig.inputs.len() * 2);
     let mut proto_decodings: Vec<proc_macro2::TokenStream> = Vec::with_capacity(input.sig.inputs.len());
     let mut read_only_stores: Vec<proc_macro2::TokenStream> = Vec::with_capacity(input.sig.inputs.len());
@@ -50,7 +51,23 @@ pub fn main(_args: TokenStream, item: TokenStream, module_type: ModuleType) -> T
                         if input_res.is_err() {
                             return token_stream_with_error(original, syn::Error::new(pat_type.span(), format!("foo {:?}",input_res.err())));
                         }
-                        let input_obj = input_res.unwrap();
+                        let mut input_obj = input_res.unwrap();
+
+                        // The `params` input is explicitly marked: the manifest
+                        // declaring it is not known here, and a `String` could be
+                        // any type named so.
+                        if pat_type.attrs.iter().any(|attr| attr.path.is_ident("params")) {
+                            if !is_string_type(argument_type) {
+                                return token_stream_with_error(original, syn::Error::new(pat_type.span(), format!("the #[params] argument must be a `String`")));
+                            }
+                            if has_seen_params {
+                                return token_stream_with_error(original, syn::Error::new(pat_type.span(), format!("handler cannot have more then one #[params] argument")));
+                            }
+                            has_seen_params = true;
+                            input_obj.is_params = true;
+                        } else if is_string_type(argument_type) {
+                            return token_stream_with_error(original, syn::Error::new(pat_type.span(), format!("a `String` argument must be marked with #[params] to receive the module params")));
+                        }
 
                         if input_obj.is_writable_store {
                             if has_seen_writable_store {
@@ -80,6 +97,8 @@ pub fn main(_args: TokenStream, item: TokenStream, module_type: ModuleType) -> T
 
                         if input_obj.is_deltas {
                             proto_decodings.push(quote! { let #var_name: #argument_type = substreams::proto::decode_ptr::<substreams::pb::substreams::StoreDeltas>(#var_ptr, #var_len).unwrap().deltas; })
+                        } else if input_obj.is_params {
+                            proto_decodings.push(quote! { let #var_name: #argument_type = substreams::memory::get_string(#var_ptr, #var_len); })
                         } else {
                             proto_decodings.push(quote! { let #var_name: #argument_type = substreams::proto::decode_ptr(#var_ptr, #var_len).unwrap(); })
                         }
@@ -122,6 +141,7 @@ struct Input {
     is_writable_store: bool,
     is_readable_store: bool,
     is_deltas: bool,
+    is_params: bool,
     resolved_ty: String
 }
 
@@ -133,6 +153,7 @@ fn parse_input_type(ty: &syn::Type) -> Result<Input, errors::SubstreamMacroError
                 is_writable_store: false,
                 is_readable_store: false,
                 is_deltas: false,
+                is_params: false,
                 resolved_ty: "".to_owned()
             };
             let mut last_type = "".to_owned();
@@ -163,6 +184,17 @@ fn parse_input_type(ty: &syn::Type) -> Result<Input, errors::SubstreamMacroError
 }
 
 
+fn is_string_type(ty: &syn::Type) -> bool {
+    match ty {
+        syn::Type::Path(p) if p.qself.is_none() => {
+            let segments: Vec<String> = p.path.segments.iter().map(|s| s.ident.to_string()).collect();
+            segments == ["String"] || segments == ["std", "string", "String"]
+        }
+        _ => false
+    }
+}
+
+
 fn parse_func_output(final_config: &FinalConfiguration, output: syn::ReturnType) -> Result<(), syn::Error> {
     match final_config.module_type {
         ModuleType::Map => {
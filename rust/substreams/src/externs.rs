@@ -14,6 +14,7 @@ extern "C" {
 #[link(wasm_import_module = "logger")]
 extern "C" {
     pub fn println(ptr: *const u8, len: usize);
+    pub fn log(ptr: *const u8, len: usize);
 }
 
 pub mod state {
@@ -36,6 +36,12 @@
 //!     unimplemented!("do something");
 //! }
 //!
+//! /// Map handler that takes the module params and a source as inputs
+//! #[substreams::handlers::map]
+//! fn map_pool(#[params] pool: String, blk: eth::Block) -> Result<proto::Custom, Error> {
+//!     unimplemented!("do something");
+//! }
+//!
 //! /// Map handler that takes a source, another map, and a store in delta mode as inputs
 //! #[substreams::handlers::map]
 //! fn map_db(blk: eth::Block, mints: proto::Custom, store_deltas: store::Deltas) -> Result<proto::Custom, Error> {
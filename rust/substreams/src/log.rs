@@ -5,6 +5,10 @@
 //!
 
 use crate::externs;
+use crate::pb::substreams::{LogEntry, LogField};
+use crate::proto;
+
+pub use crate::pb::substreams::LogLevel as Level;
 
 /// Logs a message at INFO level on current substream's logger using interpolation of
 /// runtime expressions.
@@ -29,9 +33,8 @@ use crate::externs;
 #[doc(hidden)]
 #[macro_export]
 macro_rules! log_info {
-    // We have a special case when matching an expression directly to forward directly to `println`. This is to avoid
-    // any allocation and pass directly the literal to `println` which is able to deal with. However, I'm wondering if
-    // this will cause WTF moment for some cases.
+    // Info messages without fields go through `println`, so modules only
+    // logging them do not import `logger::log`, which older runtimes lack.
     ($msg:expr) => {
         $crate::log::println($msg);
     };
@@ -66,23 +69,91 @@ macro_rules! log_info {
 #[doc(hidden)]
 #[macro_export]
 macro_rules! log_debug {
-    // We have a special case when matching an expression directly to forward directly to `println`. This is to avoid
-    // any allocation and pass directly the literal to `println` which is able to deal with. However, I'm wondering if
-    // this will cause WTF moment for some cases.
     ($msg:expr) => {
-        $crate::log::println($msg);
+        $crate::log::log($crate::log::Level::Debug, $msg, &[]);
     };
 
     ($($arg:tt)*) => {{
         let message = std::fmt::format(format_args!($($arg)*));
 
-        $crate::log::println(message);
+        $crate::log::log($crate::log::Level::Debug, message, &[]);
+    }}
+}
+
+/// Logs a message at WARN level on current substream's logger using interpolation of
+/// runtime expressions.
+///
+/// The behavior is exactly like [std::format::format!] built-in Rust formatting primitive.
+///
+/// # Panics
+///
+/// `format!` panics if a formatting trait implementation returns an error.
+/// This indicates an incorrect implementation
+/// since `fmt::Write for String` never returns an error itself.
+///
+/// # Examples
+///
+/// ```no_run
+/// use substreams::log;
+///
+/// log::warn!("test");
+/// log::warn!("hello {}", "world!");
+/// log::warn!("x = {}, y = {y}", 10, y = 30);
+/// ```
+#[doc(hidden)]
+#[macro_export]
+macro_rules! log_warn {
+    ($msg:expr) => {
+        $crate::log::log($crate::log::Level::Warn, $msg, &[]);
+    };
+
+    ($($arg:tt)*) => {{
+        let message = std::fmt::format(format_args!($($arg)*));
+
+        $crate::log::log($crate::log::Level::Warn, message, &[]);
+    }}
+}
+
+/// Logs a message at ERROR level on current substream's logger using interpolation of
+/// runtime expressions.
+///
+/// The behavior is exactly like [std::format::format!] built-in Rust formatting primitive.
+///
+/// # Panics
+///
+/// `format!` panics if a formatting trait implementation returns an error.
+/// This indicates an incorrect implementation
+/// since `fmt::Write for String` never returns an error itself.
+///
+/// # Examples
+///
+/// ```no_run
+/// use substreams::log;
+///
+/// log::error!("test");
+/// log::error!("hello {}", "world!");
+/// log::error!("x = {}, y = {y}", 10, y = 30);
+/// ```
+#[doc(hidden)]
+#[macro_export]
+macro_rules! log_error {
+    ($msg:expr) => {
+        $crate::log::log($crate::log::Level::Error, $msg, &[]);
+    };
+
+    ($($arg:tt)*) => {{
+        let message = std::fmt::format(format_args!($($arg)*));
+
+        $crate::log::log($crate::log::Level::Error, message, &[]);
     }}
 }
 
 pub use log_debug as debug;
+pub use log_error as error;
 pub use log_info as info;
+pub use log_warn as warn;
 
+/// Logs a message at INFO level, without fields.
 pub fn println<T: AsRef<str>>(msg: T) {
     let reference = msg.as_ref();
 
@@ -90,3 +161,36 @@ pub fn println<T: AsRef<str>>(msg: T) {
         externs::println(reference.as_ptr(), reference.len());
     }
 }
+
+/// Logs a message at `level` with key/value `fields`. The entry is
+/// dropped by the runtime when `level` is below the minimum level of the
+/// request.
+///
+/// Modules using it, or the `debug!`, `warn!` and `error!` macros, import
+/// the `logger::log` host function, and only run on servers providing it.
+///
+/// # Examples
+///
+/// ```no_run
+/// use substreams::log;
+///
+/// log::log(log::Level::Warn, "pair not found", &[("pair", "0xabc"), ("block", "12")]);
+/// ```
+pub fn log<T: AsRef<str>>(level: Level, msg: T, fields: &[(&str, &str)]) {
+    let entry = LogEntry {
+        level: level as i32,
+        message: msg.as_ref().to_string(),
+        fields: fields
+            .iter()
+            .map(|(key, value)| LogField {
+                key: key.to_string(),
+                value: value.to_string(),
+            })
+            .collect(),
+    };
+
+    let buffer = proto::encode(&entry).unwrap();
+    unsafe {
+        externs::log(buffer.as_ptr(), buffer.len());
+    }
+}
@@ -53,3 +53,14 @@ pub fn get_output_data(output_ptr: *mut u8) -> Vec<u8> {
         );
     }
 }
+
+/// Copies the UTF-8 string at `ptr`, like the `params` input of a module.
+pub fn get_string(ptr: *mut u8, len: usize) -> String {
+    if len == 0 {
+        return String::new();
+    }
+    unsafe {
+        let value_bytes = slice::from_raw_parts(ptr, len);
+        return String::from_utf8(value_bytes.to_vec()).expect("error reading string, invalid utf-8");
+    }
+}
@@ -73,7 +73,7 @@ pub mod module {
     }
     #[derive(Clone, PartialEq, ::prost::Message)]
     pub struct Input {
-        #[prost(oneof="input::Input", tags="1, 2, 3")]
+        #[prost(oneof="input::Input", tags="1, 2, 3, 4")]
         pub input: ::core::option::Option<input::Input>,
     }
     /// Nested message and enum types in `Input`.
@@ -107,6 +107,12 @@ pub mod module {
                 Deltas = 2,
             }
         }
+        #[derive(Clone, PartialEq, ::prost::Message)]
+        pub struct Params {
+            /// ex: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", passed as is to the module
+            #[prost(string, tag="1")]
+            pub value: ::prost::alloc::string::String,
+        }
         #[derive(Clone, PartialEq, ::prost::Oneof)]
         pub enum Input {
             #[prost(message, tag="1")]
@@ -115,6 +121,8 @@ pub mod module {
             Map(Map),
             #[prost(message, tag="3")]
             Store(Store),
+            #[prost(message, tag="4")]
+            Params(Params),
         }
     }
     #[derive(Clone, PartialEq, ::prost::Message)]
@@ -157,6 +165,10 @@ pub struct Request {
     pub output_modules: ::prost::alloc::vec::Vec<::prost::alloc::string::String>,
     #[prost(string, repeated, tag="8")]
     pub initial_store_snapshot_for_modules: ::prost::alloc::vec::Vec<::prost::alloc::string::String>,
+    /// Minimum level of the module logs to collect, all of them when
+    /// unspecified.
+    #[prost(enumeration="LogLevel", tag="9")]
+    pub min_log_level: i32,
 }
 #[derive(Clone, PartialEq, ::prost::Message)]
 pub struct Response {
@@ -209,10 +221,10 @@ pub struct BlockScopedData {
 pub struct ModuleOutput {
     #[prost(string, tag="1")]
     pub name: ::prost::alloc::string::String,
-    #[prost(string, repeated, tag="4")]
-    pub logs: ::prost::alloc::vec::Vec<::prost::alloc::string::String>,
+    #[prost(message, repeated, tag="6")]
+    pub logs: ::prost::alloc::vec::Vec<LogEntry>,
     /// LogsTruncated is a flag that tells you if you received all the logs or if they
-    /// were truncated because you logged too much (fixed limit currently is set to 128 KiB).
+    /// were truncated because you logged too much (limit set by the server, 128 KiB by default).
     #[prost(bool, tag="5")]
     pub logs_truncated: bool,
     #[prost(oneof="module_output::Data", tags="2, 3")]
@@ -228,6 +240,24 @@ pub mod module_output {
         StoreDeltas(super::StoreDeltas),
     }
 }
+/// LogEntry is a log emitted by a module. It is also the message modules
+/// pass, encoded, to the `logger.log` host function.
+#[derive(Clone, PartialEq, ::prost::Message)]
+pub struct LogEntry {
+    #[prost(enumeration="LogLevel", tag="1")]
+    pub level: i32,
+    #[prost(string, tag="2")]
+    pub message: ::prost::alloc::string::String,
+    #[prost(message, repeated, tag="3")]
+    pub fields: ::prost::alloc::vec::Vec<LogField>,
+}
+#[derive(Clone, PartialEq, ::prost::Message)]
+pub struct LogField {
+    #[prost(string, tag="1")]
+    pub key: ::prost::alloc::string::String,
+    #[prost(string, tag="2")]
+    pub value: ::prost::alloc::string::String,
+}
 #[derive(Clone, PartialEq, ::prost::Message)]
 pub struct ModulesProgress {
     #[prost(message, repeated, tag="1")]
@@ -237,7 +267,7 @@ pub struct ModulesProgress {
 pub struct ModuleProgress {
     #[prost(string, tag="1")]
     pub name: ::prost::alloc::string::String,
-    #[prost(oneof="module_progress::Type", tags="2, 3, 4, 5")]
+    #[prost(oneof="module_progress::Type", tags="2, 3, 4, 5, 6")]
     pub r#type: ::core::option::Option<module_progress::Type>,
 }
 /// Nested message and enum types in `ModuleProgress`.
@@ -263,13 +293,28 @@ pub mod module_progress {
     pub struct Failed {
         #[prost(string, tag="1")]
         pub reason: ::prost::alloc::string::String,
-        #[prost(string, repeated, tag="2")]
-        pub logs: ::prost::alloc::vec::Vec<::prost::alloc::string::String>,
+        #[prost(message, repeated, tag="4")]
+        pub logs: ::prost::alloc::vec::Vec<super::LogEntry>,
         /// FailureLogsTruncated is a flag that tells you if you received all the logs or if they
-        /// were truncated because you logged too much (fixed limit currently is set to 128 KiB).
+        /// were truncated because you logged too much (limit set by the server, 128 KiB by default).
         #[prost(bool, tag="3")]
         pub logs_truncated: bool,
     }
+    #[derive(Clone, PartialEq, ::prost::Message)]
+    pub struct Throughput {
+        /// Blocks processed per second, averaged over a recent time window.
+        #[prost(double, tag="1")]
+        pub blocks_per_second: f64,
+        #[prost(uint64, tag="2")]
+        pub processed_blocks: u64,
+        /// Number of blocks to process to complete the work, 0 when unbounded
+        /// (for example when live streaming without a stop block).
+        #[prost(uint64, tag="3")]
+        pub total_blocks: u64,
+        /// Estimated number of seconds until `total_blocks` are processed, 0 when unknown.
+        #[prost(uint64, tag="4")]
+        pub eta_seconds: u64,
+    }
     #[derive(Clone, PartialEq, ::prost::Oneof)]
     pub enum Type {
         #[prost(message, tag="2")]
@@ -280,6 +325,8 @@ pub mod module_progress {
         ProcessedBytes(ProcessedBytes),
         #[prost(message, tag="5")]
         Failed(Failed),
+        #[prost(message, tag="6")]
+        Throughput(Throughput),
     }
 }
 #[derive(Clone, PartialEq, ::prost::Message)]
@@ -340,6 +387,15 @@ pub enum ForkStep {
     /// Block is now irreversible and can be committed to (finality is chain specific, see chain documentation for more details)
     StepIrreversible = 4,
 }
+#[derive(Clone, Copy, Debug, PartialEq, Eq, Hash, PartialOrd, Ord, ::prost::Enumeration)]
+#[repr(i32)]
+pub enum LogLevel {
+    Unspecified = 0,
+    Debug = 1,
+    Info = 2,
+    Warn = 3,
+    Error = 4,
+}
 /// Encoded file descriptor set for the `sf.substreams.v1` package
 pub const FILE_DESCRIPTOR_SET: &[u8] = &[
     0x0a, 0xa9, 0x26, 0x0a, 0x1e, 0x73, 0x66, 0x2f, 0x73, 0x75, 0x62, 0x73, 0x74, 0x72, 0x65, 0x61,
@@ -30,6 +30,17 @@ func test_wasm_path(t *testing.T, wasmFile string) string {
 }
 
 func TestRustScript(t *testing.T) {
+	for _, backendName := range wasm.Backends() {
+		t.Run(backendName, func(t *testing.T) {
+			testRustScript(t, backendName)
+		})
+	}
+}
+
+func testRustScript(t *testing.T, backendName string) {
+	backend, err := wasm.NewBackend(backendName)
+	require.NoError(t, err)
+
 	cases := []struct {
 		wasmFile     string
 		functionName string
@@ -195,7 +206,7 @@ func TestRustScript(t *testing.T) {
 			require.NoError(t, err)
 
 			rpcProv := &testWasmExtension{}
-			runtime := wasm.NewRuntime([]wasm.WASMExtensioner{rpcProv})
+			runtime := wasm.NewRuntime([]wasm.WASMExtensioner{rpcProv}, wasm.WithBackend(backend))
 
 			module, err := runtime.NewModule(context.Background(), &pbsubstreams.Request{}, byteCode, c.functionName)
 			require.NoError(t, err)
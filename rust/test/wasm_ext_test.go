@@ -15,6 +15,17 @@ import (
 )
 
 func TestExtensionCalls(t *testing.T) {
+	for _, backendName := range wasm.Backends() {
+		t.Run(backendName, func(t *testing.T) {
+			testExtensionCalls(t, backendName)
+		})
+	}
+}
+
+func testExtensionCalls(t *testing.T, backendName string) {
+	backend, err := wasm.NewBackend(backendName)
+	require.NoError(t, err)
+
 	cases := []struct {
 		wasmFile     string
 		functionName string
@@ -43,7 +54,7 @@ func TestExtensionCalls(t *testing.T) {
 			require.NoError(t, err)
 
 			rpcProv := &testWasmExtension{}
-			runtime := wasm.NewRuntime([]wasm.WASMExtensioner{rpcProv})
+			runtime := wasm.NewRuntime([]wasm.WASMExtensioner{rpcProv}, wasm.WithBackend(backend))
 			module, err := runtime.NewModule(context.Background(), &pbsubstreams.Request{}, byteCode, c.functionName)
 			require.NoError(t, err)
 
@@ -58,7 +69,11 @@ func TestExtensionCalls(t *testing.T) {
 			}
 
 			assert.True(t, rpcProv.called)
-			assert.Equal(t, c.expectLogs, instance.Logs)
+			var messages []string
+			for _, log := range instance.Logs {
+				messages = append(messages, log.Message)
+			}
+			assert.Equal(t, c.expectLogs, messages)
 		})
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/streamingfast/bstream/stream"
 	"github.com/streamingfast/dstore"
@@ -25,6 +26,10 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// defaultWASMModuleCacheEntries is the number of compiled wasm modules
+// kept in memory by default.
+const defaultWASMModuleCacheEntries = 100
+
 type Service struct {
 	baseStateStore     dstore.Store
 	blockType          string // NOTE: can't that be extracted from the actual block messages? with some proto machinery? Was probably useful when `sf.ethereum.codec.v1.Block` didn't correspond to the `sf.ethereum.type.v1.Block` target type.. but that's not true anymore.
@@ -34,7 +39,18 @@ type Service struct {
 	pipelineOptions []pipeline.PipelineOptioner
 
 	storesSaveInterval           uint64
+	storesCheckpointInterval     uint64
 	outputCacheSaveBlockInterval uint64
+	outputCacheReplay            bool
+
+	wasmInstructionBudget uint64
+	wasmExecutionTimeout  time.Duration
+	wasmMaxMemoryPages    uint32
+	wasmInstanceReuse     bool
+	wasmModuleCache       *wasm.ModuleCache
+	wasmBackend           wasm.Backend
+	wasmMaxLogBytes       uint64
+	wasmExtensionCache    bool
 
 	firehoseServer *firehoseServer.Server
 	streamFactory  *firehose.StreamFactory
@@ -45,6 +61,7 @@ type Service struct {
 
 	parallelSubRequests       int
 	blockRangeSizeSubRequests int
+	subrequestRetryPolicy     *orchestrator.RetryPolicy
 }
 
 func (s *Service) BaseStateStore() dstore.Store {
@@ -85,6 +102,103 @@ func WithStoresSaveInterval(block uint64) Option {
 	}
 }
 
+// WithStoresCheckpointInterval writes full store snapshots every
+// `block` blocks while squashing, instead of at every save interval.
+func WithStoresCheckpointInterval(block uint64) Option {
+	return func(s *Service) {
+		s.storesCheckpointInterval = block
+	}
+}
+
+// WithSubrequestRetryPolicy overrides `orchestrator.DefaultRetryPolicy`,
+// used to retry subrequests failing with a retryable error.
+func WithSubrequestRetryPolicy(policy orchestrator.RetryPolicy) Option {
+	return func(s *Service) {
+		s.subrequestRetryPolicy = &policy
+	}
+}
+
+// WithOutputCacheReplay serves historical requests from the output
+// caches of the requested modules when they cover the requested range,
+// without reading blocks. Processing falls back to blocks at the first
+// gap in the caches.
+func WithOutputCacheReplay() Option {
+	return func(s *Service) {
+		s.outputCacheReplay = true
+	}
+}
+
+// WithWASMInstructionBudget fails the request when a module executes more
+// than `instructions` wasm instructions on a single block, instead of
+// letting a runaway module hold a worker forever.
+func WithWASMInstructionBudget(instructions uint64) Option {
+	return func(s *Service) {
+		s.wasmInstructionBudget = instructions
+	}
+}
+
+// WithWASMExecutionTimeout fails the request when a module runs for
+// longer than `timeout` on a single block.
+func WithWASMExecutionTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.wasmExecutionTimeout = timeout
+	}
+}
+
+// WithWASMMaxMemoryPages caps the wasm memory of each module instance to
+// `pages` pages of 64 KiB, failing the request when a module needs more.
+func WithWASMMaxMemoryPages(pages uint32) Option {
+	return func(s *Service) {
+		s.wasmMaxMemoryPages = pages
+	}
+}
+
+// WithWASMInstanceReuse reuses the wasm instance of every module across
+// blocks, restoring its memory and globals in between, as if all modules
+// declared `reuseInstance: true`. Only safe when all served modules keep
+// no state outside of their wasm instance between calls.
+func WithWASMInstanceReuse() Option {
+	return func(s *Service) {
+		s.wasmInstanceReuse = true
+	}
+}
+
+// WithWASMModuleCache replaces the in-memory cache of compiled wasm
+// modules shared by all requests, for example with one also storing
+// them in a local directory to survive restarts.
+func WithWASMModuleCache(cache *wasm.ModuleCache) Option {
+	return func(s *Service) {
+		s.wasmModuleCache = cache
+	}
+}
+
+// WithWASMBackend runs the modules with `backend`, as returned by
+// `wasm.NewBackend`, instead of the default: wasmer when built with cgo,
+// the pure Go wazero backend otherwise.
+func WithWASMBackend(backend wasm.Backend) Option {
+	return func(s *Service) {
+		s.wasmBackend = backend
+	}
+}
+
+// WithWASMMaxLogBytes limits the logs collected per module per block, and
+// returned to the client, to `bytes` (128 KiB by default).
+func WithWASMMaxLogBytes(bytes uint64) Option {
+	return func(s *Service) {
+		s.wasmMaxLogBytes = bytes
+	}
+}
+
+// WithWASMExtensionCallCache persists the outputs of the calls to
+// cacheable wasm extensions (see `wasm.CacheableWASMExtensioner`) in the
+// state store, so reprocessing a range reuses them instead of calling the
+// extensions again.
+func WithWASMExtensionCallCache() Option {
+	return func(s *Service) {
+		s.wasmExtensionCache = true
+	}
+}
+
 func WithOutCacheSaveInterval(block uint64) Option {
 	return func(s *Service) {
 		s.outputCacheSaveBlockInterval = block
@@ -104,6 +218,11 @@ func New(stateStore dstore.Store, blockType string, grpcClientFactory func() (pb
 		opt(s)
 	}
 
+	if s.wasmModuleCache == nil {
+		// Cannot fail without a directory
+		s.wasmModuleCache, _ = wasm.NewModuleCache(defaultWASMModuleCacheEntries, "")
+	}
+
 	return s
 }
 
@@ -127,7 +246,7 @@ func (s *Service) Blocks(request *pbsubstreams.Request, streamSrv pbsubstreams.S
 		return fmt.Errorf("invalid negative startblock (not handled in substreams): %d", request.StartBlockNum)
 	}
 
-	if err := manifest.ValidateModules(request.Modules); err != nil {
+	if err := manifest.ValidateModules(request.Modules, manifest.WithWASMExtensions(wasm.ExtensionFunctions(s.wasmExtensions))); err != nil {
 		return status.Error(codes.InvalidArgument, fmt.Sprintf("modules validation failed: %s", err))
 	}
 
@@ -175,6 +294,37 @@ func (s *Service) Blocks(request *pbsubstreams.Request, streamSrv pbsubstreams.S
 	if s.storesSaveInterval != 0 {
 		opts = append(opts, pipeline.WithStoresSaveInterval(s.storesSaveInterval))
 	}
+	if s.subrequestRetryPolicy != nil {
+		opts = append(opts, pipeline.WithSubrequestRetryPolicy(*s.subrequestRetryPolicy))
+	}
+	if s.storesCheckpointInterval != 0 {
+		opts = append(opts, pipeline.WithStoresCheckpointInterval(s.storesCheckpointInterval))
+	}
+	if s.outputCacheReplay {
+		opts = append(opts, pipeline.WithOutputCacheReplay())
+	}
+	if s.wasmInstructionBudget != 0 {
+		opts = append(opts, pipeline.WithWASMInstructionBudget(s.wasmInstructionBudget))
+	}
+	if s.wasmExecutionTimeout != 0 {
+		opts = append(opts, pipeline.WithWASMExecutionTimeout(s.wasmExecutionTimeout))
+	}
+	if s.wasmMaxMemoryPages != 0 {
+		opts = append(opts, pipeline.WithWASMMaxMemoryPages(s.wasmMaxMemoryPages))
+	}
+	if s.wasmInstanceReuse {
+		opts = append(opts, pipeline.WithWASMInstanceReuse())
+	}
+	opts = append(opts, pipeline.WithWASMModuleCache(s.wasmModuleCache))
+	if s.wasmBackend != nil {
+		opts = append(opts, pipeline.WithWASMBackend(s.wasmBackend))
+	}
+	if s.wasmMaxLogBytes != 0 {
+		opts = append(opts, pipeline.WithWASMMaxLogBytes(s.wasmMaxLogBytes))
+	}
+	if s.wasmExtensionCache {
+		opts = append(opts, pipeline.WithWASMExtensionCallCache())
+	}
 	responseHandler := func(resp *pbsubstreams.Response) error {
 		if err := streamSrv.Send(resp); err != nil {
 			return NewErrSendBlock(err)
@@ -186,8 +336,18 @@ func (s *Service) Blocks(request *pbsubstreams.Request, streamSrv pbsubstreams.S
 
 	pipe := pipeline.New(ctx, request, graph, s.blockType, s.baseStateStore, s.outputCacheSaveBlockInterval, s.wasmExtensions, s.grpcClientFactory, s.blockRangeSizeSubRequests, responseHandler, opts...)
 
+	if err := pipe.Init(workerPool); err != nil {
+		return fmt.Errorf("error building pipeline: %w", err)
+	}
+
+	if pipe.FullyReplayed() {
+		logger.Info("requested range served from output caches")
+		return nil
+	}
+
 	firehoseReq := &pbfirehose.Request{
-		StartBlockNum: request.StartBlockNum,
+		// Past the blocks replayed from output caches, if any
+		StartBlockNum: int64(pipe.StartBlockNum()),
 		StopBlockNum:  request.StopBlockNum,
 		StartCursor:   request.StartCursor,
 		ForkSteps:     []pbfirehose.ForkStep{pbfirehose.ForkStep_STEP_IRREVERSIBLE},
@@ -199,10 +359,6 @@ func (s *Service) Blocks(request *pbsubstreams.Request, streamSrv pbsubstreams.S
 		// perhaps on the day we actually support it in the Firehose :)
 	}
 
-	if err := pipe.Init(workerPool); err != nil {
-		return fmt.Errorf("error building pipeline: %w", err)
-	}
-
 	st, err := s.streamFactory.New(ctx, pipe, firehoseReq, zap.NewNop())
 	if err != nil {
 		return fmt.Errorf("error getting stream: %w", err)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/streamingfast/derr"
 	"github.com/streamingfast/dstore"
@@ -34,6 +35,8 @@ type Store struct {
 	ValueType    string
 
 	lastOrdinal uint64
+
+	partialsOwner string // recorded in the info file of the partials written
 }
 
 func NewBuilder(name string, saveInterval uint64, moduleInitialBlock uint64, moduleHash string, updatePolicy pbsubstreams.Module_KindStore_UpdatePolicy, valueType string, store dstore.Store, opts ...BuilderOption) (*Store, error) {
@@ -73,6 +76,7 @@ func (s *Store) CloneStructure(newStoreStartBlock uint64) *Store {
 		KV:                 map[string][]byte{},
 		UpdatePolicy:       s.UpdatePolicy,
 		ValueType:          s.ValueType,
+		partialsOwner:      s.partialsOwner,
 	}
 	//store.resetNextBoundary()
 	zlog.Info("store cloned", zap.Object("store", store))
@@ -162,8 +166,34 @@ func (s *Store) WriteState(ctx context.Context, endBoundaryBlock uint64) (err er
 		return fmt.Errorf("marshal kv state: %w", err)
 	}
 
+	if !s.IsPartial() {
+		if _, err = s.writeState(ctx, content, endBoundaryBlock); err != nil {
+			return fmt.Errorf("writing %s kv for range %d-%d: %w", s.Name, s.storeInitialBlock, endBoundaryBlock, err)
+		}
+		return nil
+	}
+
+	// Partials are surrounded by their info file, marked completed
+	// only once the partial is fully written.
+	partialRange := block.NewRange(s.storeInitialBlock, endBoundaryBlock)
+	info := &PartialInfo{Owner: s.partialsOwner, CreatedAt: time.Now()}
+	if err := s.writePartialInfo(ctx, partialRange, info); err != nil {
+		return fmt.Errorf("writing %s partial info for range %s: %w", s.Name, partialRange, err)
+	}
+
 	if _, err = s.writeState(ctx, content, endBoundaryBlock); err != nil {
-		return fmt.Errorf("writing %s kv for range %d-%d: %w", s.Name, s.storeInitialBlock, endBoundaryBlock, err)
+		return fmt.Errorf("writing %s partial for range %s: %w", s.Name, partialRange, err)
+	}
+
+	completedAt := time.Now()
+	info.Completed = true
+	info.CompletedAt = &completedAt
+	// The state store might not allow overwrites, which are silently ignored
+	if err := s.Store.DeleteObject(ctx, PartialInfoFileName(partialRange)); err != nil {
+		return fmt.Errorf("deleting %s partial info for range %s: %w", s.Name, partialRange, err)
+	}
+	if err := s.writePartialInfo(ctx, partialRange, info); err != nil {
+		return fmt.Errorf("writing %s partial info for range %s: %w", s.Name, partialRange, err)
 	}
 
 	return nil
@@ -193,6 +223,14 @@ func (s *Store) DeleteStore(ctx context.Context, exclusiveEndBlock uint64) error
 	if err := s.Store.DeleteObject(ctx, filename); err != nil {
 		return fmt.Errorf("deleting store file %q: %w", filename, err)
 	}
+
+	if s.IsPartial() {
+		infoFilename := PartialInfoFileName(block.NewRange(s.storeInitialBlock, exclusiveEndBlock))
+		if err := s.Store.DeleteObject(ctx, infoFilename); err != nil {
+			// Partials written by older versions have no info file
+			zlog.Debug("deleting partial info file", zap.String("file_name", infoFilename), zap.Error(err))
+		}
+	}
 	return nil
 }
 
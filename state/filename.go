@@ -9,9 +9,11 @@ import (
 )
 
 var stateFileRegex *regexp.Regexp
+var partialInfoFileRegex *regexp.Regexp
 
 func init() {
-	stateFileRegex = regexp.MustCompile(`([\d]+)-([\d]+)\.(kv|partial)`)
+	stateFileRegex = regexp.MustCompile(`^([\d]+)-([\d]+)\.(kv|partial)$`)
+	partialInfoFileRegex = regexp.MustCompile(`^__([\d]+)-([\d]+)\.partial\.info\.json$`)
 }
 
 type FileInfo struct {
@@ -37,6 +39,17 @@ func ParseFileName(filename string) (*FileInfo, bool) {
 	}, true
 }
 
+func parsePartialInfoFileName(filename string) (*block.Range, bool) {
+	res := partialInfoFileRegex.FindAllStringSubmatch(filename, 1)
+	if len(res) != 1 {
+		return nil, false
+	}
+
+	end := uint64(mustAtoi(res[0][1]))
+	start := uint64(mustAtoi(res[0][2]))
+	return &block.Range{StartBlock: start, ExclusiveEndBlock: end}, true
+}
+
 func FullStateFilePrefix(blockNum uint64) string {
 	return fmt.Sprintf("%010d", blockNum)
 }
@@ -0,0 +1,194 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/block"
+	"go.uber.org/zap"
+)
+
+// PartialInfo is written next to each partial store file. It records
+// who wrote the partial and whether the write completed, so abandoned
+// partials can be told apart from the ones waiting to be squashed.
+type PartialInfo struct {
+	Owner       string     `json:"owner"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// PartialInfoFileName is prefixed with `__` so snapshot listings skip it.
+func PartialInfoFileName(r *block.Range) string {
+	return fmt.Sprintf("__%s.info.json", PartialFileName(r))
+}
+
+func WithPartialsOwner(owner string) BuilderOption {
+	return func(b *Store) {
+		b.partialsOwner = owner
+	}
+}
+
+func (s *Store) writePartialInfo(ctx context.Context, r *block.Range, info *PartialInfo) error {
+	content, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("marshal partial info: %w", err)
+	}
+	return s.Store.WriteObject(ctx, PartialInfoFileName(r), bytes.NewReader(content))
+}
+
+func ReadPartialInfo(ctx context.Context, store dstore.Store, r *block.Range) (*PartialInfo, error) {
+	reader, err := store.OpenObject(ctx, PartialInfoFileName(r))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading partial info: %w", err)
+	}
+
+	info := &PartialInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("unmarshal partial info: %w", err)
+	}
+	return info, nil
+}
+
+// OrphanedPartial is a partial file found by `FindOrphanedPartials`,
+// that can be safely deleted.
+type OrphanedPartial struct {
+	Range        *block.Range
+	Filename     string // empty when only the info file remains
+	InfoFilename string // empty for partials without info file
+	Reason       string
+}
+
+// Filenames returns the files to delete for this orphan.
+func (o *OrphanedPartial) Filenames() (out []string) {
+	if o.Filename != "" {
+		out = append(out, o.Filename)
+	}
+	if o.InfoFilename != "" {
+		out = append(out, o.InfoFilename)
+	}
+	return out
+}
+
+// FindOrphanedPartials lists the partials of a store that will never be
+// squashed:
+//
+// * partials already merged in a complete snapshot,
+// * partials whose write never completed, older than `retention`,
+// * partials that cannot be loaded, older than `retention`, when `validate` is set,
+// * info files left without their partial, older than `retention`.
+//
+// Partials written before info files were introduced have no known
+// age, and are considered older than `retention`.
+func FindOrphanedPartials(ctx context.Context, store dstore.Store, retention time.Duration, validate bool, now time.Time) (out []*OrphanedPartial, err error) {
+	highestKVBlock := uint64(0)
+	partials := map[string]*block.Range{}
+	infoFiles := map[string]*block.Range{} // keyed by partial filename
+
+	err = store.Walk(ctx, "", func(filename string) (err error) {
+		if fileInfo, ok := ParseFileName(filename); ok {
+			if fileInfo.Partial {
+				partials[filename] = block.NewRange(fileInfo.StartBlock, fileInfo.EndBlock)
+			} else if fileInfo.EndBlock > highestKVBlock {
+				highestKVBlock = fileInfo.EndBlock
+			}
+			return nil
+		}
+		if fileInfo, ok := parsePartialInfoFileName(filename); ok {
+			infoFiles[PartialFileName(fileInfo)] = fileInfo
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking store: %w", err)
+	}
+
+	expired := func(info *PartialInfo) bool {
+		return info == nil || now.Sub(info.CreatedAt) > retention
+	}
+	orphan := func(filename string, r *block.Range, reason string) *OrphanedPartial {
+		o := &OrphanedPartial{Range: r, Filename: filename, Reason: reason}
+		if _, found := infoFiles[PartialFileName(r)]; found {
+			o.InfoFilename = PartialInfoFileName(r)
+		}
+		return o
+	}
+
+	for filename, r := range partials {
+		if r.ExclusiveEndBlock <= highestKVBlock {
+			out = append(out, orphan(filename, r, "already merged in a complete snapshot"))
+			continue
+		}
+
+		var info *PartialInfo
+		if _, found := infoFiles[filename]; found {
+			info, err = ReadPartialInfo(ctx, store, r)
+			if err != nil {
+				zlog.Warn("reading partial info", zap.String("filename", filename), zap.Error(err))
+			}
+		}
+
+		if info != nil && !info.Completed {
+			if expired(info) {
+				out = append(out, orphan(filename, r, fmt.Sprintf("write by %q never completed", info.Owner)))
+			}
+			continue
+		}
+
+		if validate && expired(info) {
+			if err := validatePartial(ctx, store, filename); err != nil {
+				out = append(out, orphan(filename, r, fmt.Sprintf("cannot be loaded: %s", err)))
+			}
+		}
+	}
+
+	for filename, r := range infoFiles {
+		if _, found := partials[filename]; found {
+			continue
+		}
+		info, err := ReadPartialInfo(ctx, store, r)
+		if err != nil {
+			zlog.Warn("reading partial info", zap.String("filename", filename), zap.Error(err))
+			info = nil
+		}
+		if expired(info) {
+			out = append(out, orphan("", r, "partial file is missing"))
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Range.StartBlock < out[j].Range.StartBlock
+	})
+	return out, nil
+}
+
+func validatePartial(ctx context.Context, store dstore.Store, filename string) error {
+	reader, err := store.OpenObject(ctx, filename)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("reading data: %w", err)
+	}
+
+	kv := map[string]string{}
+	if err := json.Unmarshal(data, &kv); err != nil {
+		return fmt.Errorf("unmarshal data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOrphanedPartials(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Minute)
+
+	store := dstore.NewMockStore(nil)
+	setInfo := func(start, end uint64, info *PartialInfo) {
+		cnt, err := json.Marshal(info)
+		require.NoError(t, err)
+		store.SetFile(PartialInfoFileName(block.NewRange(start, end)), cnt)
+	}
+
+	store.SetFile("0000020000-0000010000.kv", []byte("{}"))
+	store.SetFile("0000020000-0000010000.partial", []byte("{}")) // merged, legacy
+	store.SetFile("0000030000-0000020000.partial", []byte("{}")) // completed, waiting to be squashed
+	setInfo(20000, 30000, &PartialInfo{Owner: "a", CreatedAt: old, Completed: true})
+	store.SetFile("0000040000-0000030000.partial", []byte("{\"ha")) // half written, abandoned
+	setInfo(30000, 40000, &PartialInfo{Owner: "b", CreatedAt: old})
+	store.SetFile("0000050000-0000040000.partial", []byte("")) // being written right now
+	setInfo(40000, 50000, &PartialInfo{Owner: "c", CreatedAt: recent})
+	store.SetFile("0000060000-0000050000.partial", []byte("not json"))               // corrupted, legacy
+	setInfo(60000, 70000, &PartialInfo{Owner: "d", CreatedAt: old, Completed: true}) // partial missing
+
+	orphans, err := FindOrphanedPartials(context.Background(), store, 24*time.Hour, true, now)
+	require.NoError(t, err)
+
+	require.Len(t, orphans, 4)
+	assert.Equal(t, []string{"0000020000-0000010000.partial"}, orphans[0].Filenames())
+	assert.Equal(t, "already merged in a complete snapshot", orphans[0].Reason)
+	assert.Equal(t, []string{"0000040000-0000030000.partial", "__0000040000-0000030000.partial.info.json"}, orphans[1].Filenames())
+	assert.Equal(t, `write by "b" never completed`, orphans[1].Reason)
+	assert.Equal(t, []string{"0000060000-0000050000.partial"}, orphans[2].Filenames())
+	assert.Contains(t, orphans[2].Reason, "cannot be loaded")
+	assert.Equal(t, []string{"__0000070000-0000060000.partial.info.json"}, orphans[3].Filenames())
+
+	orphans, err = FindOrphanedPartials(context.Background(), store, 24*time.Hour, false, now)
+	require.NoError(t, err)
+	assert.Len(t, orphans, 3, "corrupted partials are only found when validating")
+}
+
+func TestStoreWriteState_PartialInfo(t *testing.T) {
+	store := dstore.NewMockStore(nil)
+	s, err := NewBuilder("test", 10_000, 10_000, "abc", 0, OutputValueTypeString, store, WithPartialsOwner("me"))
+	require.NoError(t, err)
+
+	partial := s.CloneStructure(20_000)
+	require.NoError(t, partial.WriteState(context.Background(), 30_000))
+
+	info, err := ReadPartialInfo(context.Background(), partial.Store, block.NewRange(20_000, 30_000))
+	require.NoError(t, err)
+	assert.Equal(t, "me", info.Owner)
+	assert.True(t, info.Completed)
+	assert.NotNil(t, info.CompletedAt)
+
+	require.NoError(t, partial.DeleteStore(context.Background(), 30_000))
+	_, err = ReadPartialInfo(context.Background(), partial.Store, block.NewRange(20_000, 30_000))
+	assert.Error(t, err)
+}
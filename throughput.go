@@ -0,0 +1,140 @@
+package substreams
+
+import (
+	"sync"
+	"time"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+)
+
+const defaultThroughputWindow = 30 * time.Second
+
+// ThroughputTracker measures the rate at which blocks are processed,
+// over a sliding time window, and derives an estimated time of
+// completion when the total amount of blocks to process is known.
+type ThroughputTracker struct {
+	sync.Mutex
+
+	totalBlocks     uint64 // 0 means unbounded
+	processedBlocks uint64
+
+	window   time.Duration
+	samples  []throughputSample
+	lastEmit time.Time
+
+	now func() time.Time
+}
+
+type throughputSample struct {
+	at        time.Time
+	processed uint64
+}
+
+func NewThroughputTracker(totalBlocks uint64) *ThroughputTracker {
+	t := &ThroughputTracker{
+		totalBlocks: totalBlocks,
+		window:      defaultThroughputWindow,
+		now:         time.Now,
+	}
+	t.samples = append(t.samples, throughputSample{at: t.now()})
+	return t
+}
+
+// Add records `blocks` more processed blocks.
+func (t *ThroughputTracker) Add(blocks uint64) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.record(t.processedBlocks + blocks)
+}
+
+// Set records the absolute count of processed blocks. Counts lower
+// than what was already recorded are ignored.
+func (t *ThroughputTracker) Set(processedBlocks uint64) {
+	t.Lock()
+	defer t.Unlock()
+
+	if processedBlocks < t.processedBlocks {
+		return
+	}
+	t.record(processedBlocks)
+}
+
+func (t *ThroughputTracker) record(processedBlocks uint64) {
+	now := t.now()
+	t.processedBlocks = processedBlocks
+	t.samples = append(t.samples, throughputSample{at: now, processed: processedBlocks})
+
+	// Keep a single sample at or before the window start, as the reference point for the rate.
+	cutoff := now.Add(-t.window)
+	drop := 0
+	for drop < len(t.samples)-1 && !t.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	t.samples = t.samples[drop:]
+}
+
+func (t *ThroughputTracker) BlocksPerSecond() float64 {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.blocksPerSecond()
+}
+
+func (t *ThroughputTracker) blocksPerSecond() float64 {
+	first := t.samples[0]
+	elapsed := t.now().Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(t.processedBlocks-first.processed) / elapsed
+}
+
+// ETA returns the estimated time before all blocks are processed, or
+// 0 when it cannot be estimated.
+func (t *ThroughputTracker) ETA() time.Duration {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.eta(t.blocksPerSecond())
+}
+
+func (t *ThroughputTracker) eta(blocksPerSecond float64) time.Duration {
+	if t.totalBlocks == 0 || blocksPerSecond <= 0 || t.processedBlocks >= t.totalBlocks {
+		return 0
+	}
+	remaining := float64(t.totalBlocks - t.processedBlocks)
+	return time.Duration(remaining / blocksPerSecond * float64(time.Second))
+}
+
+// ShouldEmit returns true at most once per `interval`, to rate limit
+// the progress messages sent to the client.
+func (t *ThroughputTracker) ShouldEmit(interval time.Duration) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	now := t.now()
+	if now.Sub(t.lastEmit) < interval {
+		return false
+	}
+	t.lastEmit = now
+	return true
+}
+
+func (t *ThroughputTracker) ProgressMessage(moduleName string) *pbsubstreams.ModuleProgress {
+	t.Lock()
+	defer t.Unlock()
+
+	rate := t.blocksPerSecond()
+	return &pbsubstreams.ModuleProgress{
+		Name: moduleName,
+		Type: &pbsubstreams.ModuleProgress_Throughput_{
+			Throughput: &pbsubstreams.ModuleProgress_Throughput{
+				BlocksPerSecond: rate,
+				ProcessedBlocks: t.processedBlocks,
+				TotalBlocks:     t.totalBlocks,
+				EtaSeconds:      uint64(t.eta(rate).Seconds()),
+			},
+		},
+	}
+}
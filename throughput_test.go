@@ -0,0 +1,69 @@
+package substreams
+
+import (
+	"testing"
+	"time"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+func newTestTracker(total uint64) (*ThroughputTracker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	t := &ThroughputTracker{totalBlocks: total, window: defaultThroughputWindow, now: clock.Now}
+	t.samples = append(t.samples, throughputSample{at: clock.Now()})
+	return t, clock
+}
+
+func TestThroughputTracker_RateAndETA(t *testing.T) {
+	tracker, clock := newTestTracker(1000)
+
+	clock.Advance(10 * time.Second)
+	tracker.Add(100)
+	assert.Equal(t, 10.0, tracker.BlocksPerSecond())
+	assert.Equal(t, 90*time.Second, tracker.ETA())
+
+	clock.Advance(10 * time.Second)
+	tracker.Set(300)
+	assert.Equal(t, 15.0, tracker.BlocksPerSecond())
+
+	tracker.Set(200) // lower counts are ignored
+	msg := tracker.ProgressMessage("mod")
+	require.IsType(t, &pbsubstreams.ModuleProgress_Throughput_{}, msg.Type)
+	throughput := msg.GetThroughput()
+	assert.Equal(t, "mod", msg.Name)
+	assert.Equal(t, uint64(300), throughput.ProcessedBlocks)
+	assert.Equal(t, uint64(1000), throughput.TotalBlocks)
+	assert.Equal(t, uint64(46), throughput.EtaSeconds)
+}
+
+func TestThroughputTracker_SlidingWindow(t *testing.T) {
+	tracker, clock := newTestTracker(0)
+
+	clock.Advance(60 * time.Second)
+	tracker.Set(60)
+	for i := 0; i < 30; i++ {
+		clock.Advance(time.Second)
+		tracker.Add(10)
+	}
+
+	// The slow first minute falls outside of the window.
+	assert.InDelta(t, 10.0, tracker.BlocksPerSecond(), 0.5)
+	assert.Equal(t, time.Duration(0), tracker.ETA(), "unbounded")
+}
+
+func TestThroughputTracker_ShouldEmit(t *testing.T) {
+	tracker, clock := newTestTracker(0)
+
+	assert.True(t, tracker.ShouldEmit(time.Second))
+	assert.False(t, tracker.ShouldEmit(time.Second))
+	clock.Advance(500 * time.Millisecond)
+	assert.False(t, tracker.ShouldEmit(time.Second))
+	clock.Advance(500 * time.Millisecond)
+	assert.True(t, tracker.ShouldEmit(time.Second))
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/abourget/llerrgroup"
 	"github.com/spf13/cobra"
@@ -12,20 +13,34 @@ import (
 
 var cleanUpCmd = &cobra.Command{
 	Use:   "cleanup <store_url>",
-	Short: "Checks for partial files which have already merged into a full KV store and purges them",
-	Args:  cobra.ExactArgs(1),
-	RunE:  cleanUpE,
+	Short: "Purges orphaned partial files: already merged into a full KV store, never completed, or corrupted",
+	Long: `Purges the partial files of a store that will never be squashed:
+
+* partials already merged into a full KV store,
+* partials whose write never completed (client disconnected, process killed),
+* partials that cannot be loaded (with --validate),
+* info files left without their partial.
+
+All but the merged partials are only deleted when older than --retention.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: cleanUpE,
 }
 
 func init() {
+	cleanUpCmd.Flags().Bool("dry-run", false, "Only list the files that would be deleted")
+	cleanUpCmd.Flags().Duration("retention", 24*time.Hour, "Keep unfinished or invalid partials younger than this, as they might still be in use")
+	cleanUpCmd.Flags().Bool("validate", true, "Load the partials, to detect corrupted ones")
+
 	Cmd.AddCommand(cleanUpCmd)
 }
 
-//delete all partial files which are already merged into the kv store
 func cleanUpE(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
-	var store dstore.Store
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	retention, _ := cmd.Flags().GetDuration("retention")
+	validate, _ := cmd.Flags().GetBool("validate")
 
 	dsn := args[0]
 	store, err := dstore.NewStore(dsn, "", "", false)
@@ -33,50 +48,41 @@ func cleanUpE(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("creating store: %w", err)
 	}
 
-	highestKVBlock := uint64(0)
-	partialFiles := map[uint64]string{}
-
-	_ = store.Walk(ctx, "", func(filename string) (err error) {
-		fileinfo, ok := state.ParseFileName(filename)
-		if !ok {
-			return nil
-		}
-
-		if fileinfo.Partial {
-			partialFiles[fileinfo.EndBlock] = filename
-		}
-
-		if !fileinfo.Partial && fileinfo.EndBlock > highestKVBlock {
-			highestKVBlock = fileinfo.EndBlock
-			return nil
-		}
+	orphans, err := state.FindOrphanedPartials(ctx, store, retention, validate, time.Now())
+	if err != nil {
+		return fmt.Errorf("finding orphaned partials: %w", err)
+	}
 
+	if len(orphans) == 0 {
+		zlog.Info("no orphaned partial files found")
 		return nil
-	})
+	}
 
-	if len(partialFiles) == 0 {
-		zlog.Info("no partial files found")
+	if dryRun {
+		for _, orphan := range orphans {
+			for _, filename := range orphan.Filenames() {
+				fmt.Printf("would delete %s (%s)\n", filename, orphan.Reason)
+			}
+		}
 		return nil
 	}
 
-	eg := llerrgroup.New(len(partialFiles))
+	eg := llerrgroup.New(len(orphans))
 
-	for endBlock, filename := range partialFiles {
+	for _, orphan := range orphans {
 		if eg.Stop() {
 			continue
 		}
 
-		eb := endBlock
-		fn := filename
+		o := orphan
 
 		eg.Go(func() error {
-			if eb > highestKVBlock {
-				return nil
-			}
-
-			err := store.DeleteObject(ctx, fn)
-			if err != nil {
-				zlog.Warn("error deleting file", zap.String("filename", fn), zap.String("store", dsn))
+			zlog.Info("deleting orphaned partial", zap.Stringer("range", o.Range), zap.String("reason", o.Reason))
+			for _, filename := range o.Filenames() {
+				err := store.DeleteObject(ctx, filename)
+				if err != nil {
+					zlog.Warn("error deleting file", zap.String("filename", filename), zap.String("store", dsn), zap.Error(err))
+				}
 			}
 
 			return nil
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/derr"
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/manifest"
+	"go.uber.org/zap"
+)
+
+var migrateHashesCmd = &cobra.Command{
+	Use:   "migrate-hashes <package> [<state_store_url>]",
+	Short: "Map the module hashes of a package from the previous hash version to the current one, and copy the existing caches over",
+	Long: fmt.Sprintf(`Prints the hash of each module of the package before hash version %d,
+and its current one.
+
+With a state store, the store snapshots and output caches found under a
+previous hash are copied under the current one, so they are reused
+instead of recomputed. Previous hashes shared by modules which now have
+different hashes are skipped: their caches mix the outputs of these
+modules. Files already present under the current hash are left
+untouched, so an interrupted migration can be run again to resume it.
+`, manifest.ModuleHashVersion),
+	Args: cobra.RangeArgs(1, 2),
+	RunE: migrateHashesE,
+}
+
+func init() {
+	migrateHashesCmd.Flags().Bool("dry-run", false, "Only list the files that would be copied")
+
+	Cmd.AddCommand(migrateHashesCmd)
+}
+
+type hashMigration struct {
+	modules   []string
+	oldHash   string
+	newHashes []string
+}
+
+func migrateHashesE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	dryRun := mustGetBool(cmd, "dry-run")
+
+	pkg, err := manifest.NewReader(args[0]).Read()
+	if err != nil {
+		return fmt.Errorf("read manifest %q: %w", args[0], err)
+	}
+
+	graph, err := manifest.NewModuleGraph(pkg.Modules.Modules)
+	if err != nil {
+		return fmt.Errorf("creating module graph: %w", err)
+	}
+
+	migrations := map[string]*hashMigration{}
+	for _, module := range pkg.Modules.Modules {
+		oldHash := manifest.HashModuleV1AsString(pkg.Modules, graph, module)
+		newHash := manifest.HashModuleAsString(pkg.Modules, graph, module)
+		fmt.Printf("%s: %s -> %s\n", module.Name, oldHash, newHash)
+
+		migration := migrations[oldHash]
+		if migration == nil {
+			migration = &hashMigration{oldHash: oldHash}
+			migrations[oldHash] = migration
+		}
+		migration.modules = append(migration.modules, module.Name)
+		if !contains(migration.newHashes, newHash) {
+			migration.newHashes = append(migration.newHashes, newHash)
+		}
+	}
+
+	if len(args) == 1 {
+		return nil
+	}
+
+	store, err := dstore.NewStore(args[1], "", "", false)
+	if err != nil {
+		return fmt.Errorf("creating store: %w", err)
+	}
+
+	oldHashes := make([]string, 0, len(migrations))
+	for oldHash := range migrations {
+		oldHashes = append(oldHashes, oldHash)
+	}
+	sort.Strings(oldHashes)
+
+	for _, oldHash := range oldHashes {
+		migration := migrations[oldHash]
+		if len(migration.newHashes) != 1 {
+			zlog.Warn("skipping hash shared by modules with different hashes", zap.String("module_hash", oldHash), zap.Strings("modules", migration.modules))
+			continue
+		}
+		if err := copyHashFiles(ctx, store, oldHash, migration.newHashes[0], dryRun); err != nil {
+			return fmt.Errorf("modules %v: %w", migration.modules, err)
+		}
+	}
+	return nil
+}
+
+// copyHashFiles copies the files under `<oldHash>/` to `<newHash>/`,
+// skipping the files the latter already has.
+func copyHashFiles(ctx context.Context, store dstore.Store, oldHash, newHash string, dryRun bool) error {
+	oldStore, err := store.SubStore(oldHash)
+	if err != nil {
+		return fmt.Errorf("creating store of %s: %w", oldHash, err)
+	}
+	newStore, err := store.SubStore(newHash)
+	if err != nil {
+		return fmt.Errorf("creating store of %s: %w", newHash, err)
+	}
+
+	existing := map[string]bool{}
+	if err := newStore.Walk(ctx, "", func(filename string) error {
+		existing[filename] = true
+		return nil
+	}); err != nil {
+		return fmt.Errorf("listing files of %s: %w", newHash, err)
+	}
+
+	var filenames []string
+	if err := oldStore.Walk(ctx, "", func(filename string) error {
+		if !existing[filename] {
+			filenames = append(filenames, filename)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("listing files of %s: %w", oldHash, err)
+	}
+	if len(existing) != 0 {
+		zlog.Info("skipping files already under the current hash", zap.String("module_hash", newHash), zap.Int("existing_file_count", len(existing)))
+	}
+
+	for _, filename := range filenames {
+		if dryRun {
+			fmt.Printf("would copy %s/%s to %s/%s\n", oldHash, filename, newHash, filename)
+			continue
+		}
+
+		err := derr.RetryContext(ctx, 3, func(ctx context.Context) error {
+			reader, err := oldStore.OpenObject(ctx, filename)
+			if err != nil {
+				return fmt.Errorf("opening %s/%s: %w", oldHash, filename, err)
+			}
+			defer reader.Close()
+
+			if err := newStore.WriteObject(ctx, filename, reader); err != nil {
+				return fmt.Errorf("writing %s/%s: %w", newHash, filename, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !dryRun && len(filenames) != 0 {
+		zlog.Info("copied module hash files", zap.String("from", oldHash), zap.String("to", newHash), zap.Int("file_count", len(filenames)))
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
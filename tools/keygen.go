@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/substreams/manifest"
+)
+
+var keygenCmd = &cobra.Command{
+	Use:   "keygen <name>",
+	Short: "Generate an ed25519 key pair to sign packages, as '<name>.key.pem' (private) and '<name>.pub.pem' (public)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  keygenE,
+}
+
+func init() {
+	Cmd.AddCommand(keygenCmd)
+}
+
+func keygenE(cmd *cobra.Command, args []string) error {
+	privateFilename := args[0] + ".key.pem"
+	publicFilename := args[0] + ".pub.pem"
+	for _, filename := range []string{privateFilename, publicFilename} {
+		if _, err := os.Stat(filename); err == nil {
+			return fmt.Errorf("%q already exists, not overwriting it", filename)
+		}
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating key: %w", err)
+	}
+
+	privateCnt, err := manifest.EncodePrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("encoding private key: %w", err)
+	}
+	publicCnt, err := manifest.EncodePublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("encoding public key: %w", err)
+	}
+
+	if err := ioutil.WriteFile(privateFilename, privateCnt, 0600); err != nil {
+		return fmt.Errorf("writing %q: %w", privateFilename, err)
+	}
+	if err := ioutil.WriteFile(publicFilename, publicCnt, 0644); err != nil {
+		return fmt.Errorf("writing %q: %w", publicFilename, err)
+	}
+
+	fmt.Printf("Wrote key %s: private key %q (keep it secret), public key %q (share it with consumers).\n", manifest.KeyID(publicKey), privateFilename, publicFilename)
+	return nil
+}
@@ -0,0 +1,358 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/spf13/cobra"
+	"github.com/streamingfast/dstore"
+	"github.com/streamingfast/substreams/manifest"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/pipeline/outputs"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+var outputsCmd = &cobra.Command{
+	Use:   "outputs",
+	Short: "Inspect and garbage collect the module output caches (`<hash>/outputs/*.output`) of a state store",
+}
+
+var outputsLsCmd = &cobra.Command{
+	Use:   "ls <state_store_url>",
+	Short: "List the cached block ranges of each module hash, and the gaps between them",
+	Args:  cobra.ExactArgs(1),
+	RunE:  outputsLsE,
+}
+
+var outputsDumpCmd = &cobra.Command{
+	Use:   "dump <state_store_url> <package> <module_name>",
+	Short: "Print the cached outputs of a module, decoded with the package's protobuf definitions, as JSON lines",
+	Args:  cobra.ExactArgs(3),
+	RunE:  outputsDumpE,
+}
+
+var outputsGcCmd = &cobra.Command{
+	Use:   "gc <state_store_url> [<package>...]",
+	Short: "Delete the output caches of module hashes not found in the given packages, or not accessed for --unaccessed-days",
+	Long: `Deletes the output caches of a module hash when either:
+
+* packages are given, and none of their modules has this hash,
+* --unaccessed-days is set, and no request used the caches for that many days.
+
+Caches written before access times were recorded have no known access
+time, and are only deleted based on packages.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: outputsGcE,
+}
+
+func init() {
+	outputsDumpCmd.Flags().Uint64P("start-block", "s", 0, "First block to print")
+	outputsDumpCmd.Flags().Uint64P("stop-block", "t", 0, "Stop block (exclusive), 0 for all the cached blocks")
+
+	outputsGcCmd.Flags().Bool("dry-run", false, "Only list the module hashes that would be deleted")
+	outputsGcCmd.Flags().Int("unaccessed-days", 0, "Delete caches not accessed for this many days, 0 to disable")
+
+	outputsCmd.AddCommand(outputsLsCmd)
+	outputsCmd.AddCommand(outputsDumpCmd)
+	outputsCmd.AddCommand(outputsGcCmd)
+	Cmd.AddCommand(outputsCmd)
+}
+
+func outputsLsE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	store, err := dstore.NewStore(args[0], "", "", false)
+	if err != nil {
+		return fmt.Errorf("creating store: %w", err)
+	}
+
+	moduleCaches, err := outputs.ListModuleCaches(ctx, store)
+	if err != nil {
+		return fmt.Errorf("listing output caches: %w", err)
+	}
+
+	for _, m := range moduleCaches {
+		lastAccess := "unknown"
+		if m.HasAccess {
+			moduleStore, err := outputs.ModuleStore(store, m.ModuleHash)
+			if err != nil {
+				return fmt.Errorf("creating module store: %w", err)
+			}
+			access, err := outputs.ReadAccess(ctx, moduleStore)
+			if err != nil {
+				return fmt.Errorf("reading access of %s: %w", m.ModuleHash, err)
+			}
+			if access != nil {
+				lastAccess = access.LastAccessed.Format(time.RFC3339)
+			}
+		}
+
+		fmt.Printf("%s (%d files, last accessed: %s)\n", m.ModuleHash, len(m.Files), lastAccess)
+		fmt.Printf("  ranges: %s\n", m.Ranges())
+		if gaps := m.Gaps(); len(gaps) != 0 {
+			fmt.Printf("  gaps:   %s\n", gaps)
+		}
+	}
+	return nil
+}
+
+func outputsDumpE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	startBlock := mustGetUint64(cmd, "start-block")
+	stopBlock := mustGetUint64(cmd, "stop-block")
+
+	store, err := dstore.NewStore(args[0], "", "", false)
+	if err != nil {
+		return fmt.Errorf("creating store: %w", err)
+	}
+
+	pkg, err := manifest.NewReader(args[1]).Read()
+	if err != nil {
+		return fmt.Errorf("read manifest %q: %w", args[1], err)
+	}
+
+	graph, err := manifest.NewModuleGraph(pkg.Modules.Modules)
+	if err != nil {
+		return fmt.Errorf("creating module graph: %w", err)
+	}
+
+	var module *pbsubstreams.Module
+	for _, mod := range pkg.Modules.Modules {
+		if mod.Name == args[2] {
+			module = mod
+		}
+	}
+	if module == nil {
+		return fmt.Errorf("module %q not found in package", args[2])
+	}
+	hash := manifest.HashModuleAsString(pkg.Modules, graph, module)
+
+	dumper, err := newOutputDumper(pkg, module)
+	if err != nil {
+		return err
+	}
+
+	moduleStore, err := outputs.ModuleStore(store, hash)
+	if err != nil {
+		return fmt.Errorf("creating module store: %w", err)
+	}
+
+	moduleCaches, err := outputs.ListModuleCaches(ctx, store)
+	if err != nil {
+		return fmt.Errorf("listing output caches: %w", err)
+	}
+
+	for _, m := range moduleCaches {
+		if m.ModuleHash != hash {
+			continue
+		}
+		for _, file := range m.Files {
+			if file.Range.ExclusiveEndBlock <= startBlock || (stopBlock != 0 && file.Range.StartBlock >= stopBlock) {
+				continue
+			}
+
+			items, _, err := outputs.ReadCacheFile(ctx, moduleStore, file.Filename)
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				if item.BlockNum < startBlock || (stopBlock != 0 && item.BlockNum >= stopBlock) {
+					continue
+				}
+				cnt, err := dumper.dump(item)
+				if err != nil {
+					return fmt.Errorf("block %d: %w", item.BlockNum, err)
+				}
+				fmt.Println(string(cnt))
+			}
+		}
+		return nil
+	}
+
+	zlog.Info("no output caches found", zap.String("module_name", module.Name), zap.String("module_hash", hash))
+	return nil
+}
+
+func outputsGcE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	dryRun := mustGetBool(cmd, "dry-run")
+	unaccessedDays, _ := cmd.Flags().GetInt("unaccessed-days")
+
+	packages := args[1:]
+	if len(packages) == 0 && unaccessedDays <= 0 {
+		return fmt.Errorf("nothing to collect: give packages to keep, or --unaccessed-days")
+	}
+
+	store, err := dstore.NewStore(args[0], "", "", false)
+	if err != nil {
+		return fmt.Errorf("creating store: %w", err)
+	}
+
+	keep := map[string]bool{}
+	for _, manifestPath := range packages {
+		pkg, err := manifest.NewReader(manifestPath).Read()
+		if err != nil {
+			return fmt.Errorf("read manifest %q: %w", manifestPath, err)
+		}
+		graph, err := manifest.NewModuleGraph(pkg.Modules.Modules)
+		if err != nil {
+			return fmt.Errorf("creating module graph for %q: %w", manifestPath, err)
+		}
+		for _, module := range pkg.Modules.Modules {
+			keep[manifest.HashModuleAsString(pkg.Modules, graph, module)] = true
+		}
+	}
+
+	moduleCaches, err := outputs.ListModuleCaches(ctx, store)
+	if err != nil {
+		return fmt.Errorf("listing output caches: %w", err)
+	}
+
+	now := time.Now()
+	for _, m := range moduleCaches {
+		moduleStore, err := outputs.ModuleStore(store, m.ModuleHash)
+		if err != nil {
+			return fmt.Errorf("creating module store: %w", err)
+		}
+
+		var reason string
+		if len(packages) != 0 && !keep[m.ModuleHash] {
+			reason = "not in any package"
+		} else if unaccessedDays > 0 && m.HasAccess {
+			access, err := outputs.ReadAccess(ctx, moduleStore)
+			if err != nil {
+				zlog.Warn("reading output cache access", zap.String("module_hash", m.ModuleHash), zap.Error(err))
+			} else if access != nil && now.Sub(access.LastAccessed) > time.Duration(unaccessedDays)*24*time.Hour {
+				reason = fmt.Sprintf("not accessed since %s", access.LastAccessed.Format(time.RFC3339))
+			}
+		}
+		if reason == "" {
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("would delete %s, %d files (%s)\n", m.ModuleHash, len(m.Files), reason)
+			continue
+		}
+
+		zlog.Info("deleting output caches", zap.String("module_hash", m.ModuleHash), zap.Int("file_count", len(m.Files)), zap.String("reason", reason))
+		var filenames []string
+		for _, file := range m.Files {
+			filenames = append(filenames, file.Filename)
+		}
+		if m.HasAccess {
+			filenames = append(filenames, outputs.AccessFileName)
+		}
+		for _, filename := range filenames {
+			if err := moduleStore.DeleteObject(ctx, filename); err != nil {
+				zlog.Warn("error deleting file", zap.String("filename", filename), zap.String("module_hash", m.ModuleHash), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// outputDumper turns cache items into JSON, decoding the payloads with
+// the package's protobuf definitions when the output type is found.
+type outputDumper struct {
+	module  *pbsubstreams.Module
+	msgType string
+	msgDesc *desc.MessageDescriptor
+}
+
+func newOutputDumper(pkg *pbsubstreams.Package, module *pbsubstreams.Module) (*outputDumper, error) {
+	d := &outputDumper{module: module}
+	switch kind := module.Kind.(type) {
+	case *pbsubstreams.Module_KindMap_:
+		d.msgType = kind.KindMap.OutputType
+	case *pbsubstreams.Module_KindStore_:
+		d.msgType = kind.KindStore.ValueType
+	}
+	d.msgType = strings.TrimPrefix(d.msgType, "proto:")
+
+	fileDescs, err := desc.CreateFileDescriptors(pkg.ProtoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("creating file descriptors: %w", err)
+	}
+	for _, file := range fileDescs {
+		if d.msgDesc = file.FindMessage(d.msgType); d.msgDesc != nil {
+			break
+		}
+	}
+	return d, nil
+}
+
+type dumpedOutput struct {
+	Module   string          `json:"@module"`
+	BlockNum uint64          `json:"@block"`
+	BlockID  string          `json:"@id"`
+	Type     string          `json:"@type"`
+	Data     json.RawMessage `json:"@data,omitempty"`
+	Deltas   []*dumpedDelta  `json:"@deltas,omitempty"`
+}
+
+type dumpedDelta struct {
+	Operation string          `json:"op"`
+	Ordinal   uint64          `json:"ordinal"`
+	Key       string          `json:"key"`
+	OldValue  json.RawMessage `json:"old"`
+	NewValue  json.RawMessage `json:"new"`
+}
+
+func (d *outputDumper) dump(item *outputs.CacheItem) ([]byte, error) {
+	out := &dumpedOutput{
+		Module:   d.module.Name,
+		BlockNum: item.BlockNum,
+		BlockID:  item.BlockID,
+		Type:     d.msgType,
+	}
+
+	if d.module.GetKindStore() == nil {
+		out.Data = d.decode(item.Payload)
+		return json.Marshal(out)
+	}
+
+	deltas := &pbsubstreams.StoreDeltas{}
+	if err := proto.Unmarshal(item.Payload, deltas); err != nil {
+		return nil, fmt.Errorf("unmarshalling store deltas: %w", err)
+	}
+	for _, delta := range deltas.Deltas {
+		out.Deltas = append(out.Deltas, &dumpedDelta{
+			Operation: delta.Operation.String(),
+			Ordinal:   delta.Ordinal,
+			Key:       delta.Key,
+			OldValue:  d.decode(delta.OldValue),
+			NewValue:  d.decode(delta.NewValue),
+		})
+	}
+	return json.Marshal(out)
+}
+
+// decode falls back to a JSON string of the raw bytes when the payload
+// cannot be decoded with the message descriptor.
+func (d *outputDumper) decode(in []byte) json.RawMessage {
+	if d.msgDesc != nil {
+		dynMsg := dynamic.NewMessageFactoryWithDefaults().NewDynamicMessage(d.msgDesc)
+		if err := dynMsg.Unmarshal(in); err == nil {
+			if cnt, err := dynMsg.MarshalJSON(); err == nil {
+				return cnt
+			}
+		}
+	}
+
+	var cnt []byte
+	if d.msgType == "bytes" || d.msgDesc != nil {
+		cnt, _ = json.Marshal(in) // base64
+	} else {
+		cnt, _ = json.Marshal(string(in))
+	}
+	return cnt
+}
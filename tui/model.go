@@ -7,6 +7,7 @@ import (
 func newModel(ui *TUI) model {
 	return model{
 		Modules:     updatedRanges{},
+		Throughputs: map[string]*pbsubstreams.ModuleProgress_Throughput{},
 		ui:          ui,
 		screenWidth: 120,
 	}
@@ -18,6 +19,7 @@ type model struct {
 	screenWidth int
 
 	Modules           updatedRanges
+	Throughputs       map[string]*pbsubstreams.ModuleProgress_Throughput
 	BarMode           bool
 	DebugSetting      bool
 	Updates           int
@@ -20,7 +20,7 @@ func (ui *TUI) decoratedBlockScopedData(output *pbsubstreams.BlockScopedData) er
 	var s []string
 	for _, out := range output.Outputs {
 		for _, log := range out.Logs {
-			s = append(s, fmt.Sprintf("%s: log: %s\n", out.Name, log))
+			s = append(s, fmt.Sprintf("%s: log: %s\n", out.Name, log.Format()))
 		}
 
 		switch data := out.Data.(type) {
@@ -181,13 +181,24 @@ func (ui *TUI) jsonSnapshotData(output *pbsubstreams.InitialSnapshotData) error
 }
 
 func (ui *TUI) formatPostDataProgress(msg *pbsubstreams.Response_Progress) {
-	var displayedFailure bool
+	var displayedFailure, displayedThroughput bool
 	for _, mod := range msg.Progress.Modules {
 		switch progMsg := mod.Type.(type) {
 		case *pbsubstreams.ModuleProgress_ProcessedRanges:
 			fmt.Println("debug: still processing ranges after data?")
 		case *pbsubstreams.ModuleProgress_InitialState_:
 		case *pbsubstreams.ModuleProgress_ProcessedBytes_:
+		case *pbsubstreams.ModuleProgress_ExecutionStats_:
+		case *pbsubstreams.ModuleProgress_Throughput_:
+			// All output modules progress at the same pace, show a single line
+			if ui.decorateOutput && !displayedThroughput {
+				processed := humanize.Comma(int64(progMsg.Throughput.ProcessedBlocks))
+				if progMsg.Throughput.TotalBlocks != 0 {
+					processed += "/" + humanize.Comma(int64(progMsg.Throughput.TotalBlocks))
+				}
+				fmt.Printf("progress: %s blocks processed, %s\n", processed, formatThroughput(progMsg.Throughput))
+				displayedThroughput = true
+			}
 		case *pbsubstreams.ModuleProgress_Failed_:
 			failure := progMsg.Failed
 			if !displayedFailure {
@@ -201,7 +212,7 @@ func (ui *TUI) formatPostDataProgress(msg *pbsubstreams.Response_Progress) {
 			}
 			if len(failure.Logs) != 0 {
 				for _, log := range failure.Logs {
-					fmt.Printf("%s: log: %s\n", mod.Name, log)
+					fmt.Printf("%s: log: %s\n", mod.Name, log.Format())
 				}
 				if failure.LogsTruncated {
 					fmt.Printf("%s: <logs truncated>\n", mod.Name)
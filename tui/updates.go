@@ -68,10 +68,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.Modules = newModules
 		case *pbsubstreams.ModuleProgress_InitialState_:
 		case *pbsubstreams.ModuleProgress_ProcessedBytes_:
+		case *pbsubstreams.ModuleProgress_ExecutionStats_:
+		case *pbsubstreams.ModuleProgress_Throughput_:
+			newThroughputs := map[string]*pbsubstreams.ModuleProgress_Throughput{}
+			for k, v := range m.Throughputs {
+				newThroughputs[k] = v
+			}
+			newThroughputs[msg.Name] = progMsg.Throughput
+
+			m.Throughputs = newThroughputs
 		case *pbsubstreams.ModuleProgress_Failed_:
 			m.Failures += 1
 			if progMsg.Failed.Reason != "" {
-				m.Reason = fmt.Sprintf("Reason: %s, logs: %s, truncated: %v", progMsg.Failed.Reason, progMsg.Failed.Logs, progMsg.Failed.LogsTruncated)
+				m.Reason = fmt.Sprintf("Reason: %s, logs: %s, truncated: %v", progMsg.Failed.Reason, pbsubstreams.FormatLogs(progMsg.Failed.Logs), progMsg.Failed.LogsTruncated)
 			}
 			m.LastFailure = progMsg.Failed
 			return m, nil
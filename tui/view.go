@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/dustin/go-humanize"
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 )
 
 var viewTpl = `
@@ -20,6 +22,7 @@ Connected - Progress messages received: {{ .Updates }} ({{ .UpdatesPerSecond }}/
 {{- else }}
   {{ pad 25 $key }}{{ printf "%d" $value.Lo | rpad 10 }}  ::  {{ linebar $value $ }}
 {{- end -}}
+{{- with index $.Throughputs $key }}  {{ throughput . }}{{ end -}}
 {{ end }}{{ end }}
 {{ if .Failures }}
 Failures: {{ .Failures }}.
@@ -27,7 +30,7 @@ Last failure:
   Reason: {{ .LastFailure.Reason }}
   Logs:
 {{ range .LastFailure.Logs }}
-    {{ . }}
+    {{ .Format }}
 {{ end }}
 {{- with .LastFailure.LogsTruncated }}  <logs truncated>{{ end }}
 {{ end -}}
@@ -51,6 +54,7 @@ var tpl = template.Must(template.New("tpl").Funcs(template.FuncMap{
 	"humanize": func(in uint64) string {
 		return humanize.Comma(int64(in))
 	},
+	"throughput": formatThroughput,
 	"linebar": func(ranges ranges, m model) string {
 		return linebar(ranges, m.Modules.Lo(), uint64(m.Request.StartBlockNum), m.screenWidth)
 	},
@@ -67,6 +71,14 @@ func (m model) View() string {
 	return buf.String()
 }
 
+func formatThroughput(t *pbsubstreams.ModuleProgress_Throughput) string {
+	out := fmt.Sprintf("%.1f blocks/sec", t.BlocksPerSecond)
+	if t.EtaSeconds != 0 {
+		out += fmt.Sprintf(", ETA %s", time.Duration(t.EtaSeconds)*time.Second)
+	}
+	return out
+}
+
 func linebar(ranges ranges, initialBlock uint64, startBlock uint64, screenWidth int) string {
 	// Make it 4 times more granular, with the Quadrants here: https://www.compart.com/en/unicode/block/U+2580
 	blocksWidth := startBlock - initialBlock
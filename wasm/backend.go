@@ -0,0 +1,154 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/streamingfast/substreams/wasm/wasmbin"
+)
+
+// Backend is a wasm engine compiling and running the modules. The
+// `Module`, `Instance` and `Heap` only interact with wasm code through it.
+type Backend interface {
+	Name() string
+
+	// NewModule compiles `code`, resolving its imports against `imports`.
+	NewModule(ctx context.Context, code []byte, imports HostImports, config *BackendConfig) (BackendModule, error)
+}
+
+// BackendConfig holds the runtime settings a backend applies to modules.
+type BackendConfig struct {
+	InstructionBudget uint64        // per call, 0 for unlimited
+	ExecutionTimeout  time.Duration // per call, 0 for none
+	MaxMemoryPages    uint32        // per instance, 0 for unlimited
+
+	// Cache of compiled modules, nil to always compile. Backends may
+	// ignore it, and keep their own.
+	Cache *ModuleCache
+}
+
+// BackendModule is a compiled wasm module.
+type BackendModule interface {
+	// NewInstance instantiates the module, checking that it exports a
+	// `memory`, an `alloc` function and the `entrypoint` function.
+	NewInstance(entrypoint string) (BackendInstance, error)
+}
+
+// BackendInstance is an instantiated wasm module.
+type BackendInstance interface {
+	// Memory returns the linear memory of the instance. The slice is
+	// only valid until the guest runs again, as it can grow.
+	Memory() []byte
+
+	// Call calls an exported function.
+	Call(function string, args ...Value) ([]Value, error)
+
+	// Execute calls an exported function with the execution limits of
+	// the `BackendConfig`, failing with an `*ExecutionLimitError`.
+	Execute(function string, args ...Value) error
+
+	// Close releases the instance, which cannot be used afterwards.
+	Close()
+}
+
+// ValueType is the type of a wasm value.
+type ValueType = wasmbin.ValueType
+
+const (
+	I32 = wasmbin.I32
+	I64 = wasmbin.I64
+	F32 = wasmbin.F32
+	F64 = wasmbin.F64
+)
+
+// Value is a wasm value, encoded on 64 bits according to its type.
+type Value uint64
+
+func NewI32(v int32) Value   { return Value(uint32(v)) }
+func NewI64(v int64) Value   { return Value(v) }
+func NewF32(v float32) Value { return Value(math.Float32bits(v)) }
+func NewF64(v float64) Value { return Value(math.Float64bits(v)) }
+
+func (v Value) I32() int32   { return int32(uint32(v)) }
+func (v Value) I64() int64   { return int64(v) }
+func (v Value) F32() float32 { return math.Float32frombits(uint32(v)) }
+func (v Value) F64() float64 { return math.Float64frombits(uint64(v)) }
+
+// toValues converts the Go arguments of an exported function call.
+func toValues(args []interface{}) ([]Value, error) {
+	out := make([]Value, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case Value:
+			out[i] = v
+		case int32:
+			out[i] = NewI32(v)
+		case int:
+			out[i] = NewI32(int32(v))
+		case int64:
+			out[i] = NewI64(v)
+		case float32:
+			out[i] = NewF32(v)
+		case float64:
+			out[i] = NewF64(v)
+		default:
+			return nil, fmt.Errorf("unsupported argument %d of type %T", i, arg)
+		}
+	}
+	return out, nil
+}
+
+// HostFunction is a Go function imported by wasm modules.
+type HostFunction struct {
+	Params  []ValueType
+	Results []ValueType
+	Func    func(args []Value) ([]Value, error)
+}
+
+// HostImports are the host functions by namespace and name.
+type HostImports map[string]map[string]*HostFunction
+
+func Params(types ...ValueType) []ValueType {
+	return types
+}
+
+func Returns(types ...ValueType) []ValueType {
+	return types
+}
+
+// backends are shared by all runtimes, so they must be safe for
+// concurrent use.
+var backends = map[string]Backend{}
+
+// defaultBackendName is the backend used when none is selected: the
+// wasmer backend when built with cgo, the pure Go one otherwise.
+var defaultBackendName = WazeroBackendName
+
+// Backends returns the names of the backends available in this build.
+func Backends() (out []string) {
+	for name := range backends {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NewBackend returns the backend named `name`, as listed by `Backends`.
+func NewBackend(name string) (Backend, error) {
+	backend, found := backends[name]
+	if !found {
+		return nil, fmt.Errorf("unknown wasm backend %q, available backends: %v", name, Backends())
+	}
+	return backend, nil
+}
+
+// WithBackend runs the modules with `backend` instead of the default
+// backend.
+func WithBackend(backend Backend) RuntimeOption {
+	return func(r *Runtime) {
+		r.backend = backend
+	}
+}
@@ -0,0 +1,491 @@
+//go:build cgo
+
+package wasm
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/wasmerio/wasmer-go/wasmer"
+	"go.uber.org/zap"
+)
+
+// WasmerBackendName is the cgo backend based on wasmer, the default
+// when available.
+const WasmerBackendName = "wasmer"
+
+func init() {
+	backends[WasmerBackendName] = &wasmerBackend{}
+	defaultBackendName = WasmerBackendName
+}
+
+type wasmerBackend struct{}
+
+func (b *wasmerBackend) Name() string { return WasmerBackendName }
+
+func (b *wasmerBackend) NewModule(ctx context.Context, code []byte, imports HostImports, config *BackendConfig) (BackendModule, error) {
+	// wasmer has no memory limit setting, the maximum of the memories is
+	// set in the binary instead
+	var edits codeEdits
+	if config.MaxMemoryPages != 0 {
+		limited, edit, err := limitMemoryPages(code, config.MaxMemoryPages)
+		if err != nil {
+			return nil, err
+		}
+		code = limited
+		edits = append(edits, edit)
+	}
+
+	// Exported to be reset when reusing instances, modules not supporting
+	// it are never reused
+	var globals []string
+	resettable := false
+	if exported, names, edit, err := exportMutableGlobals(code); err == nil {
+		code = exported
+		globals = names
+		resettable = true
+		edits = append(edits, edit)
+	} else {
+		zlog.Debug("instances of wasm module cannot be reset", zap.Error(err))
+	}
+
+	engine := newWasmerEngine(config)
+	store := wasmer.NewStore(engine)
+
+	module, err := compileWasmer(store, code, config)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &wasmerModule{
+		module:     module,
+		edits:      edits,
+		imports:    wasmer.NewImportObject(),
+		config:     config,
+		globals:    globals,
+		resettable: resettable,
+	}
+	for namespace, functions := range imports {
+		externs := map[string]wasmer.IntoExtern{}
+		for name, f := range functions {
+			externs[name] = m.newHostFunction(store, f)
+		}
+		m.imports.Register(namespace, externs)
+	}
+
+	return m, nil
+}
+
+func toWasmerKinds(types []ValueType) []*wasmer.ValueType {
+	kinds := make([]wasmer.ValueKind, len(types))
+	for i, t := range types {
+		switch t {
+		case I32:
+			kinds[i] = wasmer.I32
+		case I64:
+			kinds[i] = wasmer.I64
+		case F32:
+			kinds[i] = wasmer.F32
+		case F64:
+			kinds[i] = wasmer.F64
+		default:
+			panic(fmt.Sprintf("invalid value type %d", t))
+		}
+	}
+	return wasmer.NewValueTypes(kinds...)
+}
+
+func fromWasmerValue(v wasmer.Value) Value {
+	switch v.Kind() {
+	case wasmer.I32:
+		return NewI32(v.I32())
+	case wasmer.I64:
+		return NewI64(v.I64())
+	case wasmer.F32:
+		return NewF32(v.F32())
+	case wasmer.F64:
+		return NewF64(v.F64())
+	default:
+		panic(fmt.Sprintf("unsupported value kind %s", v.Kind()))
+	}
+}
+
+func toWasmerValue(v Value, t ValueType) wasmer.Value {
+	switch t {
+	case I32:
+		return wasmer.NewI32(v.I32())
+	case I64:
+		return wasmer.NewI64(v.I64())
+	case F32:
+		return wasmer.NewF32(v.F32())
+	case F64:
+		return wasmer.NewF64(v.F64())
+	default:
+		panic(fmt.Sprintf("invalid value type %d", t))
+	}
+}
+
+func (m *wasmerModule) newHostFunction(store *wasmer.Store, f *HostFunction) *wasmer.Function {
+	return wasmer.NewFunction(
+		store,
+		wasmer.NewFunctionType(toWasmerKinds(f.Params), toWasmerKinds(f.Results)),
+		func(args []wasmer.Value) ([]wasmer.Value, error) {
+			in := make([]Value, len(args))
+			for i, arg := range args {
+				in[i] = fromWasmerValue(arg)
+			}
+
+			out, err := f.Func(in)
+			if err != nil {
+				m.hostErr = err
+				return nil, err
+			}
+
+			results := make([]wasmer.Value, len(out))
+			for i, v := range out {
+				results[i] = toWasmerValue(v, f.Results[i])
+			}
+			return results, nil
+		},
+	)
+}
+
+type wasmerModule struct {
+	module  *wasmer.Module
+	edits   codeEdits // of the compiled binary
+	imports *wasmer.ImportObject
+	config  *BackendConfig
+
+	globals    []string // exports of the mutable globals
+	resettable bool
+
+	// hostErr is the error returned by the last failing host function.
+	// wasmer only reports its message in a trap, it is returned as is
+	// instead.
+	hostErr error
+}
+
+func (m *wasmerModule) NewInstance(entrypoint string) (BackendInstance, error) {
+	instance, err := wasmer.NewInstance(m.module, m.imports)
+	if err != nil {
+		return nil, fmt.Errorf("creating instance: %w", err)
+	}
+
+	memory, err := instance.Exports.GetMemory("memory")
+	if err != nil {
+		return nil, fmt.Errorf("getting module memory: %w", err)
+	}
+
+	if _, err := instance.Exports.GetRawFunction("alloc"); err != nil {
+		return nil, fmt.Errorf("getting alloc function: %w", err)
+	}
+
+	if _, err := instance.Exports.GetRawFunction(entrypoint); err != nil {
+		return nil, fmt.Errorf("getting wasm module function %q: %w", entrypoint, err)
+	}
+
+	i := &wasmerInstance{
+		module:   m,
+		instance: instance,
+		memory:   memory,
+		config:   m.config,
+	}
+	for _, name := range m.globals {
+		global, err := instance.Exports.GetGlobal(name)
+		if err != nil {
+			return nil, fmt.Errorf("getting global %q: %w", name, err)
+		}
+		value, err := global.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting global %q: %w", name, err)
+		}
+		i.globals = append(i.globals, global)
+		i.globalKinds = append(i.globalKinds, global.Type().ValueType().Kind())
+		i.initialGlobals = append(i.initialGlobals, value)
+	}
+	return i, nil
+}
+
+type wasmerInstance struct {
+	module   *wasmerModule
+	instance *wasmer.Instance
+	memory   *wasmer.Memory
+	config   *BackendConfig
+
+	globals        []*wasmer.Global
+	globalKinds    []wasmer.ValueKind
+	initialGlobals []interface{}
+}
+
+func (i *wasmerInstance) resettable() bool {
+	return i.module.resettable
+}
+
+func (i *wasmerInstance) resetGlobals() {
+	for idx, global := range i.globals {
+		// Mutable globals, set to values of their own types: it cannot
+		// fail
+		_ = global.Set(i.initialGlobals[idx], i.globalKinds[idx])
+	}
+}
+
+func (i *wasmerInstance) Memory() []byte {
+	return i.memory.Data()
+}
+
+func (i *wasmerInstance) Call(function string, args ...Value) ([]Value, error) {
+	f, err := i.instance.Exports.GetRawFunction(function)
+	if err != nil {
+		return nil, fmt.Errorf("getting wasm module function %q: %w", function, err)
+	}
+
+	params := f.Type().Params()
+	if len(params) != len(args) {
+		return nil, fmt.Errorf("function %q takes %d arguments, got %d", function, len(params), len(args))
+	}
+	in := make([]interface{}, len(args))
+	for idx, arg := range args {
+		switch params[idx].Kind() {
+		case wasmer.I32:
+			in[idx] = arg.I32()
+		case wasmer.I64:
+			in[idx] = arg.I64()
+		case wasmer.F32:
+			in[idx] = arg.F32()
+		case wasmer.F64:
+			in[idx] = arg.F64()
+		default:
+			return nil, fmt.Errorf("function %q: unsupported argument kind %s", function, params[idx].Kind())
+		}
+	}
+
+	i.module.hostErr = nil
+	res, err := f.Call(in...)
+	if err != nil {
+		if hostErr := i.module.hostErr; hostErr != nil {
+			return nil, hostErr
+		}
+		return nil, newWasmerTrapError(err, i.module.edits)
+	}
+
+	switch v := res.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		out := make([]Value, len(v))
+		for idx, r := range v {
+			if out[idx], err = fromWasmerResult(r); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		out, err := fromWasmerResult(v)
+		if err != nil {
+			return nil, err
+		}
+		return []Value{out}, nil
+	}
+}
+
+// newWasmerTrapError adds the call stack of wasmer traps to `err`, with
+// the offsets of the binary before `edits`.
+func newWasmerTrapError(err error, edits codeEdits) error {
+	var trap *wasmer.TrapError
+	if !errors.As(err, &trap) {
+		return err
+	}
+
+	var frames []StackFrame
+	for _, frame := range trap.Trace() {
+		frames = append(frames, StackFrame{
+			FunctionIndex: int64(frame.FunctionIndex()),
+			ModuleOffset:  edits.originalOffset(uint64(frame.ModuleOffset())),
+		})
+	}
+	return &TrapError{Err: errors.New(trap.Error()), Frames: frames}
+}
+
+func fromWasmerResult(r interface{}) (Value, error) {
+	switch v := r.(type) {
+	case int32:
+		return NewI32(v), nil
+	case int64:
+		return NewI64(v), nil
+	case float32:
+		return NewF32(v), nil
+	case float64:
+		return NewF64(v), nil
+	default:
+		return 0, fmt.Errorf("unsupported result of type %T", r)
+	}
+}
+
+func (i *wasmerInstance) metered() bool {
+	return i.config.InstructionBudget != 0 || i.config.ExecutionTimeout != 0
+}
+
+// Execute calls the function, interrupting it when the timeout is
+// reached by exhausting its metering points.
+func (i *wasmerInstance) Execute(function string, args ...Value) error {
+	if !i.metered() {
+		_, err := i.Call(function, args...)
+		return err
+	}
+
+	i.instance.SetRemainingPoints(wasmerMeteringBudget(i.config))
+
+	var timedOut int32
+	if i.config.ExecutionTimeout != 0 {
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+
+		timer := time.AfterFunc(i.config.ExecutionTimeout, func() {
+			defer close(stopped)
+			atomic.StoreInt32(&timedOut, 1)
+
+			// Setting 0 points is ignored by the metering middleware,
+			// 1 is exhausted by the next metered block. The guest updates
+			// its points without synchronization and can overwrite them,
+			// so they are set until the call returns.
+			ticker := time.NewTicker(time.Millisecond)
+			defer ticker.Stop()
+			for {
+				i.instance.SetRemainingPoints(1)
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+				}
+			}
+		})
+		defer func() {
+			if !timer.Stop() {
+				// Not touching the points of the next call
+				close(done)
+				<-stopped
+			}
+		}()
+	}
+
+	_, err := i.Call(function, args...)
+	if err == nil {
+		return nil
+	}
+
+	if atomic.LoadInt32(&timedOut) == 1 {
+		return &ExecutionLimitError{Timeout: i.config.ExecutionTimeout}
+	}
+	if i.instance.MeteringPointsExhausted() {
+		return &ExecutionLimitError{Budget: i.config.InstructionBudget}
+	}
+	return err
+}
+
+func (i *wasmerInstance) Close() {
+	i.instance.Close()
+}
+
+// lastOpcode is the highest operator known to the wasmer metering middleware.
+const lastOpcode = wasmer.I32x4TruncSatF64x2UZero
+
+// wasmerMeteringBudget is the number of points given to each call. The
+// timeout relies on metering to interrupt the guest, so it gets an
+// unlimited budget when no instruction budget is set.
+func wasmerMeteringBudget(config *BackendConfig) uint64 {
+	if config.InstructionBudget != 0 {
+		return config.InstructionBudget
+	}
+	return math.MaxUint64
+}
+
+func newWasmerEngine(config *BackendConfig) *wasmer.Engine {
+	if config.InstructionBudget == 0 && config.ExecutionTimeout == 0 {
+		return wasmer.NewUniversalEngine()
+	}
+
+	costs := make(map[wasmer.Opcode]uint32, lastOpcode+1)
+	for op := wasmer.Opcode(0); op <= lastOpcode; op++ {
+		costs[op] = 1
+	}
+	engineConfig := wasmer.NewConfig().PushMeteringMiddleware(wasmerMeteringBudget(config), costs)
+	return wasmer.NewEngineWithConfig(engineConfig)
+}
+
+// wasmerEngineVersion identifies the compiler producing the artifacts,
+// which are only valid for the exact same wasmer version and platform.
+var wasmerEngineVersion = func() string {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path != "github.com/wasmerio/wasmer-go" {
+				continue
+			}
+			version = dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Path + "@" + dep.Replace.Version
+			}
+		}
+	}
+	return fmt.Sprintf("%s/%s/%s", version, runtime.GOOS, runtime.GOARCH)
+}()
+
+// wasmerCacheKey covers the engine configuration too: metering
+// instruments the compiled code, with the budget as initial points.
+func wasmerCacheKey(code []byte, config *BackendConfig) string {
+	var metering uint64
+	if config.InstructionBudget != 0 || config.ExecutionTimeout != 0 {
+		metering = wasmerMeteringBudget(config)
+	}
+
+	h := sha256.New()
+	h.Write(code)
+	fmt.Fprintf(h, "\x00%s\x00%s\x00metering=%d", WasmerBackendName, wasmerEngineVersion, metering)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// compileWasmer returns the compiled module from the cache when available.
+func compileWasmer(store *wasmer.Store, code []byte, config *BackendConfig) (*wasmer.Module, error) {
+	cache := config.Cache
+	if cache == nil {
+		return newWasmerModule(store, code)
+	}
+
+	key := wasmerCacheKey(code, config)
+	if artifact, found := cache.get(key); found {
+		module, err := wasmer.DeserializeModule(store, artifact)
+		if err == nil {
+			return module, nil
+		}
+		zlog.Warn("deserializing cached compiled module, compiling it again", zap.String("key", key), zap.Error(err))
+		cache.remove(key)
+	}
+
+	module, err := newWasmerModule(store, code)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, err := module.Serialize()
+	if err != nil {
+		zlog.Warn("serializing compiled module", zap.String("key", key), zap.Error(err))
+		return module, nil
+	}
+	cache.put(key, artifact)
+	return module, nil
+}
+
+func newWasmerModule(store *wasmer.Store, code []byte) (*wasmer.Module, error) {
+	module, err := wasmer.NewModule(store, code)
+	if err != nil {
+		return nil, fmt.Errorf("loading wasm module: %w", err)
+	}
+	return module, nil
+}
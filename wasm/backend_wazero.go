@@ -0,0 +1,320 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/sys"
+	"go.uber.org/zap"
+)
+
+// WazeroBackendName is the pure Go backend based on wazero, which does
+// not need cgo. It does not support instruction budgets.
+const WazeroBackendName = "wazero"
+
+func init() {
+	backends[WazeroBackendName] = &wazeroBackend{
+		compilationCache: wazero.NewCompilationCache(),
+	}
+}
+
+type wazeroBackend struct {
+	// compilationCache is shared by all modules: wazero keeps its own
+	// compiled modules, the `ModuleCache` is not used.
+	compilationCache wazero.CompilationCache
+}
+
+func (b *wazeroBackend) Name() string { return WazeroBackendName }
+
+func (b *wazeroBackend) NewModule(ctx context.Context, code []byte, imports HostImports, config *BackendConfig) (BackendModule, error) {
+	if config.InstructionBudget != 0 {
+		return nil, fmt.Errorf("the %s wasm backend does not support instruction budgets", WazeroBackendName)
+	}
+
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithCompilationCache(b.compilationCache).
+		WithCloseOnContextDone(config.ExecutionTimeout != 0)
+	if config.MaxMemoryPages != 0 {
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(config.MaxMemoryPages)
+	}
+
+	// Host functions are bound to the module through `imports`, so each
+	// module gets its own runtime
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+
+	m := &wazeroModule{
+		ctx:     ctx,
+		runtime: runtime,
+		config:  config,
+	}
+
+	for namespace, functions := range imports {
+		builder := runtime.NewHostModuleBuilder(namespace)
+		for name, f := range functions {
+			builder.NewFunctionBuilder().
+				WithGoModuleFunction(m.newHostFunction(f), toWazeroTypes(f.Params), toWazeroTypes(f.Results)).
+				Export(name)
+		}
+		if _, err := builder.Instantiate(ctx); err != nil {
+			return nil, fmt.Errorf("instantiating host module %q: %w", namespace, err)
+		}
+	}
+
+	// Exported to be reset when reusing instances, modules not supporting
+	// it are never reused. wazero symbolizes traps from the binary it
+	// runs, the edit needs no mapping.
+	if exported, globals, _, err := exportMutableGlobals(code); err == nil {
+		code = exported
+		m.globals = globals
+		m.resettable = true
+	} else {
+		zlog.Debug("instances of wasm module cannot be reset", zap.Error(err))
+	}
+
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("loading wasm module: %w", err)
+	}
+	m.compiled = compiled
+
+	return m, nil
+}
+
+func toWazeroTypes(types []ValueType) []api.ValueType {
+	out := make([]api.ValueType, len(types))
+	for i, t := range types {
+		switch t {
+		case I32:
+			out[i] = api.ValueTypeI32
+		case I64:
+			out[i] = api.ValueTypeI64
+		case F32:
+			out[i] = api.ValueTypeF32
+		case F64:
+			out[i] = api.ValueTypeF64
+		default:
+			panic(fmt.Sprintf("invalid value type %d", t))
+		}
+	}
+	return out
+}
+
+type wazeroModule struct {
+	ctx      context.Context
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	config   *BackendConfig
+
+	globals    []string // exports of the mutable globals
+	resettable bool
+
+	// hostErr is the error returned by the last failing host function.
+	// wazero reports it wrapped in a trap with the wasm stack trace, it
+	// is returned as is instead, like with the other backends.
+	hostErr error
+}
+
+func (m *wazeroModule) newHostFunction(f *HostFunction) api.GoModuleFunc {
+	return func(ctx context.Context, mod api.Module, stack []uint64) {
+		args := make([]Value, len(f.Params))
+		for i := range args {
+			args[i] = Value(stack[i])
+		}
+
+		results, err := f.Func(args)
+		if err != nil {
+			m.hostErr = err
+			panic(err)
+		}
+
+		for i, v := range results {
+			stack[i] = uint64(v)
+		}
+	}
+}
+
+func (m *wazeroModule) NewInstance(entrypoint string) (BackendInstance, error) {
+	// Anonymous, so the module can be instantiated many times
+	moduleConfig := wazero.NewModuleConfig().WithName("").WithStartFunctions()
+	mod, err := m.runtime.InstantiateModule(m.ctx, m.compiled, moduleConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating instance: %w", err)
+	}
+
+	if mod.ExportedMemory("memory") == nil {
+		return nil, fmt.Errorf("getting module memory: missing export \"memory\"")
+	}
+
+	if mod.ExportedFunction("alloc") == nil {
+		return nil, fmt.Errorf("getting alloc function: missing export \"alloc\"")
+	}
+
+	if mod.ExportedFunction(entrypoint) == nil {
+		return nil, fmt.Errorf("getting wasm module function %q: missing export %q", entrypoint, entrypoint)
+	}
+
+	instance := &wazeroInstance{
+		module: m,
+		mod:    mod,
+		memory: mod.ExportedMemory("memory"),
+	}
+	for _, name := range m.globals {
+		global, ok := mod.ExportedGlobal(name).(api.MutableGlobal)
+		if !ok {
+			return nil, fmt.Errorf("getting global %q: missing mutable export", name)
+		}
+		instance.globals = append(instance.globals, global)
+		instance.initialGlobals = append(instance.initialGlobals, global.Get())
+	}
+	return instance, nil
+}
+
+type wazeroInstance struct {
+	module *wazeroModule
+	mod    api.Module
+	memory api.Memory
+
+	globals        []api.MutableGlobal
+	initialGlobals []uint64
+}
+
+func (i *wazeroInstance) resettable() bool {
+	return i.module.resettable
+}
+
+func (i *wazeroInstance) resetGlobals() {
+	for idx, global := range i.globals {
+		global.Set(i.initialGlobals[idx])
+	}
+}
+
+func (i *wazeroInstance) Memory() []byte {
+	data, _ := i.memory.Read(0, i.memory.Size())
+	return data
+}
+
+func (i *wazeroInstance) Call(function string, args ...Value) ([]Value, error) {
+	return i.call(i.module.ctx, function, args)
+}
+
+func (i *wazeroInstance) call(ctx context.Context, function string, args []Value) ([]Value, error) {
+	f := i.mod.ExportedFunction(function)
+	if f == nil {
+		return nil, fmt.Errorf("getting wasm module function %q: missing export %q", function, function)
+	}
+
+	params := make([]uint64, len(args))
+	for idx, arg := range args {
+		params[idx] = uint64(arg)
+	}
+
+	i.module.hostErr = nil
+	results, err := f.Call(ctx, params...)
+	if err != nil {
+		if hostErr := i.module.hostErr; hostErr != nil {
+			return nil, hostErr
+		}
+		return nil, newWazeroTrapError(err)
+	}
+
+	out := make([]Value, len(results))
+	for idx, r := range results {
+		out[idx] = Value(r)
+	}
+	return out, nil
+}
+
+// wazeroStackTraceSeparator precedes the wasm stack trace in the
+// message of wazero traps.
+const wazeroStackTraceSeparator = "\nwasm stack trace:\n"
+
+// wazeroFrameSignature is the signature following the function name of
+// each frame, like `(i32,i32) i32`.
+var wazeroFrameSignature = regexp.MustCompile(`\([a-z0-9,]*\)( [a-z0-9]+| \([a-z0-9,]*\))?$`)
+
+// newWazeroTrapError reads the call stack wazero adds to the message of
+// traps. Frames are already symbolized, wazero reading the names and
+// DWARF sections itself.
+func newWazeroTrapError(err error) error {
+	message, trace, found := strings.Cut(err.Error(), wazeroStackTraceSeparator)
+	if !found {
+		return err
+	}
+
+	var frames []StackFrame
+	for _, line := range strings.Split(trace, "\n") {
+		if source := strings.TrimPrefix(line, "\t\t"); source != line {
+			if len(frames) == 0 {
+				continue
+			}
+			// `0x1f: file:line:column`, possibly followed by ` (inlined)`,
+			// the address being replaced by spaces for inlined calls
+			source = strings.TrimSpace(source)
+			if strings.HasPrefix(source, "0x") {
+				if _, position, found := strings.Cut(source, ": "); found {
+					source = position
+				}
+			}
+			source = strings.TrimSuffix(source, " (inlined)")
+			frames[len(frames)-1].Sources = append(frames[len(frames)-1].Sources, source)
+			continue
+		}
+
+		// `<module name>.<function name or $index><signature>`, the
+		// instances being anonymous
+		name := strings.TrimPrefix(strings.TrimPrefix(line, "\t"), ".")
+		if loc := wazeroFrameSignature.FindStringIndex(name); loc != nil {
+			name = name[:loc[0]]
+		}
+
+		frame := StackFrame{FunctionIndex: -1, Function: name}
+		if index, err := strconv.ParseUint(strings.TrimPrefix(name, "$"), 10, 32); err == nil && strings.HasPrefix(name, "$") {
+			frame = StackFrame{FunctionIndex: int64(index)}
+		}
+		frames = append(frames, frame)
+	}
+
+	return &TrapError{
+		Err:    &wazeroTrap{message: strings.TrimPrefix(message, "wasm error: "), err: err},
+		Frames: frames,
+	}
+}
+
+// wazeroTrap is a wazero trap without its stack trace.
+type wazeroTrap struct {
+	message string
+	err     error
+}
+
+func (e *wazeroTrap) Error() string { return e.message }
+func (e *wazeroTrap) Unwrap() error { return e.err }
+
+// Execute calls the function, closing the instance when the timeout is
+// reached.
+func (i *wazeroInstance) Execute(function string, args ...Value) error {
+	timeout := i.module.config.ExecutionTimeout
+	if timeout == 0 {
+		_, err := i.Call(function, args...)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(i.module.ctx, timeout)
+	defer cancel()
+
+	_, err := i.call(ctx, function, args)
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == sys.ExitCodeDeadlineExceeded {
+		return &ExecutionLimitError{Timeout: timeout}
+	}
+	return err
+}
+
+func (i *wazeroInstance) Close() {
+	_ = i.mod.Close(i.module.ctx)
+}
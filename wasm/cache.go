@@ -0,0 +1,141 @@
+package wasm
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ModuleCache keeps compiled wasm modules, keyed by the hash of their
+// binary and the engine that compiled them, so identical binaries are
+// compiled once across requests and subrequests. Compiled artifacts are
+// held in memory, and also written to a local directory when one is
+// given, so they survive restarts.
+type ModuleCache struct {
+	sync.Mutex
+
+	dir        string // empty to only cache in memory
+	maxEntries int
+
+	entries map[string]*list.Element
+	lru     *list.List // of *moduleCacheEntry, most recently used first
+}
+
+type moduleCacheEntry struct {
+	key      string
+	artifact []byte
+}
+
+// NewModuleCache keeps at most `maxEntries` compiled modules in memory.
+// When `dir` is not empty, compiled modules are also stored there.
+func NewModuleCache(maxEntries int, dir string) (*ModuleCache, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("creating compiled modules cache directory: %w", err)
+		}
+	}
+
+	return &ModuleCache{
+		dir:        dir,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}, nil
+}
+
+// WithModuleCache compiles modules through `cache`, which should be
+// shared by all the runtimes of a process. It is only used by the
+// wasmer backend, the wazero backend keeps its own in-memory cache.
+func WithModuleCache(cache *ModuleCache) RuntimeOption {
+	return func(r *Runtime) {
+		r.moduleCache = cache
+	}
+}
+
+func (c *ModuleCache) get(key string) ([]byte, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if el, found := c.entries[key]; found {
+		c.lru.MoveToFront(el)
+		return el.Value.(*moduleCacheEntry).artifact, true
+	}
+
+	if c.dir == "" {
+		return nil, false
+	}
+
+	artifact, err := os.ReadFile(c.filePath(key))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			zlog.Warn("reading compiled module from cache directory", zap.String("key", key), zap.Error(err))
+		}
+		return nil, false
+	}
+	c.addLocked(key, artifact)
+	return artifact, true
+}
+
+func (c *ModuleCache) put(key string, artifact []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	if _, found := c.entries[key]; found {
+		return
+	}
+	c.addLocked(key, artifact)
+
+	if c.dir == "" {
+		return
+	}
+
+	// Written to a temporary file first, so concurrent readers never
+	// see a partial artifact
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		zlog.Warn("creating compiled module cache file", zap.String("key", key), zap.Error(err))
+		return
+	}
+	_, err = tmp.Write(artifact)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.filePath(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		zlog.Warn("writing compiled module cache file", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (c *ModuleCache) remove(key string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if el, found := c.entries[key]; found {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+	if c.dir != "" {
+		_ = os.Remove(c.filePath(key))
+	}
+}
+
+func (c *ModuleCache) addLocked(key string, artifact []byte) {
+	c.entries[key] = c.lru.PushFront(&moduleCacheEntry{key: key, artifact: artifact})
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*moduleCacheEntry).key)
+	}
+}
+
+func (c *ModuleCache) filePath(key string) string {
+	return filepath.Join(c.dir, key+".wasmer")
+}
@@ -0,0 +1,98 @@
+//go:build cgo
+
+package wasm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func executeCount(t *testing.T, r *Runtime, code []byte, count int32) error {
+	t.Helper()
+
+	module, err := r.NewModule(context.Background(), &pbsubstreams.Request{}, code, "test_module")
+	require.NoError(t, err)
+	instance, err := module.NewInstance(&pbsubstreams.Clock{}, "count", nil)
+	require.NoError(t, err)
+	return instance.ExecuteWithArgs(count)
+}
+
+func TestModuleCache(t *testing.T) {
+	code := readTestWasm(t, "limits")
+
+	dir := t.TempDir()
+	cache, err := NewModuleCache(10, dir)
+	require.NoError(t, err)
+
+	r := NewRuntime(nil, WithModuleCache(cache))
+	require.NoError(t, executeCount(t, r, code, 10))
+	require.Equal(t, 1, cache.lru.Len())
+	files, err := filepath.Glob(filepath.Join(dir, "*.wasmer"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Served from memory
+	require.NoError(t, executeCount(t, r, code, 10))
+	assert.Equal(t, 1, cache.lru.Len())
+
+	// Served from the directory, after a restart
+	restarted, err := NewModuleCache(10, dir)
+	require.NoError(t, err)
+	require.NoError(t, executeCount(t, NewRuntime(nil, WithModuleCache(restarted)), code, 10))
+	assert.Equal(t, 1, restarted.lru.Len())
+
+	// A corrupted artifact is compiled again
+	require.NoError(t, os.WriteFile(files[0], []byte("garbage"), 0644))
+	corrupted, err := NewModuleCache(10, dir)
+	require.NoError(t, err)
+	require.NoError(t, executeCount(t, NewRuntime(nil, WithModuleCache(corrupted)), code, 10))
+	artifact, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotEqual(t, []byte("garbage"), artifact)
+}
+
+func TestModuleCache_Metering(t *testing.T) {
+	code := readTestWasm(t, "limits")
+
+	cache, err := NewModuleCache(10, "")
+	require.NoError(t, err)
+
+	unmetered := NewRuntime(nil, WithModuleCache(cache))
+	metered := NewRuntime(nil, WithModuleCache(cache), WithInstructionBudget(10_000))
+	assert.NotEqual(t, wasmerCacheKey(code, unmetered.backendConfig()), wasmerCacheKey(code, metered.backendConfig()))
+
+	require.NoError(t, executeCount(t, unmetered, code, 100_000))
+
+	// Both compiled and cached metered modules enforce the budget
+	for i := 0; i < 2; i++ {
+		err := executeCount(t, metered, code, 100_000)
+		var limitErr *ExecutionLimitError
+		require.True(t, errors.As(err, &limitErr), "got %v", err)
+	}
+	assert.Equal(t, 2, cache.lru.Len())
+}
+
+func TestModuleCache_Eviction(t *testing.T) {
+	cache, err := NewModuleCache(2, "")
+	require.NoError(t, err)
+
+	cache.put("a", []byte("a"))
+	cache.put("b", []byte("b"))
+	_, found := cache.get("a")
+	require.True(t, found)
+	cache.put("c", []byte("c"))
+
+	_, found = cache.get("b")
+	assert.False(t, found, "least recently used")
+	_, found = cache.get("a")
+	assert.True(t, found)
+	_, found = cache.get("c")
+	assert.True(t, found)
+}
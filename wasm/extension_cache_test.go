@@ -0,0 +1,106 @@
+package wasm
+
+import (
+	"context"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingExtension struct {
+	calls map[string]int
+}
+
+func (e *countingExtension) WASMExtensions() map[string]map[string]WASMExtension {
+	call := func(name string) WASMExtension {
+		return func(ctx context.Context, request *pbsubstreams.Request, clock *pbsubstreams.Clock, in []byte) ([]byte, error) {
+			e.calls[name]++
+			return []byte(name + ":" + string(in)), nil
+		}
+	}
+	return map[string]map[string]WASMExtension{
+		"myext": {
+			"cached": call("cached"),
+			"fresh":  call("fresh"),
+		},
+	}
+}
+
+func (e *countingExtension) CacheableWASMExtensions() map[string][]string {
+	return map[string][]string{"myext": {"cached"}}
+}
+
+type memoryExtensionCallCache map[string][]byte
+
+func (c memoryExtensionCallCache) Get(ctx context.Context, clock *pbsubstreams.Clock, key string) ([]byte, bool, error) {
+	out, found := c[key]
+	return out, found, nil
+}
+
+func (c memoryExtensionCallCache) Set(ctx context.Context, clock *pbsubstreams.Clock, key string, out []byte) error {
+	c[key] = out
+	return nil
+}
+
+func TestExtensionCallCache(t *testing.T) {
+	code := readTestWasm(t, "extension_cache")
+
+	tests := []struct {
+		name        string
+		withCache   bool
+		expectCalls map[string]int
+	}{
+		{
+			name:        "without cache",
+			expectCalls: map[string]int{"cached": 3, "fresh": 3},
+		},
+		{
+			name:        "with cache",
+			withCache:   true,
+			expectCalls: map[string]int{"cached": 2, "fresh": 3},
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				ext := &countingExtension{calls: map[string]int{}}
+				module, err := NewRuntime([]WASMExtensioner{ext}, WithBackend(backend)).NewModule(context.Background(), &pbsubstreams.Request{}, code, "test_module")
+				require.NoError(t, err)
+				if test.withCache {
+					module.SetExtensionCallCache(memoryExtensionCallCache{})
+				}
+
+				for _, clock := range []*pbsubstreams.Clock{
+					{Number: 1, Id: "1a"},
+					{Number: 1, Id: "1a"}, // reprocessed
+					{Number: 1, Id: "1b"}, // fork
+				} {
+					instance, err := module.NewInstance(clock, "call_extensions", nil)
+					require.NoError(t, err)
+					require.NoError(t, instance.Execute())
+				}
+
+				assert.Equal(t, test.expectCalls, ext.calls)
+			})
+		}
+	})
+}
+
+func TestNewRuntime_UnknownCacheableExtension(t *testing.T) {
+	assert.PanicsWithValue(t, `cacheable wasm extension namespace "myext" function "cached" not defined`, func() {
+		NewRuntime([]WASMExtensioner{&unknownCacheableExtension{}})
+	})
+}
+
+type unknownCacheableExtension struct{}
+
+func (e *unknownCacheableExtension) WASMExtensions() map[string]map[string]WASMExtension {
+	return nil
+}
+
+func (e *unknownCacheableExtension) CacheableWASMExtensions() map[string][]string {
+	return map[string][]string{"myext": {"cached"}}
+}
@@ -2,36 +2,39 @@ package wasm
 
 import (
 	"fmt"
-
-	"github.com/wasmerio/wasmer-go/wasmer"
 )
 
 type Heap struct {
-	memory    *wasmer.Memory
-	allocator wasmer.NativeFunction
+	vm BackendInstance
+
+	maxMemoryPages  uint32 // 0 for unlimited
+	peakMemoryBytes uint64
 }
 
-func NewHeap(memory *wasmer.Memory, allocator wasmer.NativeFunction) *Heap {
+func NewHeap(vm BackendInstance) *Heap {
 	return &Heap{
-		memory:    memory,
-		allocator: allocator,
+		vm: vm,
 	}
 }
 
 func (h *Heap) Write(bytes []byte) (int32, error) {
 	size := len(bytes)
 
-	allocation, err := h.allocator(size)
+	allocation, err := h.vm.Call("alloc", NewI32(int32(size)))
+	h.recordPeakMemory()
 	if err != nil {
-		return 0, fmt.Errorf("allocating memory for size %d:%w", size, err)
+		return 0, fmt.Errorf("allocating memory for size %d:%w", size, h.memoryLimitError(err))
+	}
+	if len(allocation) != 1 {
+		return 0, fmt.Errorf("allocating memory for size %d: expected 1 result, got %d", size, len(allocation))
 	}
 
-	ptr := allocation.(int32)
+	ptr := allocation[0].I32()
 
 	return h.WriteAtPtr(bytes, ptr)
 }
 func (h *Heap) WriteAtPtr(bytes []byte, ptr int32) (int32, error) {
-	memoryData := h.memory.Data()
+	memoryData := h.vm.Memory()
 	copy(memoryData[ptr:], bytes)
 
 	return ptr, nil
@@ -46,7 +49,7 @@ func (h *Heap) ReadString(offset int32, length int32) (string, error) {
 }
 
 func (h *Heap) ReadBytes(offset int32, length int32) ([]byte, error) {
-	bytes := h.memory.Data()
+	bytes := h.vm.Memory()
 	if offset < 0 {
 		return nil, fmt.Errorf("offset %d env must be positive", offset)
 	}
@@ -67,7 +70,7 @@ func (h *Heap) ReadBytes(offset int32, length int32) ([]byte, error) {
 }
 
 func (h *Heap) PrintMem() {
-	data := h.memory.Data()
+	data := h.vm.Memory()
 	for i, datum := range data {
 		if i > 1024 {
 			if datum == 0 {
@@ -11,13 +11,15 @@ const (
 	InputSource InputType = iota
 	InputStore
 	OutputStore
+	InputParams
 )
 
 type Input struct {
 	Type InputType
 	Name string
 
-	// Transient data between calls
+	// Transient data between calls, the value of the params when
+	// InputType == InputParams
 	StreamData []byte
 
 	// InputType == InputStore || OutputStore
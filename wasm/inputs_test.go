@@ -0,0 +1,26 @@
+package wasm
+
+import (
+	"context"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputParams(t *testing.T) {
+	code := readTestWasm(t, "params")
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		module, err := NewRuntime(nil, WithBackend(backend)).NewModule(context.Background(), &pbsubstreams.Request{}, code, "test_module")
+		require.NoError(t, err)
+
+		instance, err := module.NewInstance(&pbsubstreams.Clock{}, "map_params", []*Input{
+			{Type: InputParams, Name: "params", StreamData: []byte("0xc02aaa39")},
+		})
+		require.NoError(t, err)
+		require.NoError(t, instance.Execute())
+		assert.Equal(t, []byte("0xc02aaa39"), instance.Output())
+	})
+}
@@ -2,23 +2,20 @@ package wasm
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
 	"github.com/streamingfast/substreams/state"
-	"github.com/wasmerio/wasmer-go/wasmer"
 )
 
 type Instance struct {
-	memory       *wasmer.Memory
 	heap         *Heap
-	store        *wasmer.Store
 	inputStores  []state.Reader
 	outputStore  *state.Store
 	updatePolicy pbsubstreams.Module_KindStore_UpdatePolicy
 
-	valueType  string
-	entrypoint *wasmer.Function
+	valueType string
 
 	clock *pbsubstreams.Clock
 
@@ -26,29 +23,28 @@ type Instance struct {
 	returnValue  []byte
 	panicError   *PanicError
 	functionName string
-	vmInstance   *wasmer.Instance
+	vm           *vm
+	module       *Module
 	moduleName   string
 
-	Logs          []string
+	Logs          []*pbsubstreams.LogEntry
 	LogsByteCount uint64
+	maxLogBytes   uint64
 }
 
 func (i *Instance) Heap() *Heap {
 	return i.heap
 }
 
-func (i *Instance) Store() *wasmer.Store {
-	return i.store
-}
-
-func (i *Instance) PrintStats() {
-	fmt.Printf("Memory size: %d\n", i.memory.DataSize())
+// PeakMemoryBytes returns the largest linear memory size observed for
+// this instance, in bytes.
+func (i *Instance) PeakMemoryBytes() uint64 {
+	return i.heap.peakMemoryBytes
 }
 
 func (i *Instance) Execute() (err error) {
-	if _, err = i.entrypoint.Call(i.args...); err != nil {
+	if err = i.call(i.args...); err != nil {
 		if i.panicError != nil {
-			fmt.Println("Panic error:", i.panicError)
 			return i.panicError
 		}
 		return fmt.Errorf("executing entrypoint %q: %w", i.functionName, err)
@@ -58,7 +54,7 @@ func (i *Instance) Execute() (err error) {
 }
 
 func (i *Instance) ExecuteWithArgs(args ...interface{}) (err error) {
-	if _, err = i.entrypoint.Call(args...); err != nil {
+	if err = i.call(args...); err != nil {
 		if i.panicError != nil {
 			return i.panicError
 		}
@@ -67,6 +63,35 @@ func (i *Instance) ExecuteWithArgs(args ...interface{}) (err error) {
 	return nil
 }
 
+func (i *Instance) call(args ...interface{}) error {
+	values, err := toValues(args)
+	if err != nil {
+		return err
+	}
+
+	err = i.vm.instance.Execute(i.functionName, values...)
+	i.heap.recordPeakMemory()
+	if err != nil {
+		i.vm.failed = true
+
+		var limitErr *ExecutionLimitError
+		if errors.As(err, &limitErr) {
+			limitErr.ModuleName = i.moduleName
+		} else {
+			err = i.heap.memoryLimitError(err)
+		}
+
+		var trapErr *TrapError
+		if errors.As(err, &trapErr) {
+			i.module.symbolize(trapErr.Frames)
+			if i.panicError != nil {
+				i.panicError.frames = trapErr.Frames
+			}
+		}
+	}
+	return err
+}
+
 func (i *Instance) WriteOutputToHeap(outputPtr int32, value []byte) error {
 
 	valuePtr, err := i.heap.Write(value)
@@ -96,9 +121,3 @@ func (i *Instance) Output() []byte {
 func (i *Instance) SetOutputStore(store *state.Store) {
 	i.outputStore = store
 }
-
-const maxLogByteCount = 128 * 1024 // 128 KiB
-
-func (i *Instance) ReachedLogsMaxByteCount() bool {
-	return i.LogsByteCount >= maxLogByteCount
-}
@@ -10,6 +10,20 @@ type WASMExtensioner interface {
 	WASMExtensions() map[string]map[string]WASMExtension
 }
 
+// ExtensionFunctions returns the names of the functions of `extensions`,
+// by namespace, as validated by `wasmbin.Validate`.
+func ExtensionFunctions(extensions []WASMExtensioner) map[string][]string {
+	out := map[string][]string{}
+	for _, ext := range extensions {
+		for namespace, functions := range ext.WASMExtensions() {
+			for name := range functions {
+				out[namespace] = append(out[namespace], name)
+			}
+		}
+	}
+	return out
+}
+
 // WASMExtension defines the implementation of a function that will
 // be exposed as wasm imports; therefore, exposed to the host language
 // like Rust.
@@ -20,3 +34,22 @@ type WASMExtensioner interface {
 //
 // Such a function needs to be registered through RegisterRuntime.
 type WASMExtension func(ctx context.Context, request *pbsubstreams.Request, clock *pbsubstreams.Clock, in []byte) (out []byte, err error)
+
+// CacheableWASMExtensioner is implemented by extensions with functions
+// whose output only depends on the block and their input, like calls
+// to a chain node at a given block. The outputs of these functions are
+// memoized in the `ExtensionCallCache` of the module, when it has one.
+type CacheableWASMExtensioner interface {
+	WASMExtensioner
+
+	// CacheableWASMExtensions returns the names of the cacheable
+	// functions, by namespace.
+	CacheableWASMExtensions() map[string][]string
+}
+
+// ExtensionCallCache memoizes the outputs of cacheable `WASMExtension`
+// calls. Keys identify the function, the block and the input of a call.
+type ExtensionCallCache interface {
+	Get(ctx context.Context, clock *pbsubstreams.Clock, key string) (out []byte, found bool, err error)
+	Set(ctx context.Context, clock *pbsubstreams.Clock, key string, out []byte) error
+}
@@ -0,0 +1,223 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/streamingfast/substreams/wasm/wasmbin"
+)
+
+type RuntimeOption func(r *Runtime)
+
+// WithInstructionBudget caps the number of wasm instructions a module
+// can execute for a single block. Each instruction costs one point, so
+// the budget is deterministic: a module either always or never exceeds
+// it for a given block.
+func WithInstructionBudget(instructions uint64) RuntimeOption {
+	return func(r *Runtime) {
+		r.instructionBudget = instructions
+	}
+}
+
+// WithExecutionTimeout aborts a module execution running for longer than
+// `timeout` on a single block. It is a safety net for host functions and
+// machines slower than expected: unlike the instruction budget, it is
+// not deterministic.
+func WithExecutionTimeout(timeout time.Duration) RuntimeOption {
+	return func(r *Runtime) {
+		r.executionTimeout = timeout
+	}
+}
+
+// WithMaxMemoryPages caps the linear memory of each module instance to
+// `pages` wasm pages of 64 KiB.
+func WithMaxMemoryPages(pages uint32) RuntimeOption {
+	return func(r *Runtime) {
+		r.maxMemoryPages = pages
+	}
+}
+
+func (r *Runtime) backendConfig() *BackendConfig {
+	return &BackendConfig{
+		InstructionBudget: r.instructionBudget,
+		ExecutionTimeout:  r.executionTimeout,
+		MaxMemoryPages:    r.maxMemoryPages,
+		Cache:             r.moduleCache,
+	}
+}
+
+// ExecutionLimitError is returned when a module execution is stopped
+// for exceeding its instruction budget or its timeout.
+type ExecutionLimitError struct {
+	ModuleName string
+	Budget     uint64        // set when the instruction budget was exceeded
+	Timeout    time.Duration // set when the execution timed out
+}
+
+func (e *ExecutionLimitError) Error() string {
+	if e.Timeout != 0 {
+		return fmt.Sprintf("module %q execution timed out after %s", e.ModuleName, e.Timeout)
+	}
+	return fmt.Sprintf("module %q exceeded its budget of %d wasm instructions per block", e.ModuleName, e.Budget)
+}
+
+// wasmPageSize is the size of a page of wasm linear memory.
+const wasmPageSize = 64 * 1024
+
+// MemoryLimitError is returned when a module instance fails with its
+// linear memory at the maximum number of pages: the backends refuse to
+// grow it further, which the guest usually reports as a trap.
+type MemoryLimitError struct {
+	MaxPages uint32
+	Err      error
+}
+
+func (e *MemoryLimitError) Error() string {
+	return fmt.Sprintf("memory limit of %d pages (%s) reached: %s",
+		e.MaxPages, humanize.IBytes(uint64(e.MaxPages)*wasmPageSize), e.Err,
+	)
+}
+
+func (e *MemoryLimitError) Unwrap() error {
+	return e.Err
+}
+
+// recordPeakMemory records the memory in use. The guest can grow its
+// memory at will, so it runs after each allocation and after each
+// execution.
+func (h *Heap) recordPeakMemory() {
+	if size := uint64(len(h.vm.Memory())); size > h.peakMemoryBytes {
+		h.peakMemoryBytes = size
+	}
+}
+
+// memoryLimitError returns `err` as a `*MemoryLimitError` when the
+// memory is at its maximum, the likely cause of the failure.
+func (h *Heap) memoryLimitError(err error) error {
+	if h.maxMemoryPages == 0 {
+		return err
+	}
+	if pages := uint32(len(h.vm.Memory()) / wasmPageSize); pages < h.maxMemoryPages {
+		return err
+	}
+	return &MemoryLimitError{MaxPages: h.maxMemoryPages, Err: err}
+}
+
+// codeEdit maps the offsets of a wasm binary resized by `delta` bytes
+// before `offset` back to the original binary.
+type codeEdit struct {
+	offset uint64 // in the edited binary
+	delta  int64
+}
+
+func (e codeEdit) originalOffset(offset uint64) uint64 {
+	if offset < e.offset {
+		return offset
+	}
+	return uint64(int64(offset) - e.delta)
+}
+
+// codeEdits are the successive edits of a wasm binary.
+type codeEdits []codeEdit
+
+func (e codeEdits) originalOffset(offset uint64) uint64 {
+	for i := len(e) - 1; i >= 0; i-- {
+		offset = e[i].originalOffset(offset)
+	}
+	return offset
+}
+
+// limitMemoryPages sets the maximum of the memories defined by `code` to
+// `maxPages`, for backends without a memory limit setting: growing the
+// memory past it then fails in the guest. Memories declaring a larger
+// minimum or maximum are rejected.
+func limitMemoryPages(code []byte, maxPages uint32) ([]byte, codeEdit, error) {
+	var out []byte
+	var edit codeEdit
+
+	sectionStart := len(wasmbin.Magic)
+	err := wasmbin.ReadSections(code, func(id byte, section *wasmbin.Reader, offset int) error {
+		start, end := sectionStart, offset+len(section.Data)
+		sectionStart = end
+		if id != wasmbin.SectionMemory {
+			return nil
+		}
+
+		content, err := limitMemories(section, maxPages)
+		if err != nil {
+			return err
+		}
+
+		limited := []byte{id}
+		limited = appendU32(limited, uint32(len(content)))
+		limited = append(limited, content...)
+
+		out = make([]byte, 0, len(code)+len(limited)-(end-start))
+		out = append(out, code[:start]...)
+		out = append(out, limited...)
+		out = append(out, code[end:]...)
+		edit = codeEdit{
+			offset: uint64(start + len(limited)),
+			delta:  int64(len(limited) - (end - start)),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, codeEdit{}, fmt.Errorf("limiting memory to %d pages: %w", maxPages, err)
+	}
+	if out == nil {
+		return code, codeEdit{}, nil
+	}
+	return out, edit, nil
+}
+
+// limitMemories encodes the memory section `r` with a maximum of
+// `maxPages` on each memory.
+func limitMemories(r *wasmbin.Reader, maxPages uint32) ([]byte, error) {
+	count, err := r.U32()
+	if err != nil {
+		return nil, err
+	}
+
+	out := appendU32(nil, count)
+	for i := uint32(0); i < count; i++ {
+		flags, err := r.Byte()
+		if err != nil {
+			return nil, err
+		}
+		if flags > 0x01 {
+			return nil, fmt.Errorf("memory %d: unsupported limits flags 0x%x", i, flags)
+		}
+
+		min, err := r.U32()
+		if err != nil {
+			return nil, err
+		}
+		if min > maxPages {
+			return nil, fmt.Errorf("memory %d: minimum of %d pages over the limit", i, min)
+		}
+
+		max := maxPages
+		if flags == 0x01 {
+			if max, err = r.U32(); err != nil {
+				return nil, err
+			}
+			if max > maxPages {
+				return nil, fmt.Errorf("memory %d: maximum of %d pages over the limit", i, max)
+			}
+		}
+
+		out = append(out, 0x01)
+		out = appendU32(out, min)
+		out = appendU32(out, max)
+	}
+	return out, nil
+}
+
+func appendU32(out []byte, v uint32) []byte {
+	var buf [binary.MaxVarintLen32]byte
+	n := binary.PutUvarint(buf[:], uint64(v))
+	return append(out, buf[:n]...)
+}
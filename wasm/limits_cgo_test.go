@@ -0,0 +1,61 @@
+//go:build cgo
+
+package wasm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+func TestInstructionBudget(t *testing.T) {
+	instance := newLimitsTestInstance(t, backends[WasmerBackendName], "count", WithInstructionBudget(10_000))
+	require.NoError(t, instance.ExecuteWithArgs(int32(100)))
+
+	// The budget is reset for each execution
+	require.NoError(t, instance.ExecuteWithArgs(int32(100)))
+
+	err := instance.ExecuteWithArgs(int32(100_000))
+	var limitErr *ExecutionLimitError
+	require.True(t, errors.As(err, &limitErr), "got %v", err)
+	assert.Equal(t, uint64(10_000), limitErr.Budget)
+	assert.Contains(t, err.Error(), `module "test_module" exceeded its budget of 10000 wasm instructions per block`)
+}
+
+func TestLimitMemoryPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		wat       string
+		expectMax uint32
+		expectErr string
+	}{
+		{"no maximum", `(module (memory (export "memory") 1))`, 4, ""},
+		{"lower maximum", `(module (memory (export "memory") 1 2))`, 2, ""},
+		{"maximum at limit", `(module (memory (export "memory") 1 4))`, 4, ""},
+		{"maximum over limit", `(module (memory (export "memory") 1 5))`, 0, "limiting memory to 4 pages: section 5: memory 0: maximum of 5 pages over the limit"},
+		{"minimum over limit", `(module (memory (export "memory") 5))`, 0, "limiting memory to 4 pages: section 5: memory 0: minimum of 5 pages over the limit"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, err := wasmer.Wat2Wasm(test.wat)
+			require.NoError(t, err)
+
+			limited, _, err := limitMemoryPages(code, 4)
+			if test.expectErr != "" {
+				assert.EqualError(t, err, test.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			module, err := wasmer.NewModule(wasmer.NewStore(wasmer.NewEngine()), limited)
+			require.NoError(t, err)
+			exports := module.Exports()
+			require.Len(t, exports, 1)
+			assert.Equal(t, test.expectMax, exports[0].Type().IntoMemoryType().Limits().Maximum())
+		})
+	}
+}
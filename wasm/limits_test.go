@@ -0,0 +1,85 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forEachBackend runs the test against every backend of the build.
+func forEachBackend(t *testing.T, test func(t *testing.T, backend Backend)) {
+	for _, name := range Backends() {
+		backend, err := NewBackend(name)
+		require.NoError(t, err)
+		t.Run(name, func(t *testing.T) {
+			test(t, backend)
+		})
+	}
+}
+
+func newLimitsTestModule(t *testing.T, backend Backend, opts ...RuntimeOption) (*Module, error) {
+	t.Helper()
+
+	opts = append(opts, WithBackend(backend))
+	return NewRuntime(nil, opts...).NewModule(context.Background(), &pbsubstreams.Request{}, readTestWasm(t, "limits"), "test_module")
+}
+
+func newLimitsTestInstance(t *testing.T, backend Backend, functionName string, opts ...RuntimeOption) *Instance {
+	t.Helper()
+
+	module, err := newLimitsTestModule(t, backend, opts...)
+	require.NoError(t, err)
+
+	instance, err := module.NewInstance(&pbsubstreams.Clock{}, functionName, nil)
+	require.NoError(t, err)
+	return instance
+}
+
+func TestInstructionBudget_Unsupported(t *testing.T) {
+	_, err := newLimitsTestModule(t, backends[WazeroBackendName], WithInstructionBudget(10_000))
+	assert.EqualError(t, err, "the wazero wasm backend does not support instruction budgets")
+}
+
+func TestExecutionTimeout(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		instance := newLimitsTestInstance(t, backend, "loop_forever", WithExecutionTimeout(50*time.Millisecond))
+
+		start := time.Now()
+		err := instance.Execute()
+		var limitErr *ExecutionLimitError
+		require.True(t, errors.As(err, &limitErr), "got %v", err)
+		assert.Equal(t, 50*time.Millisecond, limitErr.Timeout)
+		assert.Equal(t, "test_module", limitErr.ModuleName)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestMaxMemoryPages(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		instance := newLimitsTestInstance(t, backend, "grow", WithMaxMemoryPages(2))
+		require.NoError(t, instance.ExecuteWithArgs(int32(1)))
+		assert.Equal(t, uint64(2*wasmPageSize), instance.PeakMemoryBytes())
+
+		// The memory is never grown past the limit
+		err := instance.ExecuteWithArgs(int32(3))
+		var limitErr *MemoryLimitError
+		require.True(t, errors.As(err, &limitErr), "got %v", err)
+		assert.Equal(t, uint32(2), limitErr.MaxPages)
+		assert.Contains(t, err.Error(), "memory limit of 2 pages (128 KiB) reached: ")
+		assert.Equal(t, uint64(2*wasmPageSize), instance.PeakMemoryBytes())
+	})
+}
+
+func TestMaxMemoryPages_InitialMemoryOverLimit(t *testing.T) {
+	code := readTestWasm(t, "memory_3_pages")
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		_, err := NewRuntime(nil, WithBackend(backend), WithMaxMemoryPages(2)).NewModule(context.Background(), &pbsubstreams.Request{}, code, "test_module")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,71 @@
+package wasm
+
+import (
+	"fmt"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxLogBytes is the default limit of logs collected per module
+// per block.
+const DefaultMaxLogBytes = 128 * 1024 // 128 KiB
+
+// WithMaxLogBytes limits the logs collected per module per block, counting
+// the bytes of messages, field keys and values. The logs past the limit
+// are dropped, and the output flagged as truncated.
+func WithMaxLogBytes(bytes uint64) RuntimeOption {
+	return func(r *Runtime) {
+		r.maxLogBytes = bytes
+	}
+}
+
+// logEnabled tells if logs of `level` are collected for the request.
+func (m *Module) logEnabled(level pbsubstreams.LogLevel) bool {
+	return level >= m.minLogLevel
+}
+
+// appendLog collects `entry` until the byte limit of the instance is
+// reached.
+func (i *Instance) appendLog(entry *pbsubstreams.LogEntry) {
+	if tracer.Enabled() {
+		fields := []zap.Field{
+			zap.Stringer("level", entry.Level),
+			zap.String("function_name", i.functionName),
+			zap.String("wasm_file", i.moduleName),
+		}
+		for _, field := range entry.Fields {
+			fields = append(fields, zap.String(field.Key, field.Value))
+		}
+		zlog.Debug(entry.Message, fields...)
+	}
+
+	// len(<string>) in Go count number of bytes and not characters, so we are good here
+	size := len(entry.Message)
+	for _, field := range entry.Fields {
+		size += len(field.Key) + len(field.Value)
+	}
+	i.LogsByteCount += uint64(size)
+
+	if !i.ReachedLogsMaxByteCount() {
+		i.Logs = append(i.Logs, entry)
+	}
+}
+
+func (i *Instance) ReachedLogsMaxByteCount() bool {
+	return i.LogsByteCount >= i.maxLogBytes
+}
+
+func validateLogEntry(entry *pbsubstreams.LogEntry) error {
+	switch entry.Level {
+	case pbsubstreams.LogLevel_LOG_LEVEL_UNSPECIFIED:
+		entry.Level = pbsubstreams.LogLevel_LOG_LEVEL_INFO
+	case pbsubstreams.LogLevel_LOG_LEVEL_DEBUG,
+		pbsubstreams.LogLevel_LOG_LEVEL_INFO,
+		pbsubstreams.LogLevel_LOG_LEVEL_WARN,
+		pbsubstreams.LogLevel_LOG_LEVEL_ERROR:
+	default:
+		return fmt.Errorf("invalid log level %d", entry.Level)
+	}
+	return nil
+}
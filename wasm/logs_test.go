@@ -0,0 +1,118 @@
+package wasm
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// newLogsTestInstance returns an instance of a module whose `log_entries`
+// function logs `entries` in order, through the `logger::log` import.
+func newLogsTestInstance(t *testing.T, backend Backend, request *pbsubstreams.Request, entries []*pbsubstreams.LogEntry, opts ...RuntimeOption) *Instance {
+	t.Helper()
+
+	// Read by the module from its params input, each entry prefixed by
+	// its size
+	var params []byte
+	for _, entry := range entries {
+		encoded, err := proto.Marshal(entry)
+		require.NoError(t, err)
+		size := make([]byte, 4)
+		binary.LittleEndian.PutUint32(size, uint32(len(encoded)))
+		params = append(params, size...)
+		params = append(params, encoded...)
+	}
+
+	opts = append(opts, WithBackend(backend))
+	module, err := NewRuntime(nil, opts...).NewModule(context.Background(), request, readTestWasm(t, "logs"), "test_module")
+	require.NoError(t, err)
+
+	instance, err := module.NewInstance(&pbsubstreams.Clock{}, "log_entries", []*Input{
+		{Type: InputParams, Name: "params", StreamData: params},
+	})
+	require.NoError(t, err)
+	return instance
+}
+
+func TestLog(t *testing.T) {
+	entries := []*pbsubstreams.LogEntry{
+		{Level: pbsubstreams.LogLevel_LOG_LEVEL_DEBUG, Message: "decoding"},
+		{Message: "decoded", Fields: []*pbsubstreams.LogField{{Key: "count", Value: "2"}}},
+		{Level: pbsubstreams.LogLevel_LOG_LEVEL_ERROR, Message: "pair not found", Fields: []*pbsubstreams.LogField{{Key: "pair", Value: "0xabc"}}},
+	}
+
+	tests := []struct {
+		name        string
+		minLogLevel pbsubstreams.LogLevel
+		expectLogs  []string
+	}{
+		{
+			name:       "all levels",
+			expectLogs: []string{"DEBUG decoding", "INFO decoded count=2", "ERROR pair not found pair=0xabc"},
+		},
+		{
+			name:        "info and above",
+			minLogLevel: pbsubstreams.LogLevel_LOG_LEVEL_INFO,
+			expectLogs:  []string{"INFO decoded count=2", "ERROR pair not found pair=0xabc"},
+		},
+		{
+			name:        "errors only",
+			minLogLevel: pbsubstreams.LogLevel_LOG_LEVEL_ERROR,
+			expectLogs:  []string{"ERROR pair not found pair=0xabc"},
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				instance := newLogsTestInstance(t, backend, &pbsubstreams.Request{MinLogLevel: test.minLogLevel}, entries)
+				require.NoError(t, instance.Execute())
+
+				assert.Equal(t, test.expectLogs, pbsubstreams.FormatLogs(instance.Logs))
+			})
+		}
+	})
+}
+
+func TestLog_MaxBytes(t *testing.T) {
+	entries := []*pbsubstreams.LogEntry{
+		{Message: "first"},
+		{Message: "second"},
+		{Message: "third"},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		instance := newLogsTestInstance(t, backend, &pbsubstreams.Request{}, entries, WithMaxLogBytes(10))
+		require.NoError(t, instance.Execute())
+
+		assert.Equal(t, []string{"INFO first"}, pbsubstreams.FormatLogs(instance.Logs))
+		assert.True(t, instance.ReachedLogsMaxByteCount())
+	})
+}
+
+func TestLog_EntryTooBig(t *testing.T) {
+	entries := []*pbsubstreams.LogEntry{
+		{Message: "pair not found", Fields: []*pbsubstreams.LogField{{Key: "pair", Value: "0xabc"}}},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		instance := newLogsTestInstance(t, backend, &pbsubstreams.Request{}, entries, WithMaxLogBytes(10))
+		err := instance.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "log entry is too big, max size is 10 B")
+	})
+}
+
+func TestLog_InvalidLevel(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		instance := newLogsTestInstance(t, backend, &pbsubstreams.Request{}, []*pbsubstreams.LogEntry{{Level: 42, Message: "hello"}})
+		err := instance.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid log level 42")
+	})
+}
@@ -2,65 +2,90 @@ package wasm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strconv"
+	"sync"
 
 	"github.com/dustin/go-humanize"
 	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
-	"github.com/wasmerio/wasmer-go/wasmer"
-	"go.uber.org/zap"
+	"github.com/streamingfast/substreams/wasm/wasmbin"
 	"google.golang.org/protobuf/proto"
 )
 
 type Module struct {
 	runtime *Runtime
 
-	engine *wasmer.Engine
-	module *wasmer.Module
+	module BackendModule
 	name   string
 
 	wasmCode        []byte
 	CurrentInstance *Instance
-	imports         *wasmer.ImportObject
-}
+	imports         HostImports
 
-func (r *Runtime) NewModule(ctx context.Context, request *pbsubstreams.Request, wasmCode []byte, name string) (*Module, error) {
-	engine := wasmer.NewUniversalEngine()
-	store := wasmer.NewStore(engine)
+	reuseInstances bool
+	pooledVMs      map[string]*vm // by entrypoint, when reusing instances
+	initialMemory  []byte         // memory of a fresh instance, when reusing instances
+	lastVM         *vm            // instance of the previous call, when not reusing instances
 
-	module, err := wasmer.NewModule(store, wasmCode)
-	if err != nil {
-		return nil, fmt.Errorf("loading wasm module: %w", err)
-	}
+	minLogLevel pbsubstreams.LogLevel
+
+	symbolsOnce sync.Once
+	symbols     *symbols // read from `wasmCode` on the first trap
+
+	extensionCallCache ExtensionCallCache // nil to always call extensions
+}
 
+func (r *Runtime) NewModule(ctx context.Context, request *pbsubstreams.Request, wasmCode []byte, name string) (*Module, error) {
 	m := &Module{
 		runtime:  r,
-		engine:   engine,
-		module:   module,
 		name:     name,
 		wasmCode: wasmCode,
+
+		reuseInstances: r.reuseInstances,
+		minLogLevel:    request.GetMinLogLevel(),
 	}
-	m.imports = m.newImports(store)
+	m.imports = m.newImports()
 
 	for namespace, imports := range r.extensions {
-		externs := map[string]wasmer.IntoExtern{}
+		functions := map[string]*HostFunction{}
 		for importName, f := range imports {
-			externs[importName] = m.newExtensionFunction(ctx, request, store, namespace, importName, f)
+			functions[importName] = m.newExtensionFunction(ctx, request, namespace, importName, f)
 		}
-		//
-		m.imports.Register(namespace, externs)
+		m.imports[namespace] = functions
+	}
+
+	module, err := r.backend.NewModule(ctx, wasmCode, m.imports, r.backendConfig())
+	if err != nil {
+		return nil, err
 	}
+	m.module = module
 
 	return m, nil
 }
 
-func (m *Module) newExtensionFunction(ctx context.Context, request *pbsubstreams.Request, store *wasmer.Store, namespace, name string, f WASMExtension) *wasmer.Function {
-	return wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I32, wasmer.I32, wasmer.I32), // 0(READ): input bytes offset,  1(READ): input length, 2(WRITE): output bytes offset
-			Returns()),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+// symbolize resolves the function names and source positions of `frames`
+// from the binary.
+func (m *Module) symbolize(frames []StackFrame) {
+	m.symbolsOnce.Do(func() {
+		m.symbols = newSymbols(m.wasmCode)
+	})
+	m.symbols.symbolize(frames)
+}
+
+// SetExtensionCallCache memoizes the calls of the cacheable extension
+// functions of the module in `cache`.
+func (m *Module) SetExtensionCallCache(cache ExtensionCallCache) {
+	m.extensionCallCache = cache
+}
+
+func (m *Module) newExtensionFunction(ctx context.Context, request *pbsubstreams.Request, namespace, name string, f WASMExtension) *HostFunction {
+	return &HostFunction{
+		Params:  wasmbin.ExtensionSignature.Params,
+		Results: wasmbin.ExtensionSignature.Results,
+		Func: func(args []Value) ([]Value, error) {
 
 			heap := m.CurrentInstance.Heap()
 
@@ -69,15 +94,9 @@ func (m *Module) newExtensionFunction(ctx context.Context, request *pbsubstreams
 				return nil, fmt.Errorf("read message argument: %w", err)
 			}
 
-			out, err := f(ctx, request, m.CurrentInstance.clock, message)
+			out, err := m.callExtension(ctx, request, namespace, name, f, message)
 			if err != nil {
-				return nil, fmt.Errorf(`failed running wasm extension "%s::%s": %w`, namespace, name, err)
-			}
-
-			// It's unclear if WASMExtension implementor will correctly handle the context canceled case, as a safety
-			// measure, we check if the context was canceled without being handled correctly and stop here.
-			if ctx.Err() == context.Canceled {
-				return nil, fmt.Errorf("running wasm extension has been stop upstream in the call stack: %w", ctx.Err())
+				return nil, err
 			}
 
 			err = m.CurrentInstance.WriteOutputToHeap(args[2].I32(), out)
@@ -86,46 +105,86 @@ func (m *Module) newExtensionFunction(ctx context.Context, request *pbsubstreams
 			}
 			return nil, nil
 		},
-	)
+	}
 }
 
-func (m *Module) NewInstance(clock *pbsubstreams.Clock, functionName string, inputs []*Input) (*Instance, error) {
-	// WARN: An instance needs to be created on the same thread that it is consumed.
-	store := wasmer.NewStore(m.engine)
-
-	m.CurrentInstance = &Instance{
-		moduleName:   m.name,
-		store:        store,
-		functionName: functionName,
-		clock:        clock,
+// callExtension calls the extension function `f`, going through the
+// extension call cache for cacheable functions.
+func (m *Module) callExtension(ctx context.Context, request *pbsubstreams.Request, namespace, name string, f WASMExtension, in []byte) ([]byte, error) {
+	clock := m.CurrentInstance.clock
+
+	var key string
+	cache := m.extensionCallCache
+	if cache != nil && m.runtime.cacheableExtensions[namespace][name] {
+		key = extensionCallKey(namespace, name, clock, in)
+		out, found, err := cache.Get(ctx, clock, key)
+		if err != nil {
+			return nil, fmt.Errorf(`reading cached call of wasm extension "%s::%s": %w`, namespace, name, err)
+		}
+		if found {
+			return out, nil
+		}
 	}
 
-	vmInstance, err := wasmer.NewInstance(m.module, m.imports)
+	out, err := f(ctx, request, clock, in)
 	if err != nil {
-		return nil, fmt.Errorf("creating instance: %w", err)
+		return nil, fmt.Errorf(`failed running wasm extension "%s::%s": %w`, namespace, name, err)
 	}
-	m.CurrentInstance.vmInstance = vmInstance
 
-	memory, err := vmInstance.Exports.GetMemory("memory")
-	if err != nil {
-		return nil, fmt.Errorf("getting module memory: %w", err)
+	// It's unclear if WASMExtension implementor will correctly handle the context canceled case, as a safety
+	// measure, we check if the context was canceled without being handled correctly and stop here.
+	if ctx.Err() == context.Canceled {
+		return nil, fmt.Errorf("running wasm extension has been stop upstream in the call stack: %w", ctx.Err())
 	}
 
-	alloc, err := vmInstance.Exports.GetFunction("alloc")
-	if err != nil {
-		return nil, fmt.Errorf("getting alloc function: %w", err)
+	if key != "" {
+		if err := cache.Set(ctx, clock, key, out); err != nil {
+			return nil, fmt.Errorf(`caching call of wasm extension "%s::%s": %w`, namespace, name, err)
+		}
 	}
-	m.CurrentInstance.memory = memory
-	m.CurrentInstance.heap = NewHeap(memory, alloc)
-	m.CurrentInstance.entrypoint, err = vmInstance.Exports.GetRawFunction(functionName)
+	return out, nil
+}
+
+// extensionCallKey identifies a call of an extension function at a block
+// with a given input.
+func extensionCallKey(namespace, name string, clock *pbsubstreams.Clock, in []byte) string {
+	h := sha256.New()
+	for _, part := range []string{namespace, name, strconv.FormatUint(clock.GetNumber(), 10), clock.GetId()} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(in)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (m *Module) NewInstance(clock *pbsubstreams.Clock, functionName string, inputs []*Input) (instance *Instance, err error) {
+	// WARN: An instance needs to be created on the same thread that it is consumed.
+	vm, err := m.acquireVM(functionName)
 	if err != nil {
-		return nil, fmt.Errorf("getting wasm module function %q: %w", functionName, err)
+		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			vm.failed = true
+		}
+	}()
+
+	m.CurrentInstance = &Instance{
+		module:       m,
+		moduleName:   m.name,
+		functionName: functionName,
+		clock:        clock,
+		vm:           vm,
+		heap:         NewHeap(vm.instance),
+		maxLogBytes:  m.runtime.maxLogBytes,
+	}
+	m.CurrentInstance.heap.maxMemoryPages = m.runtime.maxMemoryPages
+	m.CurrentInstance.heap.recordPeakMemory()
 
 	var args []interface{}
 	for _, input := range inputs {
 		switch input.Type {
-		case InputSource:
+		case InputSource, InputParams:
 			ptr, err := m.CurrentInstance.heap.Write(input.StreamData)
 			if err != nil {
 				return nil, fmt.Errorf("writing %q to heap: %w", input.Name, err)
@@ -160,20 +219,17 @@ func (m *Module) NewInstance(clock *pbsubstreams.Clock, functionName string, inp
 	return m.CurrentInstance, nil
 }
 
-func (m *Module) newImports(store *wasmer.Store) *wasmer.ImportObject {
-	imports := wasmer.NewImportObject()
+// newImports returns the host functions provided to every module, with
+// the signatures of `wasmbin.HostFunctions`.
+func (m *Module) newImports() HostImports {
+	imports := HostImports{}
 
-	m.registerLoggerImports(imports, store)
-	m.registerStateImports(imports, store)
+	m.registerLoggerImports(imports)
+	m.registerStateImports(imports)
 
-	imports.Register("env", map[string]wasmer.IntoExtern{
-		"register_panic": wasmer.NewFunction(
-			store,
-			wasmer.NewFunctionType(
-				Params(wasmer.I32, wasmer.I32, wasmer.I32, wasmer.I32, wasmer.I32, wasmer.I32),
-				Returns(),
-			),
-			func(args []wasmer.Value) ([]wasmer.Value, error) {
+	imports["env"] = map[string]*HostFunction{
+		"register_panic": &HostFunction{
+			Func: func(args []Value) ([]Value, error) {
 				message, err := m.CurrentInstance.heap.ReadString(args[0].I32(), args[1].I32())
 				if err != nil {
 					return nil, fmt.Errorf("read message argument: %w", err)
@@ -191,18 +247,13 @@ func (m *Module) newImports(store *wasmer.Store) *wasmer.ImportObject {
 				lineNumber := int(args[4].I32())
 				columnNumber := int(args[5].I32())
 
-				m.CurrentInstance.panicError = &PanicError{message, filename, lineNumber, columnNumber}
+				m.CurrentInstance.panicError = &PanicError{message: message, filename: filename, lineNumber: lineNumber, columnNumber: columnNumber}
 
 				return nil, nil
 			},
-		),
-		"output": wasmer.NewFunction(
-			store,
-			wasmer.NewFunctionType(
-				Params(wasmer.I32, wasmer.I32),
-				Returns(),
-			),
-			func(args []wasmer.Value) ([]wasmer.Value, error) {
+		},
+		"output": &HostFunction{
+			Func: func(args []Value) ([]Value, error) {
 				message, err := m.CurrentInstance.heap.ReadBytes(args[0].I32(), args[1].I32())
 				if err != nil {
 					return nil, fmt.Errorf("reading bytes: %w", err)
@@ -212,28 +263,33 @@ func (m *Module) newImports(store *wasmer.Store) *wasmer.ImportObject {
 
 				return nil, nil
 			},
-		),
-	})
+		},
+	}
+
+	for namespace, functions := range imports {
+		for name, f := range functions {
+			signature, found := wasmbin.HostFunctions[namespace][name]
+			if !found {
+				panic(fmt.Sprintf("host function \"%s::%s\" has no signature", namespace, name))
+			}
+			f.Params, f.Results = signature.Params, signature.Results
+		}
+	}
 	return imports
 }
 
-func (m *Module) registerLoggerImports(imports *wasmer.ImportObject, store *wasmer.Store) {
-	imports.Register("logger", map[string]wasmer.IntoExtern{
-		"println": wasmer.NewFunction(
-			store,
-			wasmer.NewFunctionType(
-				Params(wasmer.I32, wasmer.I32),
-				Returns(),
-			),
-			func(args []wasmer.Value) ([]wasmer.Value, error) {
-				if m.CurrentInstance.ReachedLogsMaxByteCount() {
+func (m *Module) registerLoggerImports(imports HostImports) {
+	imports["logger"] = map[string]*HostFunction{
+		"println": &HostFunction{
+			Func: func(args []Value) ([]Value, error) {
+				if !m.logEnabled(pbsubstreams.LogLevel_LOG_LEVEL_INFO) || m.CurrentInstance.ReachedLogsMaxByteCount() {
 					// Early exit, we don't even need to collect the message as we would not store it anyway
 					return nil, nil
 				}
 
 				length := args[1].I32()
-				if length > maxLogByteCount {
-					return nil, fmt.Errorf("message to log is too big, max size is %s", humanize.IBytes(uint64(length)))
+				if uint64(length) > m.CurrentInstance.maxLogBytes {
+					return nil, fmt.Errorf("message to log is too big, max size is %s", humanize.IBytes(m.CurrentInstance.maxLogBytes))
 				}
 
 				message, err := m.CurrentInstance.heap.ReadString(args[0].I32(), length)
@@ -241,31 +297,50 @@ func (m *Module) registerLoggerImports(imports *wasmer.ImportObject, store *wasm
 					return nil, fmt.Errorf("reading string: %w", err)
 				}
 
-				if tracer.Enabled() {
-					zlog.Debug(message, zap.String("function_name", m.CurrentInstance.functionName), zap.String("wasm_file", m.CurrentInstance.moduleName))
+				m.CurrentInstance.appendLog(&pbsubstreams.LogEntry{
+					Level:   pbsubstreams.LogLevel_LOG_LEVEL_INFO,
+					Message: message,
+				})
+				return nil, nil
+			},
+		},
+		"log": &HostFunction{
+			Func: func(args []Value) ([]Value, error) {
+				if m.CurrentInstance.ReachedLogsMaxByteCount() {
+					return nil, nil
 				}
 
-				// len(<string>) in Go count number of bytes and not characters, so we are good here
-				m.CurrentInstance.LogsByteCount += uint64(len(message))
+				length := args[1].I32()
+				if uint64(length) > m.CurrentInstance.maxLogBytes {
+					return nil, fmt.Errorf("log entry is too big, max size is %s", humanize.IBytes(m.CurrentInstance.maxLogBytes))
+				}
 
-				if !m.CurrentInstance.ReachedLogsMaxByteCount() {
-					m.CurrentInstance.Logs = append(m.CurrentInstance.Logs, message)
+				data, err := m.CurrentInstance.heap.ReadBytes(args[0].I32(), length)
+				if err != nil {
+					return nil, fmt.Errorf("reading bytes: %w", err)
 				}
 
+				entry := &pbsubstreams.LogEntry{}
+				if err := proto.Unmarshal(data, entry); err != nil {
+					return nil, fmt.Errorf("decoding log entry: %w", err)
+				}
+				if err := validateLogEntry(entry); err != nil {
+					return nil, err
+				}
+
+				if m.logEnabled(entry.Level) {
+					m.CurrentInstance.appendLog(entry)
+				}
 				return nil, nil
 			},
-		),
-	})
+		},
+	}
 }
-func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasmer.Store) {
-	functions := map[string]wasmer.IntoExtern{}
-	functions["set"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64, wasmer.I32, wasmer.I32, wasmer.I32, wasmer.I32),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+
+func (m *Module) registerStateImports(imports HostImports) {
+	functions := map[string]*HostFunction{}
+	functions["set"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_SET {
 				return nil, fmt.Errorf("invalid store operation: 'set' only valid for stores with updatePolicy == 'replace'")
 			}
@@ -283,15 +358,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["set_if_not_exists"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64, wasmer.I32, wasmer.I32, wasmer.I32, wasmer.I32),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["set_if_not_exists"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_SET_IF_NOT_EXISTS {
 				return nil, fmt.Errorf("invalid store operation: 'set_if_not_exists' only valid for stores with updatePolicy == 'ignore'")
 			}
@@ -309,18 +379,9 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-	functions["delete_prefix"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(
-				wasmer.I64, /* ordinal */
-				wasmer.I32, /* prefix offset */
-				wasmer.I32, /* prefix length */
-			),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+	functions["delete_prefix"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			prefix, err := m.CurrentInstance.heap.ReadString(args[1].I32(), args[2].I32())
 			if err != nil {
 				return nil, fmt.Errorf("reading prefix: %w", err)
@@ -329,14 +390,9 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 			m.CurrentInstance.outputStore.DeletePrefix(uint64(ord), prefix)
 			return nil, nil
 		},
-	)
-	functions["add_bigfloat"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I32, wasmer.I32 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+	functions["add_bigfloat"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD && m.CurrentInstance.valueType != "bigfloat" {
 				return nil, fmt.Errorf("invalid store operation: 'add_bigfloat' only valid for stores with updatePolicy == 'add' and valueType == 'bigfloat'")
 			}
@@ -359,15 +415,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["add_bigint"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I32, wasmer.I32 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["add_bigint"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD && m.CurrentInstance.valueType != "bigint" {
 				return nil, fmt.Errorf("invalid store operation: 'add_bigint' only valid for stores with updatePolicy == 'add' and valueType == 'bigint'")
 			}
@@ -386,15 +437,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["add_int64"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I64 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["add_int64"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD && m.CurrentInstance.valueType != "int64" {
 				return nil, fmt.Errorf("invalid store operation: 'add_bigint' only valid for stores with updatePolicy == 'add' and valueType == 'int64'")
 			}
@@ -409,15 +455,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["add_float64"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.F64 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["add_float64"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_ADD && m.CurrentInstance.valueType != "float64" {
 				return nil, fmt.Errorf("invalid store operation: 'add_float64' only valid for stores with updatePolicy == 'add' and valueType == 'float64'")
 			}
@@ -435,15 +476,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["set_min_int64"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I64 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["set_min_int64"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MIN && m.CurrentInstance.valueType != "int64" {
 				return nil, fmt.Errorf("invalid store operation: 'set_min_int64' only valid for stores with updatePolicy == 'min' and valueType == 'int64'")
 			}
@@ -458,15 +494,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["set_min_bigint"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I32, wasmer.I32 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["set_min_bigint"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MIN && m.CurrentInstance.valueType != "bigfloat" {
 				return nil, fmt.Errorf("invalid store operation: 'set_min_bigint' only valid for stores with updatePolicy == 'min' and valueType == 'bigint'")
 			}
@@ -485,14 +516,9 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-	functions["set_min_float64"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.F64 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+	functions["set_min_float64"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MIN && m.CurrentInstance.valueType != "float" {
 				return nil, fmt.Errorf("invalid store operation: 'set_min_float64' only valid for stores with updatePolicy == 'min' and valueType == 'int64'")
 			}
@@ -506,15 +532,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["set_min_bigfloat"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I32, wasmer.I32 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["set_min_bigfloat"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MIN && m.CurrentInstance.valueType != "bigint" {
 				return nil, fmt.Errorf("invalid store operation: 'set_min_bigfloat' only valid for stores with updatePolicy == 'min' and valueType == 'bigint'")
 			}
@@ -533,15 +554,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["set_max_int64"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I64 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["set_max_int64"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MAX && m.CurrentInstance.valueType != "int64" {
 				return nil, fmt.Errorf("invalid store operation: 'set_max_int64' only valid for stores with updatePolicy == 'max' and valueType == 'int64'")
 			}
@@ -556,15 +572,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["set_max_bigint"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I32, wasmer.I32 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["set_max_bigint"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MAX && m.CurrentInstance.valueType != "bigint" {
 				return nil, fmt.Errorf("invalid store operation: 'set_max_bigint' only valid for stores with updatePolicy == 'max' and valueType == 'bigint'")
 			}
@@ -583,14 +594,9 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-	functions["set_max_float64"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.F64 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+	functions["set_max_float64"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MAX && m.CurrentInstance.valueType != "float" {
 				return nil, fmt.Errorf("invalid store operation: 'set_max_float64' only valid for stores with updatePolicy == 'max' and valueType == 'float64'")
 			}
@@ -604,15 +610,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["set_max_bigfloat"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I64 /* ordinal */, wasmer.I32, wasmer.I32 /* key */, wasmer.I32, wasmer.I32 /* value */),
-			Returns(),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["set_max_bigfloat"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			if m.CurrentInstance.outputStore == nil && m.CurrentInstance.updatePolicy != pbsubstreams.Module_KindStore_UPDATE_POLICY_MAX && m.CurrentInstance.valueType != "bigint" {
 				return nil, fmt.Errorf("invalid store operation: 'set_max_bigfloat' only valid for stores with updatePolicy == 'max' and valueType == 'bigfloat'")
 			}
@@ -631,19 +632,10 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 
 			return nil, nil
 		},
-	)
-
-	functions["get_at"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I32, /* store index */
-				wasmer.I64, /* ordinal */
-				wasmer.I32, /* key offset */
-				wasmer.I32, /* key length */
-				wasmer.I32 /* return pointer */),
-			Returns(wasmer.I32),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+
+	functions["get_at"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			storeIndex := int(args[0].I32())
 			if storeIndex+1 > len(m.CurrentInstance.inputStores) {
 				return nil, fmt.Errorf("'get_at' failed: invalid store index %d, %d stores declared", storeIndex, len(m.CurrentInstance.inputStores))
@@ -656,27 +648,19 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 			}
 			value, found := readStore.GetAt(uint64(ord), key)
 			if !found {
-				zero := wasmer.NewI32(0)
-				return []wasmer.Value{zero}, nil
+				zero := NewI32(0)
+				return []Value{zero}, nil
 			}
 			outputPtr := args[4].I32()
 			err = m.CurrentInstance.WriteOutputToHeap(outputPtr, value)
 			if err != nil {
 				return nil, fmt.Errorf("writing value to output ptr %d: %w", outputPtr, err)
 			}
-			return []wasmer.Value{wasmer.NewI32(1)}, nil
+			return []Value{NewI32(1)}, nil
 		},
-	)
-	functions["get_first"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I32,
-				wasmer.I32,
-				wasmer.I32,
-				wasmer.I32),
-			Returns(wasmer.I32),
-		),
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+	functions["get_first"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			storeIndex := int(args[0].I32())
 			if storeIndex+1 > len(m.CurrentInstance.inputStores) {
 				return nil, fmt.Errorf("'get_first' failed: invalid store index %d, %d stores declared", storeIndex, len(m.CurrentInstance.inputStores))
@@ -688,29 +672,20 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 			}
 			value, found := readStore.GetFirst(key)
 			if !found {
-				zero := wasmer.NewI32(0)
-				return []wasmer.Value{zero}, nil
+				zero := NewI32(0)
+				return []Value{zero}, nil
 			}
 			outputPtr := args[3].I32()
 			err = m.CurrentInstance.WriteOutputToHeap(outputPtr, value)
 			if err != nil {
 				return nil, fmt.Errorf("writing value to output ptr %d: %w", outputPtr, err)
 			}
-			return []wasmer.Value{wasmer.NewI32(1)}, nil
+			return []Value{NewI32(1)}, nil
 
 		},
-	)
-	functions["get_last"] = wasmer.NewFunction(
-		store,
-		wasmer.NewFunctionType(
-			Params(wasmer.I32,
-				wasmer.I32,
-				wasmer.I32,
-				wasmer.I32),
-			Returns(wasmer.I32),
-		),
-
-		func(args []wasmer.Value) ([]wasmer.Value, error) {
+	}
+	functions["get_last"] = &HostFunction{
+		Func: func(args []Value) ([]Value, error) {
 			storeIndex := int(args[0].I32())
 			if storeIndex+1 > len(m.CurrentInstance.inputStores) {
 				return nil, fmt.Errorf("'get_last' failed: invalid store index %d, %d stores declared", storeIndex, len(m.CurrentInstance.inputStores))
@@ -724,17 +699,17 @@ func (m *Module) registerStateImports(imports *wasmer.ImportObject, store *wasme
 			}
 			value, found := readStore.GetLast(key)
 			if !found {
-				zero := wasmer.NewI32(0)
-				return []wasmer.Value{zero}, nil
+				zero := NewI32(0)
+				return []Value{zero}, nil
 			}
 			outputPtr := args[3].I32()
 			err = m.CurrentInstance.WriteOutputToHeap(outputPtr, value)
 			if err != nil {
 				return nil, fmt.Errorf("writing value to output ptr %d: %w", outputPtr, err)
 			}
-			return []wasmer.Value{wasmer.NewI32(1)}, nil
+			return []Value{NewI32(1)}, nil
 		},
-	)
+	}
 
-	imports.Register("state", functions)
+	imports["state"] = functions
 }
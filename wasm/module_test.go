@@ -0,0 +1,25 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/streamingfast/substreams/wasm/wasmbin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewImports_Signatures(t *testing.T) {
+	imports := (&Module{}).newImports()
+
+	// Every function validated by `wasmbin.Validate` is provided
+	for namespace, functions := range wasmbin.HostFunctions {
+		require.Len(t, imports[namespace], len(functions), namespace)
+		for name, signature := range functions {
+			f := imports[namespace][name]
+			require.NotNil(t, f, "%s::%s", namespace, name)
+			assert.Equal(t, signature.Params, f.Params, "%s::%s", namespace, name)
+			assert.Equal(t, signature.Results, f.Results, "%s::%s", namespace, name)
+		}
+	}
+	assert.Len(t, imports, len(wasmbin.HostFunctions))
+}
@@ -0,0 +1,218 @@
+package wasm
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/streamingfast/substreams/wasm/wasmbin"
+)
+
+// WithInstanceReuse reuses the instances of every module across blocks,
+// as if they all had opted in with `reuseInstance`. Only the backends
+// able to reset instances reuse them.
+func WithInstanceReuse() RuntimeOption {
+	return func(r *Runtime) {
+		r.reuseInstances = true
+	}
+}
+
+// EnableInstanceReuse reuses the wasm instance of the module from one
+// call to the next, restoring its memory and globals in between. Only
+// valid for modules keeping no state outside of them between calls.
+func (m *Module) EnableInstanceReuse() {
+	m.reuseInstances = true
+}
+
+// vm is an instantiated wasm module.
+type vm struct {
+	instance BackendInstance
+
+	// failed is set when a call did not complete, the instance is not
+	// reused: the backend may have closed it, on timeouts notably.
+	failed bool
+}
+
+func (m *Module) acquireVM(functionName string) (*vm, error) {
+	if !m.reuseInstances {
+		// The previous instance is not used anymore once the next call
+		// starts, its outputs have been read
+		if m.lastVM != nil {
+			m.lastVM.instance.Close()
+		}
+		v, err := m.newVM(functionName)
+		m.lastVM = v
+		return v, err
+	}
+
+	pooled := m.pooledVMs[functionName]
+	if pooled != nil {
+		if pooled.reset(m.initialMemory) {
+			return pooled, nil
+		}
+		pooled.instance.Close()
+		delete(m.pooledVMs, functionName)
+	}
+
+	v, err := m.newVM(functionName)
+	if err != nil {
+		return nil, err
+	}
+	if resettable, ok := v.instance.(resettableInstance); !ok || !resettable.resettable() {
+		// Instantiating is the only way to reset instances of this
+		// backend, they are not reused
+		m.reuseInstances = false
+		m.lastVM = v
+		return v, nil
+	}
+	if m.initialMemory == nil {
+		// Instantiation is deterministic, all instances start with
+		// this exact memory
+		memory := v.instance.Memory()
+		m.initialMemory = make([]byte, len(memory))
+		copy(m.initialMemory, memory)
+	}
+
+	if m.pooledVMs == nil {
+		m.pooledVMs = map[string]*vm{}
+	}
+	m.pooledVMs[functionName] = v
+	return v, nil
+}
+
+func (m *Module) newVM(functionName string) (*vm, error) {
+	instance, err := m.module.NewInstance(functionName)
+	if err != nil {
+		return nil, err
+	}
+	return &vm{instance: instance}, nil
+}
+
+// resetChunkSize is the granularity at which memory is compared to
+// the initial memory, to only rewrite the chunks a call modified.
+const resetChunkSize = 4096
+
+// resettableInstance is implemented by the backend instances able to
+// restore their mutable globals, such as the stack pointer, to their
+// values right after instantiation. Only those are reused.
+type resettableInstance interface {
+	resettable() bool
+	resetGlobals()
+}
+
+// reset restores the memory and the globals of the vm to their state
+// right after instantiation, which also resets the guest allocator.
+// Memory cannot shrink, so a vm whose memory grew is not reusable: a
+// guest allocator would see a different memory size than on a fresh
+// instance.
+func (v *vm) reset(initialMemory []byte) bool {
+	data := v.instance.Memory()
+	if v.failed || len(data) != len(initialMemory) {
+		return false
+	}
+
+	for start := 0; start < len(data); start += resetChunkSize {
+		end := start + resetChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if !bytes.Equal(data[start:end], initialMemory[start:end]) {
+			copy(data[start:end], initialMemory[start:end])
+		}
+	}
+	v.instance.(resettableInstance).resetGlobals()
+	return true
+}
+
+// globalExportPrefix names the exports added to reach the mutable
+// globals of a module, followed by their index.
+const globalExportPrefix = "__substreams_global_"
+
+// exportMutableGlobals exports the mutable globals defined by `code`, so
+// that a backend can reset them, returning the names of the exports and
+// the edit of the export section.
+// The stack pointer of Rust modules, notably, is not exported. Global
+// imports are not supported by the runtime, the module indices of the
+// globals are their indices in the global section.
+func exportMutableGlobals(code []byte) ([]byte, []string, codeEdit, error) {
+	var mutable []uint32
+	var out []byte
+	var names []string
+	var edit codeEdit
+
+	sectionStart := len(wasmbin.Magic)
+	err := wasmbin.ReadSections(code, func(id byte, section *wasmbin.Reader, offset int) (err error) {
+		start, end := sectionStart, offset+len(section.Data)
+		sectionStart = end
+
+		switch id {
+		case wasmbin.SectionGlobal:
+			mutable, err = decodeMutableGlobals(section)
+			return err
+		case wasmbin.SectionExport:
+			if len(mutable) == 0 {
+				return nil
+			}
+
+			count, err := section.U32()
+			if err != nil {
+				return err
+			}
+			content := appendU32(nil, count+uint32(len(mutable)))
+			content = append(content, section.Data[section.Offset:]...)
+			for _, index := range mutable {
+				name := fmt.Sprintf("%s%d", globalExportPrefix, index)
+				names = append(names, name)
+				content = appendU32(content, uint32(len(name)))
+				content = append(content, name...)
+				content = append(content, wasmbin.ExternalGlobal)
+				content = appendU32(content, index)
+			}
+
+			out = make([]byte, 0, len(code)+len(content))
+			out = append(out, code[:start]...)
+			out = append(out, id)
+			out = appendU32(out, uint32(len(content)))
+			out = append(out, content...)
+			edit = codeEdit{
+				offset: uint64(len(out)),
+				delta:  int64(len(out) - end),
+			}
+			out = append(out, code[end:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, codeEdit{}, fmt.Errorf("exporting mutable globals: %w", err)
+	}
+	if len(mutable) != 0 && out == nil {
+		return nil, nil, codeEdit{}, fmt.Errorf("exporting mutable globals: no export section")
+	}
+	if out == nil {
+		return code, nil, codeEdit{}, nil
+	}
+	return out, names, edit, nil
+}
+
+// decodeMutableGlobals returns the indices of the mutable globals of
+// the global section `r`.
+func decodeMutableGlobals(r *wasmbin.Reader) ([]uint32, error) {
+	count, err := r.U32()
+	if err != nil {
+		return nil, err
+	}
+
+	var mutable []uint32
+	for i := uint32(0); i < count; i++ {
+		typ, err := r.Bytes(2) // value type and mutability
+		if err != nil {
+			return nil, err
+		}
+		if typ[1] == 0x01 {
+			mutable = append(mutable, i)
+		}
+		if err := r.SkipConstantExpression(); err != nil {
+			return nil, fmt.Errorf("global %d: %w", i, err)
+		}
+	}
+	return mutable, nil
+}
@@ -0,0 +1,131 @@
+package wasm
+
+import (
+	"context"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+)
+
+// newPoolTestModule returns the module of the `pool` test binary, whose
+// memory mimics a small Rust module, or of `pool_small` with a single
+// page of memory.
+func newPoolTestModule(t testing.TB, backend Backend, name string, reuse bool) *Module {
+	t.Helper()
+
+	module, err := NewRuntime(nil, WithBackend(backend)).NewModule(context.Background(), &pbsubstreams.Request{}, readTestWasm(t, name), "test_module")
+	require.NoError(t, err)
+	if reuse {
+		module.EnableInstanceReuse()
+	}
+	return module
+}
+
+func executePoolTest(t *testing.T, module *Module, functionName string) (*Instance, error) {
+	t.Helper()
+
+	instance, err := module.NewInstance(&pbsubstreams.Clock{}, functionName, nil)
+	require.NoError(t, err)
+	return instance, instance.Execute()
+}
+
+func TestInstanceReuse(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		for _, reuse := range []bool{false, true} {
+			module := newPoolTestModule(t, backend, "pool", reuse)
+
+			first, err := executePoolTest(t, module, "counter")
+			require.NoError(t, err)
+			second, err := executePoolTest(t, module, "counter")
+			require.NoError(t, err)
+
+			// The memory and the globals are reset between calls: a
+			// reused instance gives the same output as a fresh one
+			assert.Equal(t, []byte{1, 0, 0, 0}, first.Output(), "reuse=%v", reuse)
+			assert.Equal(t, []byte{1, 0, 0, 0}, second.Output(), "reuse=%v", reuse)
+			assert.Equal(t, reuse, first.vm == second.vm, "reuse=%v", reuse)
+		}
+	})
+}
+
+func TestInstanceReuse_Discarded(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		module := newPoolTestModule(t, backend, "pool", true)
+
+		first, err := executePoolTest(t, module, "counter")
+		require.NoError(t, err)
+		second, err := executePoolTest(t, module, "counter")
+		require.NoError(t, err)
+		require.Same(t, first.vm, second.vm)
+
+		first, err = executePoolTest(t, module, "grow")
+		require.NoError(t, err)
+		second, err = executePoolTest(t, module, "grow")
+		require.NoError(t, err)
+		assert.NotSame(t, first.vm, second.vm, "memory grew")
+
+		first, err = executePoolTest(t, module, "trap")
+		require.Error(t, err)
+		second, err = executePoolTest(t, module, "trap")
+		require.Error(t, err)
+		assert.NotSame(t, first.vm, second.vm, "call failed")
+	})
+}
+
+// BenchmarkInstancePerBlock measures the per-block overhead of running
+// a module doing almost nothing, with a new or a reused instance on each
+// backend. Resetting a reused instance restores its whole memory, its
+// cost grows with the size of the memory.
+func BenchmarkInstancePerBlock(b *testing.B) {
+	for _, name := range Backends() {
+		for _, memory := range []struct {
+			name   string
+			module string
+		}{
+			{"1_page", "pool_small"},
+			{"17_pages", "pool"},
+		} {
+			for _, bench := range []struct {
+				name  string
+				reuse bool
+			}{
+				{"new_instance", false},
+				{"reused_instance", true},
+			} {
+				b.Run(name+"/"+memory.name+"/"+bench.name, func(b *testing.B) {
+					module := newPoolTestModule(b, backends[name], memory.module, bench.reuse)
+					b.ResetTimer()
+
+					for i := 0; i < b.N; i++ {
+						instance, err := module.NewInstance(&pbsubstreams.Clock{}, "counter", nil)
+						if err != nil {
+							b.Fatal(err)
+						}
+						if err := instance.Execute(); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestExportMutableGlobals(t *testing.T) {
+	exported, names, _, err := exportMutableGlobals(readTestWasm(t, "globals"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"__substreams_global_0", "__substreams_global_2"}, names)
+
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+	mod, err := runtime.Instantiate(ctx, exported)
+	require.NoError(t, err)
+
+	assert.NotNil(t, mod.ExportedFunction("f"))
+	assert.Equal(t, uint64(11), mod.ExportedGlobal("__substreams_global_0").Get())
+	assert.Equal(t, ^uint64(0), mod.ExportedGlobal("__substreams_global_2").Get())
+}
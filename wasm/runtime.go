@@ -1,9 +1,25 @@
 package wasm
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Runtime struct {
+	backend    Backend
 	extensions map[string]map[string]WASMExtension
+
+	// cacheableExtensions are the extension functions whose calls can
+	// be memoized, by namespace and name.
+	cacheableExtensions map[string]map[string]bool
+
+	instructionBudget uint64        // per module per block, 0 for unlimited
+	executionTimeout  time.Duration // per module per block, 0 for none
+	maxMemoryPages    uint32        // per module instance, 0 for unlimited
+	reuseInstances    bool          // for all modules
+	maxLogBytes       uint64        // per module per block
+
+	moduleCache *ModuleCache // nil to always compile
 }
 
 func (r *Runtime) registerWASMExtension(namespace string, importName string, ext WASMExtension) {
@@ -29,14 +45,37 @@ func (r *Runtime) registerWASMExtension(namespace string, importName string, ext
 	r.extensions[namespace][importName] = ext
 }
 
-func NewRuntime(extensions []WASMExtensioner) *Runtime {
-	r := &Runtime{}
+func NewRuntime(extensions []WASMExtensioner, opts ...RuntimeOption) *Runtime {
+	r := &Runtime{
+		backend:     backends[defaultBackendName],
+		maxLogBytes: DefaultMaxLogBytes,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
 	for _, ext := range extensions {
 		for ns, exts := range ext.WASMExtensions() {
 			for name, ext := range exts {
 				r.registerWASMExtension(ns, name, ext)
 			}
 		}
+
+		if cacheable, ok := ext.(CacheableWASMExtensioner); ok {
+			for ns, names := range cacheable.CacheableWASMExtensions() {
+				for _, name := range names {
+					if r.extensions[ns][name] == nil {
+						panic(fmt.Sprintf("cacheable wasm extension namespace %q function %q not defined", ns, name))
+					}
+					if r.cacheableExtensions == nil {
+						r.cacheableExtensions = map[string]map[string]bool{}
+					}
+					if r.cacheableExtensions[ns] == nil {
+						r.cacheableExtensions[ns] = map[string]bool{}
+					}
+					r.cacheableExtensions[ns][name] = true
+				}
+			}
+		}
 	}
 	return r
 }
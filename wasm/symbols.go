@@ -0,0 +1,207 @@
+package wasm
+
+import (
+	"debug/dwarf"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/streamingfast/substreams/wasm/wasmbin"
+	"go.uber.org/zap"
+)
+
+// symbols resolves function names and source positions of a wasm binary,
+// from its `name` custom section and its DWARF custom sections, when
+// present.
+type symbols struct {
+	functionNames map[uint32]string
+
+	// DWARF addresses are offsets in the code section
+	codeOffset uint64
+	lines      []lineRow // sorted by address
+}
+
+type lineRow struct {
+	address     uint64
+	source      string
+	endSequence bool
+}
+
+// newSymbols reads the symbols of `code`. Symbols are best effort:
+// malformed sections are ignored.
+func newSymbols(code []byte) *symbols {
+	s := &symbols{}
+
+	debugSections := map[string][]byte{}
+	_ = wasmbin.ReadSections(code, func(id byte, section *wasmbin.Reader, offset int) error {
+		switch id {
+		case wasmbin.SectionCode:
+			s.codeOffset = uint64(offset)
+		case wasmbin.SectionCustom:
+			name, err := section.Name()
+			if err != nil {
+				return nil
+			}
+			content := section.Data[section.Offset:]
+			if name == "name" {
+				s.functionNames = decodeFunctionNames(&wasmbin.Reader{Data: content})
+			} else if strings.HasPrefix(name, ".debug_") {
+				debugSections[name] = content
+			}
+		}
+		return nil
+	})
+
+	if len(debugSections) != 0 {
+		if lines, err := decodeLines(debugSections); err == nil {
+			s.lines = lines
+		} else {
+			zlog.Debug("ignoring invalid wasm debug info", zap.Error(err))
+		}
+	}
+	return s
+}
+
+// decodeFunctionNames reads the function names subsection of the `name`
+// custom section.
+func decodeFunctionNames(r *wasmbin.Reader) map[uint32]string {
+	for !r.Done() {
+		id, err := r.Byte()
+		if err != nil {
+			return nil
+		}
+		size, err := r.U32()
+		if err != nil {
+			return nil
+		}
+		content, err := r.Bytes(int(size))
+		if err != nil {
+			return nil
+		}
+		if id != 1 {
+			continue
+		}
+
+		sub := &wasmbin.Reader{Data: content}
+		count, err := sub.U32()
+		if err != nil {
+			return nil
+		}
+		names := make(map[uint32]string, count)
+		for i := uint32(0); i < count; i++ {
+			index, err := sub.U32()
+			if err != nil {
+				return nil
+			}
+			name, err := sub.Name()
+			if err != nil {
+				return nil
+			}
+			names[index] = name
+		}
+		return names
+	}
+	return nil
+}
+
+// decodeLines reads the line tables of all the compilation units.
+func decodeLines(sections map[string][]byte) ([]lineRow, error) {
+	data, err := dwarf.New(
+		sections[".debug_abbrev"],
+		sections[".debug_aranges"],
+		sections[".debug_frame"],
+		sections[".debug_info"],
+		sections[".debug_line"],
+		sections[".debug_pubnames"],
+		sections[".debug_ranges"],
+		sections[".debug_str"],
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{".debug_addr", ".debug_line_str", ".debug_str_offsets", ".debug_rnglists"} {
+		if section, found := sections[name]; found {
+			if err := data.AddSection(name, section); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var rows []lineRow
+	reader := data.Reader()
+	for {
+		entry, err := reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit {
+			reader.SkipChildren()
+			continue
+		}
+
+		lineReader, err := data.LineReader(entry)
+		if err != nil {
+			return nil, fmt.Errorf("compilation unit at %d: %w", entry.Offset, err)
+		}
+		reader.SkipChildren()
+		if lineReader == nil {
+			continue
+		}
+
+		var line dwarf.LineEntry
+		for {
+			if err := lineReader.Next(&line); err != nil {
+				break
+			}
+			row := lineRow{address: line.Address, endSequence: line.EndSequence}
+			if !line.EndSequence && line.File != nil {
+				row.source = fmt.Sprintf("%s:%d:%d", line.File.Name, line.Line, line.Column)
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	// At a given address, the end of a sequence comes before the start of
+	// the next one
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].address != rows[j].address {
+			return rows[i].address < rows[j].address
+		}
+		return rows[i].endSequence && !rows[j].endSequence
+	})
+	return rows, nil
+}
+
+// source returns the source position of the instruction at `moduleOffset`
+// in the binary.
+func (s *symbols) source(moduleOffset uint64) (string, bool) {
+	if len(s.lines) == 0 || moduleOffset < s.codeOffset {
+		return "", false
+	}
+	address := moduleOffset - s.codeOffset
+
+	i := sort.Search(len(s.lines), func(i int) bool { return s.lines[i].address > address }) - 1
+	if i < 0 || s.lines[i].endSequence || s.lines[i].source == "" {
+		return "", false
+	}
+	return s.lines[i].source, true
+}
+
+// symbolize completes the names and sources of `frames` the backend did
+// not resolve.
+func (s *symbols) symbolize(frames []StackFrame) {
+	for i := range frames {
+		frame := &frames[i]
+		if frame.Function == "" && frame.FunctionIndex >= 0 {
+			frame.Function = s.functionNames[uint32(frame.FunctionIndex)]
+		}
+		if len(frame.Sources) == 0 && frame.ModuleOffset != 0 {
+			if source, found := s.source(frame.ModuleOffset); found {
+				frame.Sources = []string{source}
+			}
+		}
+	}
+}
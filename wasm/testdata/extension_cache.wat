@@ -0,0 +1,11 @@
+(module
+  (import "myext" "cached" (func $cached (param i32 i32 i32)))
+  (import "myext" "fresh" (func $fresh (param i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "input")
+  (func (export "alloc") (param i32) (result i32)
+    i32.const 2048)
+  (func (export "call_extensions")
+    (call $cached (i32.const 0) (i32.const 5) (i32.const 1024))
+    (call $fresh (i32.const 0) (i32.const 5) (i32.const 1024)))
+)
@@ -0,0 +1,5 @@
+(module
+  (global $stack_pointer (mut i32) (i32.const 11))
+  (global $constant i32 (i32.const 1))
+  (global $counter (mut i64) (i64.const -1))
+  (func (export "f")))
@@ -0,0 +1,17 @@
+(module
+  (memory (export "memory") 1)
+  (func (export "alloc") (param i32) (result i32)
+    i32.const 0)
+  (func (export "loop_forever")
+    (loop $l
+      br $l))
+  (func (export "count") (param $n i32)
+    (local $i i32)
+    (block $done
+      (loop $l
+        (br_if $done (i32.ge_u (local.get $i) (local.get $n)))
+        (local.set $i (i32.add (local.get $i) (i32.const 1)))
+        br $l)))
+  (func (export "grow") (param $pages i32)
+    (if (i32.eq (memory.grow (local.get $pages)) (i32.const -1))
+      (then unreachable))))
@@ -0,0 +1,18 @@
+;; Logs the entries of its params input through the `logger::log`
+;; import, each prefixed by its size as a little endian u32.
+(module
+  (import "logger" "log" (func $log (param i32 i32)))
+  (memory (export "memory") 1)
+  (func (export "alloc") (param i32) (result i32)
+    i32.const 0)
+  (func (export "log_entries") (param $ptr i32) (param $len i32)
+    (local $end i32)
+    (local $size i32)
+    (local.set $end (i32.add (local.get $ptr) (local.get $len)))
+    (block $done
+      (loop $l
+        (br_if $done (i32.ge_u (local.get $ptr) (local.get $end)))
+        (local.set $size (i32.load (local.get $ptr)))
+        (call $log (i32.add (local.get $ptr) (i32.const 4)) (local.get $size))
+        (local.set $ptr (i32.add (local.get $ptr) (i32.add (local.get $size) (i32.const 4))))
+        br $l))))
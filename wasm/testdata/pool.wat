@@ -0,0 +1,16 @@
+;; The memory size mimics a small Rust module, with its 1 MiB stack.
+(module
+  (import "env" "output" (func $output (param i32 i32)))
+  (memory (export "memory") 17)
+  (global $calls (mut i32) (i32.const 0))
+  (data (i32.const 1024) "\00\00\00\00")
+  (func (export "alloc") (param i32) (result i32)
+    i32.const 2048)
+  (func (export "counter")
+    (global.set $calls (i32.add (global.get $calls) (i32.const 1)))
+    (i32.store (i32.const 1024) (i32.add (i32.load (i32.const 1024)) (global.get $calls)))
+    (call $output (i32.const 1024) (i32.const 4)))
+  (func (export "grow")
+    (drop (memory.grow (i32.const 1))))
+  (func (export "trap")
+    unreachable))
@@ -0,0 +1,17 @@
+(module
+  (import "env" "register_panic" (func $register_panic (param i32 i32 i32 i32 i32 i32)))
+  (memory (export "memory") 1)
+  (global $stack_pointer (mut i32) (i32.const 1024))
+  (data (i32.const 0) "boomsrc/lib.rs")
+  (func $alloc (export "alloc") (param i32) (result i32)
+    i32.const 0)
+  (func $inner
+    unreachable)
+  (func $outer
+    call $inner)
+  (func $trap (export "trap")
+    call $outer)
+  (func $panic (export "panic")
+    (call $register_panic (i32.const 0) (i32.const 4) (i32.const 4) (i32.const 10) (i32.const 12) (i32.const 5))
+    unreachable)
+)
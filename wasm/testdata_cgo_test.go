@@ -0,0 +1,41 @@
+//go:build cgo
+
+package wasm
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+var updateTestdata = flag.Bool("update-testdata", false, "compile the wat sources of testdata to their wasm binaries")
+
+// TestTestdata checks that the binaries of testdata are compiled from
+// their wat sources. Run with `-update-testdata` after editing them.
+func TestTestdata(t *testing.T) {
+	sources, err := filepath.Glob(filepath.Join("testdata", "*.wat"))
+	require.NoError(t, err)
+	require.NotEmpty(t, sources)
+
+	for _, source := range sources {
+		name := strings.TrimSuffix(filepath.Base(source), ".wat")
+		t.Run(name, func(t *testing.T) {
+			wat, err := os.ReadFile(source)
+			require.NoError(t, err)
+			code, err := wasmer.Wat2Wasm(string(wat))
+			require.NoError(t, err)
+
+			if *updateTestdata {
+				require.NoError(t, os.WriteFile(filepath.Join("testdata", name+".wasm"), code, 0644))
+				return
+			}
+			assert.Equal(t, code, readTestWasm(t, name), "testdata/%s.wasm is outdated, run the tests with -update-testdata", name)
+		})
+	}
+}
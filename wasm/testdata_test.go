@@ -0,0 +1,19 @@
+package wasm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readTestWasm returns the binary compiled from `testdata/<name>.wat`,
+// checked in so that the tests do not need cgo to compile it.
+func readTestWasm(t testing.TB, name string) []byte {
+	t.Helper()
+
+	code, err := os.ReadFile(filepath.Join("testdata", name+".wasm"))
+	require.NoError(t, err)
+	return code
+}
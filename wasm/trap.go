@@ -0,0 +1,64 @@
+package wasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TrapError is a wasm trap, with the call stack of the guest when it
+// happened. Backends report traps with the frames they know about, the
+// `Module` then symbolizes them with the names and debug info of the
+// binary.
+type TrapError struct {
+	Err    error
+	Frames []StackFrame // innermost first
+}
+
+func (e *TrapError) Error() string {
+	if len(e.Frames) == 0 {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + "\n" + formatStackTrace(e.Frames)
+}
+
+func (e *TrapError) Unwrap() error {
+	return e.Err
+}
+
+// StackFrame is a frame of the wasm call stack.
+type StackFrame struct {
+	FunctionIndex int64  // in the function index space, -1 when unknown
+	ModuleOffset  uint64 // of the current instruction in the binary, 0 when unknown
+
+	Function string   // name of the function, empty when unknown
+	Sources  []string // `file:line:column` of the current instruction, innermost inlined call first
+}
+
+func (f StackFrame) String() string {
+	name := f.Function
+	if name == "" {
+		if f.FunctionIndex >= 0 {
+			name = fmt.Sprintf("$%d", f.FunctionIndex)
+		} else {
+			name = "<unknown>"
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(name)
+	for _, source := range f.Sources {
+		out.WriteString("\n\t\tat ")
+		out.WriteString(source)
+	}
+	return out.String()
+}
+
+func formatStackTrace(frames []StackFrame) string {
+	var out strings.Builder
+	out.WriteString("wasm stack trace:")
+	for _, frame := range frames {
+		out.WriteString("\n\t")
+		out.WriteString(frame.String())
+	}
+	return out.String()
+}
@@ -0,0 +1,227 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pbsubstreams "github.com/streamingfast/substreams/pb/sf/substreams/v1"
+	"github.com/streamingfast/substreams/wasm/wasmbin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTrapTestInstance(t *testing.T, backend Backend, code []byte, functionName string, opts ...RuntimeOption) *Instance {
+	t.Helper()
+
+	opts = append(opts, WithBackend(backend))
+	module, err := NewRuntime(nil, opts...).NewModule(context.Background(), &pbsubstreams.Request{}, code, "test_module")
+	require.NoError(t, err)
+
+	instance, err := module.NewInstance(&pbsubstreams.Clock{}, functionName, nil)
+	require.NoError(t, err)
+	return instance
+}
+
+func frameNames(frames []StackFrame) (out []string) {
+	for _, frame := range frames {
+		out = append(out, frame.Function)
+	}
+	return
+}
+
+func TestTrap_StackTrace(t *testing.T) {
+	code := readTestWasm(t, "trap")
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		err := newTrapTestInstance(t, backend, code, "trap").Execute()
+		require.Error(t, err)
+
+		var trapErr *TrapError
+		require.True(t, errors.As(err, &trapErr))
+		assert.Equal(t, []string{"inner", "outer", "trap"}, frameNames(trapErr.Frames))
+		assert.Contains(t, err.Error(), "unreachable\nwasm stack trace:\n\tinner\n\touter\n\ttrap")
+	})
+}
+
+func TestTrap_Panic(t *testing.T) {
+	code := readTestWasm(t, "trap")
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		err := newTrapTestInstance(t, backend, code, "panic").Execute()
+		require.Error(t, err)
+
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+		assert.Equal(t, []string{"panic"}, frameNames(panicErr.Frames()))
+		assert.Equal(t, "panic in the wasm module: \"boom\" at src/lib.rs:12:5\nwasm stack trace:\n\tpanic", err.Error())
+	})
+}
+
+func TestTrap_DebugInfo(t *testing.T) {
+	code := readTestWasm(t, "trap")
+
+	// Lines of the first instruction of each defined function
+	starts, codeSize := functionStarts(t, code)
+	code = appendDebugInfo(code, codeSize, []debugLine{
+		{address: starts[0], line: 3, column: 5},  // alloc
+		{address: starts[1], line: 7, column: 9},  // inner
+		{address: starts[2], line: 11, column: 5}, // outer
+		{address: starts[3], line: 15, column: 5}, // trap
+		{address: starts[4], line: 20, column: 5}, // panic
+	})
+
+	forEachBackend(t, func(t *testing.T, backend Backend) {
+		// Limiting the memory and exporting the globals edit the binary
+		// compiled by wasmer
+		for _, opts := range [][]RuntimeOption{nil, {WithMaxMemoryPages(4)}} {
+			err := newTrapTestInstance(t, backend, code, "trap", opts...).Execute()
+			require.Error(t, err)
+
+			var trapErr *TrapError
+			require.True(t, errors.As(err, &trapErr))
+			require.Len(t, trapErr.Frames, 3)
+			assert.Equal(t, []string{"src/lib.rs:7:9"}, trapErr.Frames[0].Sources)
+			assert.Equal(t, []string{"src/lib.rs:11:5"}, trapErr.Frames[1].Sources)
+			assert.Equal(t, []string{"src/lib.rs:15:5"}, trapErr.Frames[2].Sources)
+			assert.Contains(t, err.Error(), "wasm stack trace:\n\tinner\n\t\tat src/lib.rs:7:9\n\touter\n\t\tat src/lib.rs:11:5")
+		}
+	})
+}
+
+func TestNewSymbols_Invalid(t *testing.T) {
+	s := newSymbols([]byte("pairExtractor code"))
+
+	frames := []StackFrame{{FunctionIndex: 1, ModuleOffset: 42}}
+	s.symbolize(frames)
+	assert.Equal(t, []StackFrame{{FunctionIndex: 1, ModuleOffset: 42}}, frames)
+}
+
+// functionStarts returns the offsets in the code section of the first
+// instruction of each function body, which must not declare locals, and
+// the size of the code section.
+func functionStarts(t *testing.T, code []byte) (starts []uint64, codeSize uint64) {
+	t.Helper()
+
+	err := wasmbin.ReadSections(code, func(id byte, section *wasmbin.Reader, _ int) error {
+		if id != wasmbin.SectionCode {
+			return nil
+		}
+		codeSize = uint64(len(section.Data))
+		count, err := section.U32()
+		require.NoError(t, err)
+		for i := uint32(0); i < count; i++ {
+			size, err := section.U32()
+			require.NoError(t, err)
+			starts = append(starts, uint64(section.Offset+1)) // after the empty locals vector
+			_, err = section.Bytes(int(size))
+			require.NoError(t, err)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return
+}
+
+func appendUint32(out []byte, v uint32) []byte {
+	return append(out, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendULEB128(out []byte, v uint64) []byte {
+	for v >= 0x80 {
+		out = append(out, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(out, byte(v))
+}
+
+func appendSLEB128(out []byte, v int64) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+type debugLine struct {
+	address uint64
+	line    int
+	column  uint64
+}
+
+// appendDebugInfo adds a DWARF 4 compilation unit for `src/lib.rs` with
+// the given line table, covering the code section from the first line
+// (wazero ignores units at address 0).
+func appendDebugInfo(code []byte, codeSize uint64, lines []debugLine) []byte {
+	abbrev := []byte{
+		1, 0x11, 0, // compile unit, no children
+		0x03, 0x08, // name, string
+		0x10, 0x17, // stmt_list, sec_offset
+		0x11, 0x01, // low_pc, addr
+		0x12, 0x06, // high_pc, data4
+		0, 0,
+		0,
+	}
+
+	unit := []byte{4, 0, 0, 0, 0, 0, 4} // version, abbrev offset, address size
+	unit = append(unit, 1)
+	unit = append(unit, "lib.rs\x00"...)
+	unit = appendUint32(unit, 0)
+	unit = appendUint32(unit, uint32(lines[0].address))
+	unit = appendUint32(unit, uint32(codeSize-lines[0].address))
+	info := appendUint32(nil, uint32(len(unit)))
+	info = append(info, unit...)
+
+	header := []byte{1, 1, 1, 0xfb, 14, 13} // min inst length, max ops, default is_stmt, line base, line range, opcode base
+	header = append(header, 0, 1, 1, 1, 1, 0, 0, 0, 1, 0, 0, 1)
+	header = append(header, "src\x00\x00"...)
+	header = append(header, "lib.rs\x00"...)
+	header = append(header, 1, 0, 0, 0)
+
+	setAddress := func(program []byte, address uint64) []byte {
+		program = append(program, 0, 5, 0x02)
+		return appendUint32(program, uint32(address))
+	}
+	var program []byte
+	line := 1
+	for _, l := range lines {
+		program = setAddress(program, l.address)
+		program = append(program, 0x03)
+		program = appendSLEB128(program, int64(l.line-line))
+		program = append(program, 0x05)
+		program = appendULEB128(program, l.column)
+		program = append(program, 0x01)
+		line = l.line
+	}
+	program = setAddress(program, codeSize)
+	program = append(program, 0, 1, 0x01)
+
+	unit = []byte{4, 0}
+	unit = appendUint32(unit, uint32(len(header)))
+	unit = append(unit, header...)
+	unit = append(unit, program...)
+	lineTable := appendUint32(nil, uint32(len(unit)))
+	lineTable = append(lineTable, unit...)
+
+	out := append([]byte{}, code...)
+	for _, section := range []struct {
+		name    string
+		content []byte
+	}{
+		{".debug_abbrev", abbrev},
+		{".debug_info", info},
+		{".debug_line", lineTable},
+	} {
+		content := appendULEB128(nil, uint64(len(section.name)))
+		content = append(content, section.name...)
+		content = append(content, section.content...)
+
+		out = append(out, wasmbin.SectionCustom)
+		out = appendULEB128(out, uint64(len(content)))
+		out = append(out, content...)
+	}
+	return out
+}
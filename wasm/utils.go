@@ -2,25 +2,26 @@ package wasm
 
 import (
 	"fmt"
-
-	"github.com/wasmerio/wasmer-go/wasmer"
 )
 
-func Params(kinds ...wasmer.ValueKind) []*wasmer.ValueType {
-	return wasmer.NewValueTypes(kinds...)
-}
-
-func Returns(kinds ...wasmer.ValueKind) []*wasmer.ValueType {
-	return wasmer.NewValueTypes(kinds...)
-}
-
 type PanicError struct {
 	message      string
 	filename     string
 	lineNumber   int
 	columnNumber int
+	frames       []StackFrame
 }
 
 func (e *PanicError) Error() string {
-	return fmt.Sprintf("panic in the wasm module: %q at %s:%d:%d", e.message, e.filename, e.lineNumber, e.columnNumber)
+	msg := fmt.Sprintf("panic in the wasm module: %q at %s:%d:%d", e.message, e.filename, e.lineNumber, e.columnNumber)
+	if len(e.frames) != 0 {
+		msg += "\n" + formatStackTrace(e.frames)
+	}
+	return msg
+}
+
+// Frames returns the wasm call stack when the module panicked, innermost
+// first.
+func (e *PanicError) Frames() []StackFrame {
+	return e.frames
 }
@@ -0,0 +1,252 @@
+// Package wasmbin decodes the parts of wasm binaries needed to validate
+// substreams modules, and describes the host functions provided to
+// them. It does not depend on any wasm runtime, so that reading
+// manifests does not pull one.
+package wasmbin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ValueType is the type of a wasm value.
+type ValueType byte
+
+const (
+	I32 ValueType = iota + 1
+	I64
+	F32
+	F64
+)
+
+// Value types of guest functions that host functions cannot use, so that
+// modules using SIMD or reference types internally are still valid.
+const (
+	v128 ValueType = iota + F64 + 1
+	funcRef
+	externRef
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case F32:
+		return "f32"
+	case F64:
+		return "f64"
+	case v128:
+		return "v128"
+	case funcRef:
+		return "funcref"
+	case externRef:
+		return "externref"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(t))
+	}
+}
+
+// Section identifiers and encodings of the wasm binary format, limited
+// to what is needed to list imported and exported functions, to
+// symbolize stack traces, to limit memories and to export globals.
+const (
+	SectionCustom   = 0
+	SectionType     = 1
+	SectionImport   = 2
+	SectionFunction = 3
+	SectionMemory   = 5
+	SectionGlobal   = 6
+	SectionExport   = 7
+	SectionCode     = 10
+
+	ExternalFunction = 0x00
+	ExternalTable    = 0x01
+	ExternalMemory   = 0x02
+	ExternalGlobal   = 0x03
+
+	functionTypeForm = 0x60
+)
+
+// Magic is the header of wasm binaries, with the version.
+var Magic = []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+
+// ReadSections calls `f` with the content of each section of a wasm
+// binary, and its offset in the binary.
+func ReadSections(code []byte, f func(id byte, section *Reader, offset int) error) error {
+	if !bytes.HasPrefix(code, Magic) {
+		return fmt.Errorf("invalid magic number or version")
+	}
+
+	r := &Reader{Data: code, Offset: len(Magic)}
+	for !r.Done() {
+		id, err := r.Byte()
+		if err != nil {
+			return err
+		}
+		size, err := r.U32()
+		if err != nil {
+			return err
+		}
+		offset := r.Offset
+		content, err := r.Bytes(int(size))
+		if err != nil {
+			return fmt.Errorf("section %d: %w", id, err)
+		}
+
+		if err := f(id, &Reader{Data: content}, offset); err != nil {
+			return fmt.Errorf("section %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Reader reads the encodings of the wasm binary format from `Data`,
+// starting at `Offset`.
+type Reader struct {
+	Data   []byte
+	Offset int
+}
+
+func (r *Reader) Done() bool {
+	return r.Offset >= len(r.Data)
+}
+
+func (r *Reader) Byte() (byte, error) {
+	if r.Done() {
+		return 0, fmt.Errorf("unexpected end of data at offset %d", r.Offset)
+	}
+	b := r.Data[r.Offset]
+	r.Offset++
+	return b, nil
+}
+
+func (r *Reader) Bytes(n int) ([]byte, error) {
+	if n < 0 || r.Offset+n > len(r.Data) {
+		return nil, fmt.Errorf("unexpected end of data reading %d bytes at offset %d", n, r.Offset)
+	}
+	b := r.Data[r.Offset : r.Offset+n]
+	r.Offset += n
+	return b, nil
+}
+
+func (r *Reader) U32() (uint32, error) {
+	v, n := binary.Uvarint(r.Data[r.Offset:])
+	if n <= 0 || v > 0xFFFFFFFF {
+		return 0, fmt.Errorf("invalid u32 at offset %d", r.Offset)
+	}
+	r.Offset += n
+	return uint32(v), nil
+}
+
+func (r *Reader) Name() (string, error) {
+	size, err := r.U32()
+	if err != nil {
+		return "", err
+	}
+	b, err := r.Bytes(int(size))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *Reader) valueTypes() ([]ValueType, error) {
+	count, err := r.U32()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]ValueType, count)
+	for i := range types {
+		b, err := r.Byte()
+		if err != nil {
+			return nil, err
+		}
+		switch b {
+		case 0x7f:
+			types[i] = I32
+		case 0x7e:
+			types[i] = I64
+		case 0x7d:
+			types[i] = F32
+		case 0x7c:
+			types[i] = F64
+		case 0x7b:
+			types[i] = v128
+		case 0x70:
+			types[i] = funcRef
+		case 0x6f:
+			types[i] = externRef
+		default:
+			return nil, fmt.Errorf("unsupported value type 0x%x", b)
+		}
+	}
+	return types, nil
+}
+
+func (r *Reader) skipLimits() error {
+	flags, err := r.Byte()
+	if err != nil {
+		return err
+	}
+	if _, err := r.U32(); err != nil { // min
+		return err
+	}
+	if flags&0x01 != 0 {
+		if _, err := r.U32(); err != nil { // max
+			return err
+		}
+	}
+	return nil
+}
+
+// SkipConstantExpression skips the initializer of a global, limited to
+// a single constant instruction followed by `end`.
+func (r *Reader) SkipConstantExpression() error {
+	opcode, err := r.Byte()
+	if err != nil {
+		return err
+	}
+
+	switch opcode {
+	case 0x41, 0x42: // i32.const, i64.const
+		err = r.skipLEB128()
+	case 0x43: // f32.const
+		_, err = r.Bytes(4)
+	case 0x44: // f64.const
+		_, err = r.Bytes(8)
+	case 0x23, 0xd2: // global.get, ref.func
+		_, err = r.U32()
+	case 0xd0: // ref.null
+		_, err = r.Byte()
+	default:
+		err = fmt.Errorf("unsupported constant instruction 0x%x", opcode)
+	}
+	if err != nil {
+		return err
+	}
+
+	end, err := r.Byte()
+	if err != nil {
+		return err
+	}
+	if end != 0x0b {
+		return fmt.Errorf("unsupported constant expression, expected end, got 0x%x", end)
+	}
+	return nil
+}
+
+func (r *Reader) skipLEB128() error {
+	for {
+		b, err := r.Byte()
+		if err != nil {
+			return err
+		}
+		if b&0x80 == 0 {
+			return nil
+		}
+	}
+}
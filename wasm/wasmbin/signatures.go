@@ -0,0 +1,76 @@
+package wasmbin
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Signature is the signature of a wasm function.
+type Signature struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+func (s Signature) Equal(other Signature) bool {
+	return bytes.Equal(valueTypeBytes(s.Params), valueTypeBytes(other.Params)) &&
+		bytes.Equal(valueTypeBytes(s.Results), valueTypeBytes(other.Results))
+}
+
+func (s Signature) String() string {
+	return fmt.Sprintf("(%s) -> (%s)", valueTypesString(s.Params), valueTypesString(s.Results))
+}
+
+func valueTypeBytes(types []ValueType) []byte {
+	out := make([]byte, len(types))
+	for i, t := range types {
+		out[i] = byte(t)
+	}
+	return out
+}
+
+func valueTypesString(types []ValueType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// HostFunctions are the signatures of the host functions provided to
+// every module, by namespace and name. Strings and byte slices are
+// passed as an offset and a length in the memory of the module.
+var HostFunctions = map[string]map[string]Signature{
+	"env": {
+		"register_panic": {Params: []ValueType{I32, I32 /* message */, I32, I32 /* filename */, I32 /* line */, I32 /* column */}},
+		"output":         {Params: []ValueType{I32, I32 /* output */}},
+	},
+	"logger": {
+		"println": {Params: []ValueType{I32, I32 /* message */}},
+		"log":     {Params: []ValueType{I32, I32 /* encoded sf.substreams.v1.LogEntry */}},
+	},
+	"state": {
+		"set":               {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"set_if_not_exists": {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"delete_prefix":     {Params: []ValueType{I64 /* ordinal */, I32, I32 /* prefix */}},
+		"add_bigfloat":      {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"add_bigint":        {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"add_int64":         {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I64 /* value */}},
+		"add_float64":       {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, F64 /* value */}},
+		"set_min_int64":     {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I64 /* value */}},
+		"set_min_bigint":    {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"set_min_float64":   {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, F64 /* value */}},
+		"set_min_bigfloat":  {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"set_max_int64":     {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I64 /* value */}},
+		"set_max_bigint":    {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"set_max_float64":   {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, F64 /* value */}},
+		"set_max_bigfloat":  {Params: []ValueType{I64 /* ordinal */, I32, I32 /* key */, I32, I32 /* value */}},
+		"get_at":            {Params: []ValueType{I32 /* store index */, I64 /* ordinal */, I32, I32 /* key */, I32 /* output pointer */}, Results: []ValueType{I32 /* found */}},
+		"get_first":         {Params: []ValueType{I32 /* store index */, I32, I32 /* key */, I32 /* output pointer */}, Results: []ValueType{I32 /* found */}},
+		"get_last":          {Params: []ValueType{I32 /* store index */, I32, I32 /* key */, I32 /* output pointer */}, Results: []ValueType{I32 /* found */}},
+	},
+}
+
+// ExtensionSignature is the signature of all the functions of wasm
+// extensions.
+var ExtensionSignature = Signature{Params: []ValueType{I32, I32 /* input */, I32 /* output pointer */}}
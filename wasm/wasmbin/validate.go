@@ -0,0 +1,238 @@
+package wasmbin
+
+import (
+	"fmt"
+)
+
+// Validate checks, without compiling it, that the wasm binary `code`
+// only imports host functions provided by the runtime with their exact
+// signature, and that it exports each of the `entrypoints` taking the
+// given number of parameters.
+//
+// Imports from the namespaces of `extensions`, the names of the
+// functions of the registered wasm extensions by namespace, must exist
+// and have the extension signature. Imports from other namespaces are
+// errors, unless `allowUnknownExtensions` is set for clients, which
+// cannot know the extensions registered on the server: they then only
+// need the extension signature.
+func Validate(code []byte, entrypoints map[string]int, extensions map[string][]string, allowUnknownExtensions bool) error {
+	decoded, err := decodeBinary(code)
+	if err != nil {
+		return fmt.Errorf("decoding wasm binary: %w", err)
+	}
+
+	for _, imp := range decoded.imports {
+		var expected *Signature
+		if functions, found := HostFunctions[imp.namespace]; found {
+			if signature, found := functions[imp.name]; found {
+				expected = &signature
+			}
+		} else if functions, found := extensions[imp.namespace]; found {
+			if contains(functions, imp.name) {
+				expected = &ExtensionSignature
+			}
+		} else if allowUnknownExtensions {
+			expected = &ExtensionSignature
+		}
+
+		if expected == nil {
+			return fmt.Errorf("importing unknown host function \"%s::%s\"", imp.namespace, imp.name)
+		}
+		if !imp.signature.Equal(*expected) {
+			return fmt.Errorf("host function \"%s::%s\" imported with signature %s, expected %s", imp.namespace, imp.name, imp.signature, expected)
+		}
+	}
+
+	for entrypoint, params := range entrypoints {
+		exported, found := decoded.exports[entrypoint]
+		if !found {
+			return fmt.Errorf("entrypoint %q not exported", entrypoint)
+		}
+		if len(exported.Params) != params {
+			return fmt.Errorf("entrypoint %q takes %d parameters, expected %d for the module inputs", entrypoint, len(exported.Params), params)
+		}
+	}
+
+	return nil
+}
+
+func contains(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+type importedFunction struct {
+	namespace string
+	name      string
+	signature Signature
+}
+
+type decodedBinary struct {
+	imports []importedFunction
+	exports map[string]Signature // exported functions, by name
+}
+
+// decodeBinary reads the imported and exported functions of a wasm
+// binary.
+func decodeBinary(code []byte) (*decodedBinary, error) {
+	var types []Signature
+	var functions []Signature // imported first, then defined
+	var exports map[string]uint32
+	out := &decodedBinary{exports: map[string]Signature{}}
+
+	err := ReadSections(code, func(id byte, section *Reader, _ int) (err error) {
+		switch id {
+		case SectionType:
+			types, err = decodeTypes(section)
+		case SectionImport:
+			out.imports, err = decodeImports(section, types)
+			for _, imp := range out.imports {
+				functions = append(functions, imp.signature)
+			}
+		case SectionFunction:
+			var defined []Signature
+			defined, err = decodeFunctions(section, types)
+			functions = append(functions, defined...)
+		case SectionExport:
+			exports, err = decodeExports(section)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, index := range exports {
+		if int(index) >= len(functions) {
+			return nil, fmt.Errorf("export %q: invalid function index %d", name, index)
+		}
+		out.exports[name] = functions[index]
+	}
+	return out, nil
+}
+
+func decodeTypes(r *Reader) ([]Signature, error) {
+	count, err := r.U32()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]Signature, count)
+	for i := range types {
+		form, err := r.Byte()
+		if err != nil {
+			return nil, err
+		}
+		if form != functionTypeForm {
+			return nil, fmt.Errorf("type %d: invalid form 0x%x", i, form)
+		}
+		if types[i].Params, err = r.valueTypes(); err != nil {
+			return nil, fmt.Errorf("type %d: %w", i, err)
+		}
+		if types[i].Results, err = r.valueTypes(); err != nil {
+			return nil, fmt.Errorf("type %d: %w", i, err)
+		}
+	}
+	return types, nil
+}
+
+func decodeImports(r *Reader, types []Signature) ([]importedFunction, error) {
+	count, err := r.U32()
+	if err != nil {
+		return nil, err
+	}
+
+	var imports []importedFunction
+	for i := uint32(0); i < count; i++ {
+		namespace, err := r.Name()
+		if err != nil {
+			return nil, err
+		}
+		name, err := r.Name()
+		if err != nil {
+			return nil, err
+		}
+		kind, err := r.Byte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case ExternalFunction:
+			typeIndex, err := r.U32()
+			if err != nil {
+				return nil, err
+			}
+			if int(typeIndex) >= len(types) {
+				return nil, fmt.Errorf("import \"%s::%s\": invalid type index %d", namespace, name, typeIndex)
+			}
+			imports = append(imports, importedFunction{namespace: namespace, name: name, signature: types[typeIndex]})
+		case ExternalTable:
+			if _, err := r.Byte(); err != nil { // element type
+				return nil, err
+			}
+			err = r.skipLimits()
+		case ExternalMemory:
+			err = r.skipLimits()
+		case ExternalGlobal:
+			_, err = r.Bytes(2) // value type and mutability
+		default:
+			err = fmt.Errorf("import \"%s::%s\": invalid kind 0x%x", namespace, name, kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return imports, nil
+}
+
+func decodeFunctions(r *Reader, types []Signature) ([]Signature, error) {
+	count, err := r.U32()
+	if err != nil {
+		return nil, err
+	}
+
+	functions := make([]Signature, count)
+	for i := range functions {
+		typeIndex, err := r.U32()
+		if err != nil {
+			return nil, err
+		}
+		if int(typeIndex) >= len(types) {
+			return nil, fmt.Errorf("function %d: invalid type index %d", i, typeIndex)
+		}
+		functions[i] = types[typeIndex]
+	}
+	return functions, nil
+}
+
+func decodeExports(r *Reader) (map[string]uint32, error) {
+	count, err := r.U32()
+	if err != nil {
+		return nil, err
+	}
+
+	exports := map[string]uint32{}
+	for i := uint32(0); i < count; i++ {
+		name, err := r.Name()
+		if err != nil {
+			return nil, err
+		}
+		kind, err := r.Byte()
+		if err != nil {
+			return nil, err
+		}
+		index, err := r.U32()
+		if err != nil {
+			return nil, err
+		}
+		if kind == ExternalFunction {
+			exports[name] = index
+		}
+	}
+	return exports, nil
+}
@@ -0,0 +1,113 @@
+//go:build cgo
+
+package wasmbin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wasmerio/wasmer-go/wasmer"
+)
+
+func TestValidate(t *testing.T) {
+	const exports = `
+  (memory (export "memory") 1)
+  (func (export "alloc") (param i32) (result i32)
+    i32.const 0)
+  (func (export "map_transfers") (param i32 i32 i32))`
+
+	tests := []struct {
+		name                   string
+		imports                string
+		entrypoints            map[string]int
+		allowUnknownExtensions bool
+		expectError            string
+	}{
+		{
+			name: "builtin imports",
+			imports: `
+  (import "env" "output" (func (param i32 i32)))
+  (import "state" "get_last" (func (param i32 i32 i32 i32) (result i32)))
+  (import "logger" "println" (func (param i32 i32)))`,
+			entrypoints: map[string]int{"map_transfers": 3},
+		},
+		{
+			name:        "unknown builtin",
+			imports:     `(import "state" "delete" (func (param i32 i32)))`,
+			expectError: `importing unknown host function "state::delete"`,
+		},
+		{
+			name:        "builtin signature mismatch",
+			imports:     `(import "state" "set" (func (param i32 i32 i32 i32 i32)))`,
+			expectError: `host function "state::set" imported with signature (i32, i32, i32, i32, i32) -> (), expected (i64, i32, i32, i32, i32) -> ()`,
+		},
+		{
+			name:    "registered extension",
+			imports: `(import "myext" "myimport" (func (param i32 i32 i32)))`,
+		},
+		{
+			name:        "unknown extension function",
+			imports:     `(import "myext" "other" (func (param i32 i32 i32)))`,
+			expectError: `importing unknown host function "myext::other"`,
+		},
+		{
+			name:        "unknown extension namespace",
+			imports:     `(import "rpc" "eth_call" (func (param i32 i32 i32)))`,
+			expectError: `importing unknown host function "rpc::eth_call"`,
+		},
+		{
+			name:                   "unknown extension namespace allowed",
+			imports:                `(import "rpc" "eth_call" (func (param i32 i32 i32)))`,
+			allowUnknownExtensions: true,
+		},
+		{
+			name:                   "unknown extension namespace signature mismatch",
+			imports:                `(import "rpc" "eth_call" (func (param i32 i32) (result i32)))`,
+			allowUnknownExtensions: true,
+			expectError:            `host function "rpc::eth_call" imported with signature (i32, i32) -> (i32), expected (i32, i32, i32) -> ()`,
+		},
+		{
+			name: "simd and reference types in guest functions",
+			imports: `
+  (import "env" "output" (func (param i32 i32)))
+  (func (param v128 funcref externref) (result v128)
+    local.get 0)`,
+			entrypoints: map[string]int{"map_transfers": 3},
+		},
+		{
+			name:        "reference type in host import",
+			imports:     `(import "env" "output" (func (param externref i32)))`,
+			expectError: `host function "env::output" imported with signature (externref, i32) -> (), expected (i32, i32) -> ()`,
+		},
+		{
+			name:        "missing entrypoint",
+			entrypoints: map[string]int{"map_approvals": 2},
+			expectError: `entrypoint "map_approvals" not exported`,
+		},
+		{
+			name:        "entrypoint arity mismatch",
+			entrypoints: map[string]int{"map_transfers": 2},
+			expectError: `entrypoint "map_transfers" takes 3 parameters, expected 2 for the module inputs`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, err := wasmer.Wat2Wasm("(module " + test.imports + exports + ")")
+			require.NoError(t, err)
+
+			err = Validate(code, test.entrypoints, map[string][]string{"myext": {"myimport"}}, test.allowUnknownExtensions)
+			if test.expectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectError)
+			}
+		})
+	}
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	err := Validate([]byte("pairExtractor code"), nil, nil, true)
+	assert.EqualError(t, err, "decoding wasm binary: invalid magic number or version")
+}